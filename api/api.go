@@ -8,19 +8,52 @@ import (
 
 // API provides a struct to wrap the api around
 type API struct {
-	Router    *mux.Router
-	Cfg       *config.Config
-	DataStore DataStorer
+	Router         *mux.Router
+	Cfg            *config.Config
+	DataStore      DataStorer
+	SchemaRegistry SchemaRegistry
+	Publisher      EventPublisher
+	Clock          VirtualClock
+	Health         HealthSimulator
 }
 
-// Setup function sets up the api and returns an api
-func Setup(r *mux.Router, cfg *config.Config, dataStorer DataStorer) *API {
+// Setup function sets up the api and returns an api. The resource changing endpoints are only added if resource
+// changes are enabled, and the event publishing endpoint if an event publisher is given.
+func Setup(r *mux.Router, cfg *config.Config, dataStorer DataStorer, schemaRegistry SchemaRegistry, clock VirtualClock, health HealthSimulator, publisher EventPublisher) *API {
 	api := &API{
-		Router:    r,
-		Cfg:       cfg,
-		DataStore: dataStorer,
+		Router:         r,
+		Cfg:            cfg,
+		DataStore:      dataStorer,
+		SchemaRegistry: schemaRegistry,
+		Publisher:      publisher,
+		Clock:          clock,
+		Health:         health,
 	}
 
 	r.HandleFunc("/resources", GetResources(api)).Methods("GET")
+	r.HandleFunc("/admin/fixtures", GetFixtures(api)).Methods("GET")
+	r.HandleFunc("/admin/clock", GetClock(api)).Methods("GET")
+	r.HandleFunc("/admin/clock", PutClock(api)).Methods("PUT")
+	r.HandleFunc("/admin/clock/advance", PostClockAdvance(api)).Methods("POST")
+	r.HandleFunc("/admin/health", GetHealthSimulation(api)).Methods("GET")
+	r.HandleFunc("/admin/health", PutHealthSimulation(api)).Methods("PUT")
+	r.HandleFunc("/admin/health", DeleteHealthSimulation(api)).Methods("DELETE")
+
+	if cfg.ResourceChangesEnabled {
+		r.HandleFunc("/admin/resources", PostResource(api)).Methods("POST")
+		r.HandleFunc("/admin/resources/{uri:.+}", PutResource(api)).Methods("PUT")
+		r.HandleFunc("/admin/resources/{uri:.+}", DeleteResource(api)).Methods("DELETE")
+	}
+
+	if publisher != nil {
+		r.HandleFunc("/admin/events/{topic}", PostEvent(api)).Methods("POST")
+	}
+
+	// schema registry stand-in, following the Confluent schema registry API
+	r.HandleFunc("/subjects", GetSubjects(api)).Methods("GET")
+	r.HandleFunc("/subjects/{subject}/versions", GetSubjectVersions(api)).Methods("GET")
+	r.HandleFunc("/subjects/{subject}/versions/{version}", GetSubjectVersion(api)).Methods("GET")
+	r.HandleFunc("/subjects/{subject}/versions/{version}/schema", GetSubjectVersionSchema(api)).Methods("GET")
+	r.HandleFunc("/schemas/ids/{id}", GetSchemaByID(api)).Methods("GET")
 	return api
 }
@@ -4,9 +4,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
 	"github.com/ONSdigital/dis-search-upstream-stub/config"
 	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/healthsim"
+	"github.com/ONSdigital/dis-search-upstream-stub/registry"
 
 	"github.com/gorilla/mux"
 	. "github.com/smartystreets/goconvey/convey"
@@ -17,10 +21,48 @@ func TestSetup(t *testing.T) {
 		r := mux.NewRouter()
 		cfg, err := config.Get()
 		So(err, ShouldBeNil)
-		api := Setup(r, cfg, &data.ResourceStore{})
+		schemaRegistry, err := registry.New(cfg.Kafka)
+		So(err, ShouldBeNil)
+		api := Setup(r, cfg, &data.ResourceStore{}, schemaRegistry, clock.NewSimulated(clock.Real{}, time.Now(), 1), healthsim.New(clock.Real{}), nil)
 
 		Convey("When created the following routes should have been added", func() {
 			So(hasRoute(api.Router, "/resources", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/fixtures", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/clock", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/clock", "PUT"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/clock/advance", "POST"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/health", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/health", "PUT"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/health", "DELETE"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/subjects", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/subjects/content-updated-value/versions", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/subjects/content-updated-value/versions/latest", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/subjects/content-updated-value/versions/1/schema", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/schemas/ids/1", "GET"), ShouldBeTrue)
+		})
+
+		Convey("When created without an event publisher the event publishing route should not have been added", func() {
+			So(hasRoute(api.Router, "/admin/events/content-updated", "POST"), ShouldBeFalse)
+		})
+
+		Convey("When created with resource changes disabled the resource changing routes should not have been added", func() {
+			So(hasRoute(api.Router, "/admin/resources", "POST"), ShouldBeFalse)
+			So(hasRoute(api.Router, "/admin/resources/economy/bulletins/a", "PUT"), ShouldBeFalse)
+			So(hasRoute(api.Router, "/admin/resources/economy/bulletins/a", "DELETE"), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given an API instance with resource changes enabled", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		enabled := *cfg
+		enabled.ResourceChangesEnabled = true
+		api := Setup(mux.NewRouter(), &enabled, &data.ResourceStore{}, nil, nil, nil, nil)
+
+		Convey("When created the resource changing routes should have been added", func() {
+			So(hasRoute(api.Router, "/admin/resources", "POST"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/resources/economy/bulletins/a", "PUT"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/admin/resources/economy/bulletins/a", "DELETE"), ShouldBeTrue)
 		})
 	})
 }
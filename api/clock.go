@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	dpresponse "github.com/ONSdigital/dp-net/v3/handlers/response"
+	"github.com/ONSdigital/log.go/v2/log"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/apierrors"
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// GetClock returns the state of the stub's virtual clock
+func GetClock(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		writeClock(w, req, api.Clock)
+	}
+}
+
+// PutClock sets the time and speed of the stub's virtual clock, and freezes or resumes it
+func PutClock(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		var body models.SetClockRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			log.Error(ctx, "failed to decode clock request", err)
+			http.Error(w, apierrors.ErrInvalidClockRequest.Error(), http.StatusBadRequest)
+			return
+		}
+		logData := log.Data{"request": body}
+
+		var now time.Time
+		if body.Now != "" {
+			var ok bool
+			if now, ok = parseClockTime(body.Now, api.Clock.Now()); !ok {
+				log.Warn(ctx, "invalid clock time", logData)
+				http.Error(w, apierrors.ErrInvalidClockTime.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if body.Speed != nil && *body.Speed <= 0 {
+			log.Warn(ctx, "invalid clock speed", logData)
+			http.Error(w, apierrors.ErrInvalidClockSpeed.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if body.Speed != nil {
+			api.Clock.SetSpeed(*body.Speed)
+		}
+		if body.Now != "" {
+			api.Clock.Set(now)
+		}
+		if body.Frozen != nil && *body.Frozen {
+			api.Clock.Freeze()
+		}
+		if body.Frozen != nil && !*body.Frozen {
+			api.Clock.Resume()
+		}
+
+		log.Info(ctx, "clock changed", logData)
+		writeClock(w, req, api.Clock)
+	}
+}
+
+// PostClockAdvance moves the stub's virtual clock on, or back, by a duration
+func PostClockAdvance(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		var body models.AdvanceClockRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			log.Error(ctx, "failed to decode clock request", err)
+			http.Error(w, apierrors.ErrInvalidClockRequest.Error(), http.StatusBadRequest)
+			return
+		}
+		logData := log.Data{"by": body.By}
+
+		d, err := clock.ParseDuration(body.By)
+		if err != nil {
+			log.Warn(ctx, "invalid clock duration", logData)
+			http.Error(w, apierrors.ErrInvalidClockDuration.Error(), http.StatusBadRequest)
+			return
+		}
+
+		api.Clock.Advance(d)
+
+		log.Info(ctx, "clock advanced", logData)
+		writeClock(w, req, api.Clock)
+	}
+}
+
+// parseClockTime parses an RFC 3339 date-time, or a time relative to now such as now+7d
+func parseClockTime(value string, now time.Time) (time.Time, bool) {
+	if t, ok := clock.ParseRelative(value, now); ok {
+		return t, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t.UTC(), err == nil
+}
+
+func writeClock(w http.ResponseWriter, req *http.Request, c VirtualClock) {
+	state := models.Clock{
+		Now:    c.Now(),
+		Frozen: c.Frozen(),
+		Speed:  c.Speed(),
+	}
+
+	if err := dpresponse.WriteJSON(w, state, http.StatusOK); err != nil {
+		log.Error(req.Context(), "failed to write response", err, log.Data{"clock": state})
+		http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,144 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	apiMock "github.com/ONSdigital/dis-search-upstream-stub/api/mock"
+	"github.com/ONSdigital/dis-search-upstream-stub/apierrors"
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testClockTime = time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)
+
+func virtualClockMock() *apiMock.VirtualClockMock {
+	return &apiMock.VirtualClockMock{
+		NowFunc:      func() time.Time { return testClockTime },
+		FrozenFunc:   func() bool { return false },
+		SpeedFunc:    func() float64 { return 1 },
+		SetFunc:      func(t time.Time) {},
+		SetSpeedFunc: func(speed float64) {},
+		FreezeFunc:   func() {},
+		ResumeFunc:   func() {},
+		AdvanceFunc:  func(d time.Duration) {},
+	}
+}
+
+func clockRequest(apiInstance *api.API, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "http://localhost:29600"+path, strings.NewReader(body))
+	resp := httptest.NewRecorder()
+	apiInstance.Router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestClockHandlersSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given an API with a virtual clock", t, func() {
+		clockMock := virtualClockMock()
+		apiInstance := api.Setup(mux.NewRouter(), cfg, &apiMock.DataStorerMock{}, &apiMock.SchemaRegistryMock{}, clockMock, &apiMock.HealthSimulatorMock{}, nil)
+
+		Convey("When the clock is requested", func() {
+			resp := clockRequest(apiInstance, "GET", "/admin/clock", "")
+
+			Convey("Then its state is returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+
+				var got models.Clock
+				So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+				So(got, ShouldResemble, models.Clock{Now: testClockTime, Frozen: false, Speed: 1})
+			})
+		})
+
+		Convey("When the clock is set to a date-time, sped up and frozen", func() {
+			resp := clockRequest(apiInstance, "PUT", "/admin/clock", `{"now": "2030-01-01T00:00:00Z", "speed": 60, "frozen": true}`)
+
+			Convey("Then each change is made to the clock and its state returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(clockMock.SetCalls(), ShouldHaveLength, 1)
+				So(clockMock.SetCalls()[0].T, ShouldEqual, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+				So(clockMock.SetSpeedCalls(), ShouldHaveLength, 1)
+				So(clockMock.SetSpeedCalls()[0].Speed, ShouldEqual, 60)
+				So(clockMock.FreezeCalls(), ShouldHaveLength, 1)
+				So(clockMock.ResumeCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the clock is set relative to its current time and resumed", func() {
+			resp := clockRequest(apiInstance, "PUT", "/admin/clock", `{"now": "now+7d", "frozen": false}`)
+
+			Convey("Then the clock is set to the relative time and resumed", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(clockMock.SetCalls()[0].T, ShouldEqual, testClockTime.Add(7*24*time.Hour))
+				So(clockMock.SetSpeedCalls(), ShouldBeEmpty)
+				So(clockMock.ResumeCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the clock is advanced", func() {
+			resp := clockRequest(apiInstance, "POST", "/admin/clock/advance", `{"by": "1w2d"}`)
+
+			Convey("Then it is moved on by the duration with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(clockMock.AdvanceCalls(), ShouldHaveLength, 1)
+				So(clockMock.AdvanceCalls()[0].D, ShouldEqual, 9*24*time.Hour)
+			})
+		})
+	})
+}
+
+func TestClockHandlersFail(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given an API with a virtual clock", t, func() {
+		clockMock := virtualClockMock()
+		apiInstance := api.Setup(mux.NewRouter(), cfg, &apiMock.DataStorerMock{}, &apiMock.SchemaRegistryMock{}, clockMock, &apiMock.HealthSimulatorMock{}, nil)
+
+		cases := []struct {
+			description string
+			method      string
+			path        string
+			body        string
+			message     string
+		}{
+			{"the body is not json", "PUT", "/admin/clock", `now`, apierrors.ErrInvalidClockRequest.Error()},
+			{"the time is not valid", "PUT", "/admin/clock", `{"now": "tomorrow", "speed": 2}`, apierrors.ErrInvalidClockTime.Error()},
+			{"the speed is negative", "PUT", "/admin/clock", `{"now": "now+1d", "speed": -1}`, apierrors.ErrInvalidClockSpeed.Error()},
+			{"the speed is zero", "PUT", "/admin/clock", `{"speed": 0}`, apierrors.ErrInvalidClockSpeed.Error()},
+			{"the advance body is not json", "POST", "/admin/clock/advance", `7d`, apierrors.ErrInvalidClockRequest.Error()},
+			{"the duration is not valid", "POST", "/admin/clock/advance", `{"by": "1 week"}`, apierrors.ErrInvalidClockDuration.Error()},
+		}
+
+		for _, c := range cases {
+			Convey("When a request is made to change the clock where "+c.description, func() {
+				resp := clockRequest(apiInstance, c.method, c.path, c.body)
+
+				Convey("Then a bad request is returned and the clock is left unchanged", func() {
+					So(resp.Code, ShouldEqual, http.StatusBadRequest)
+					So(strings.TrimSpace(resp.Body.String()), ShouldEqual, c.message)
+					So(clockMock.SetCalls(), ShouldBeEmpty)
+					So(clockMock.SetSpeedCalls(), ShouldBeEmpty)
+					So(clockMock.AdvanceCalls(), ShouldBeEmpty)
+				})
+			})
+		}
+	})
+}
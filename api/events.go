@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	dpresponse "github.com/ONSdigital/dp-net/v3/handlers/response"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/apierrors"
+	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// PostEvent publishes a fixture, or a resource given inline, as an event to a Kafka topic
+func PostEvent(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		topic := mux.Vars(req)["topic"]
+		logData := log.Data{"topic": topic}
+
+		var eventRequest models.PublishEventRequest
+		if err := json.NewDecoder(req.Body).Decode(&eventRequest); err != nil {
+			log.Error(ctx, "failed to decode event request", err, logData)
+			http.Error(w, apierrors.ErrInvalidEventRequest.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if (eventRequest.Fixture == "") == (len(eventRequest.Payload) == 0) {
+			log.Warn(ctx, "event request must have either a fixture or a payload", logData)
+			http.Error(w, apierrors.ErrEventSource.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var resource models.Resource
+		var err error
+
+		if eventRequest.Fixture != "" {
+			logData["fixture"] = eventRequest.Fixture
+
+			resource, err = api.DataStore.GetFixture(ctx, eventRequest.Fixture)
+			if err != nil {
+				if errors.Is(err, data.ErrFixtureNotFound) {
+					log.Warn(ctx, "fixture not found", logData)
+					http.Error(w, err.Error(), http.StatusNotFound)
+					return
+				}
+				log.Error(ctx, "getting fixture failed", err, logData)
+				http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+				return
+			}
+		} else {
+			resource, err = api.Publisher.DecodeResource(topic, eventRequest.Payload)
+			if err != nil {
+				if errors.Is(err, events.ErrUnknownTopic) {
+					log.Warn(ctx, "no producer for topic", logData)
+					http.Error(w, err.Error(), http.StatusNotFound)
+					return
+				}
+				log.Error(ctx, "failed to decode event payload", err, logData)
+				http.Error(w, apierrors.ErrInvalidEventPayload.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		event, err := api.Publisher.Publish(ctx, topic, resource)
+		if err != nil {
+			switch {
+			case errors.Is(err, events.ErrUnknownTopic):
+				log.Warn(ctx, "no producer for topic", logData)
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, events.ErrTopicMismatch):
+				log.Warn(ctx, "resource is not published to topic", logData)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				log.Error(ctx, "publishing event failed", err, logData)
+				http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+			}
+			return
+		}
+		event.Fixture = eventRequest.Fixture
+
+		// write response
+		err = dpresponse.WriteJSON(w, event, http.StatusAccepted)
+		if err != nil {
+			log.Error(ctx, "failed to write response", err, logData)
+			http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	apiMock "github.com/ONSdigital/dis-search-upstream-stub/api/mock"
+	"github.com/ONSdigital/dis-search-upstream-stub/apierrors"
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testFixture = "search_content_deleted/example.json"
+
+var testDeletedResource = models.SearchContentDeletedResource{URI: "/economy", CollectionID: "COLLECTIONID"}
+
+func eventDataStoreMock() *apiMock.DataStorerMock {
+	return &apiMock.DataStorerMock{
+		GetFixtureFunc: func(ctx context.Context, file string) (models.Resource, error) {
+			if file != testFixture {
+				return nil, data.ErrFixtureNotFound
+			}
+			return testDeletedResource, nil
+		},
+	}
+}
+
+func eventPublisherMock(publishErr error) *apiMock.EventPublisherMock {
+	return &apiMock.EventPublisherMock{
+		DecodeResourceFunc: func(topic string, payload []byte) (models.Resource, error) {
+			if topic != "search-content-deleted" {
+				return nil, events.ErrUnknownTopic
+			}
+			var r models.SearchContentDeletedResource
+			err := json.Unmarshal(payload, &r)
+			return r, err
+		},
+		PublishFunc: func(ctx context.Context, topic string, resource models.Resource) (*models.PublishedEvent, error) {
+			if publishErr != nil {
+				return nil, publishErr
+			}
+			return &models.PublishedEvent{Topic: topic, EventType: events.SearchContentDeletedEvent, Encoding: events.EncodingJSON}, nil
+		},
+	}
+}
+
+func postEvent(apiInstance *api.API, topic, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "http://localhost:29600/admin/events/"+topic, strings.NewReader(body))
+	resp := httptest.NewRecorder()
+	apiInstance.Router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestPostEventHandlerSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given an API with an event publisher", t, func() {
+		publisherMock := eventPublisherMock(nil)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, eventDataStoreMock(), &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, publisherMock)
+
+		Convey("When a request is made to publish a fixture", func() {
+			resp := postEvent(apiInstance, "search-content-deleted", `{"fixture": "`+testFixture+`"}`)
+
+			Convey("Then the fixture is published and the event returned with status code 202", func() {
+				So(resp.Code, ShouldEqual, http.StatusAccepted)
+				So(publisherMock.PublishCalls(), ShouldHaveLength, 1)
+				So(publisherMock.PublishCalls()[0].Topic, ShouldEqual, "search-content-deleted")
+				So(publisherMock.PublishCalls()[0].Resource, ShouldResemble, testDeletedResource)
+
+				var event models.PublishedEvent
+				So(json.Unmarshal(resp.Body.Bytes(), &event), ShouldBeNil)
+				So(event, ShouldResemble, models.PublishedEvent{
+					Topic:     "search-content-deleted",
+					EventType: events.SearchContentDeletedEvent,
+					Encoding:  events.EncodingJSON,
+					Fixture:   testFixture,
+				})
+			})
+		})
+
+		Convey("When a request is made to publish an inline payload", func() {
+			resp := postEvent(apiInstance, "search-content-deleted", `{"payload": {"uri": "/inline", "collection_id": "INLINE"}}`)
+
+			Convey("Then the decoded payload is published with status code 202", func() {
+				So(resp.Code, ShouldEqual, http.StatusAccepted)
+				So(publisherMock.DecodeResourceCalls(), ShouldHaveLength, 1)
+				So(publisherMock.PublishCalls(), ShouldHaveLength, 1)
+				So(publisherMock.PublishCalls()[0].Resource, ShouldResemble,
+					models.SearchContentDeletedResource{URI: "/inline", CollectionID: "INLINE"})
+			})
+		})
+	})
+
+	Convey("Given an API without an event publisher", t, func() {
+		apiInstance := api.Setup(mux.NewRouter(), cfg, eventDataStoreMock(), &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+		Convey("When a request is made to publish a fixture", func() {
+			resp := postEvent(apiInstance, "search-content-deleted", `{"fixture": "`+testFixture+`"}`)
+
+			Convey("Then the route is not found", func() {
+				So(resp.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestPostEventHandlerFail(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given an API with an event publisher", t, func() {
+		apiInstance := api.Setup(mux.NewRouter(), cfg, eventDataStoreMock(), &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, eventPublisherMock(nil))
+
+		cases := []struct {
+			description string
+			topic       string
+			body        string
+			status      int
+			message     string
+		}{
+			{"the body is not json", "search-content-deleted", `fixture`, http.StatusBadRequest, apierrors.ErrInvalidEventRequest.Error()},
+			{"neither a fixture nor a payload is given", "search-content-deleted", `{}`, http.StatusBadRequest, apierrors.ErrEventSource.Error()},
+			{"both a fixture and a payload are given", "search-content-deleted", `{"fixture": "` + testFixture + `", "payload": {}}`, http.StatusBadRequest, apierrors.ErrEventSource.Error()},
+			{"the fixture does not exist", "search-content-deleted", `{"fixture": "search_content_deleted/missing.json"}`, http.StatusNotFound, data.ErrFixtureNotFound.Error()},
+			{"the payload is not a valid resource", "search-content-deleted", `{"payload": {"uri": 1}}`, http.StatusBadRequest, apierrors.ErrInvalidEventPayload.Error()},
+			{"the topic has no producer", "unknown", `{"payload": {"uri": "/economy"}}`, http.StatusNotFound, events.ErrUnknownTopic.Error()},
+		}
+
+		for _, c := range cases {
+			Convey("When a request is made to publish an event where "+c.description, func() {
+				resp := postEvent(apiInstance, c.topic, c.body)
+
+				Convey("Then the request fails with the expected status and message", func() {
+					So(resp.Code, ShouldEqual, c.status)
+					So(strings.TrimSpace(resp.Body.String()), ShouldEqual, c.message)
+				})
+			})
+		}
+	})
+
+	Convey("Given an event publisher that rejects the resource for the topic", t, func() {
+		apiInstance := api.Setup(mux.NewRouter(), cfg, eventDataStoreMock(), &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, eventPublisherMock(events.ErrTopicMismatch))
+
+		Convey("When a request is made to publish a fixture to the topic", func() {
+			resp := postEvent(apiInstance, "content-updated", `{"fixture": "`+testFixture+`"}`)
+
+			Convey("Then a bad request is returned with status code 400", func() {
+				So(resp.Code, ShouldEqual, http.StatusBadRequest)
+				So(strings.TrimSpace(resp.Body.String()), ShouldEqual, events.ErrTopicMismatch.Error())
+			})
+		})
+	})
+
+	Convey("Given an event publisher that fails to send events", t, func() {
+		apiInstance := api.Setup(mux.NewRouter(), cfg, eventDataStoreMock(), &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, eventPublisherMock(errors.New("broker unavailable")))
+
+		Convey("When a request is made to publish a fixture", func() {
+			resp := postEvent(apiInstance, "search-content-deleted", `{"fixture": "`+testFixture+`"}`)
+
+			Convey("Then an internal server error is returned with status code 500", func() {
+				So(resp.Code, ShouldEqual, http.StatusInternalServerError)
+				So(strings.TrimSpace(resp.Body.String()), ShouldEqual, expectedServerErrorMsg)
+			})
+		})
+	})
+}
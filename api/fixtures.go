@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	dpresponse "github.com/ONSdigital/dp-net/v3/handlers/response"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// GetFixtures returns a report of every fixture with its expected and actual search contract violations
+func GetFixtures(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		report, err := api.DataStore.GetFixturesReport(ctx)
+		if err != nil {
+			log.Error(ctx, "getting fixtures report failed", err)
+			http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+			return
+		}
+
+		logData := log.Data{
+			"fixtures_count":      report.Count,
+			"fixtures_mismatches": report.Mismatches,
+		}
+
+		// write response
+		err = dpresponse.WriteJSON(w, report, http.StatusOK)
+		if err != nil {
+			log.Error(ctx, "failed to write response", err, logData)
+			http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+			return
+		}
+	}
+}
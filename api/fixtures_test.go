@@ -0,0 +1,112 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	apiMock "github.com/ONSdigital/dis-search-upstream-stub/api/mock"
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func expectedFixturesReport() models.FixturesReport {
+	return models.FixturesReport{
+		Count:      2,
+		Mismatches: 1,
+		Items: []models.FixtureReport{
+			{
+				File:               "search_content_updated/release_invalid_release_date.json",
+				ResourceType:       "SearchContentUpdatedResource",
+				ContentType:        "release",
+				Description:        "release with a release_date that is not a date-time",
+				ExpectedViolations: []string{"release_date:format"},
+				ActualViolations:   []string{"release_date:format"},
+				Matches:            true,
+			},
+			{
+				File:               "search_content_updated/standard_no_title_or_lang.json",
+				ResourceType:       "SearchContentUpdatedResource",
+				ContentType:        "statistical_article",
+				ExpectedViolations: []string{},
+				ActualViolations:   []string{"title:required"},
+				Matches:            false,
+			},
+		},
+	}
+}
+
+func TestGetFixturesHandlerSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given a Data Store that reports on its fixtures", t, func() {
+		dataStorerMock := &apiMock.DataStorerMock{
+			GetFixturesReportFunc: func(ctx context.Context) (*models.FixturesReport, error) {
+				report := expectedFixturesReport()
+				return &report, nil
+			},
+		}
+
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+		Convey("When a request is made to get the fixtures report", func() {
+			req := httptest.NewRequest("GET", "http://localhost:29600/admin/fixtures", http.NoBody)
+			resp := httptest.NewRecorder()
+
+			apiInstance.Router.ServeHTTP(resp, req)
+
+			Convey("Then the report is returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+
+				var reportReturned models.FixturesReport
+				err := json.Unmarshal(resp.Body.Bytes(), &reportReturned)
+				So(err, ShouldBeNil)
+				So(reportReturned, ShouldResemble, expectedFixturesReport())
+				So(dataStorerMock.GetFixturesReportCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
+
+func TestGetFixturesHandlerFail(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given a Data Store that fails to report on its fixtures", t, func() {
+		dataStorerMock := &apiMock.DataStorerMock{
+			GetFixturesReportFunc: func(ctx context.Context) (*models.FixturesReport, error) {
+				return nil, errors.New("failed to read fixtures")
+			},
+		}
+
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+		Convey("When a request is made to get the fixtures report", func() {
+			req := httptest.NewRequest("GET", "http://localhost:29600/admin/fixtures", http.NoBody)
+			resp := httptest.NewRecorder()
+
+			apiInstance.Router.ServeHTTP(resp, req)
+
+			Convey("Then an internal server error is returned with status code 500", func() {
+				So(resp.Code, ShouldEqual, http.StatusInternalServerError)
+				So(strings.TrimSpace(resp.Body.String()), ShouldEqual, expectedServerErrorMsg)
+			})
+		})
+	})
+}
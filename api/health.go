@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	dpresponse "github.com/ONSdigital/dp-net/v3/handlers/response"
+	"github.com/ONSdigital/log.go/v2/log"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/apierrors"
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
+	"github.com/ONSdigital/dis-search-upstream-stub/healthsim"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// GetHealthSimulation returns the status being forced on the health endpoint, if any
+func GetHealthSimulation(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		writeHealthSimulation(w, req, api.Health)
+	}
+}
+
+// PutHealthSimulation forces the status of the health endpoint, either to a single status or through steps that can
+// repeat, replacing any status already forced
+func PutHealthSimulation(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		var body models.SetHealthRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			log.Error(ctx, "failed to decode health request", err)
+			http.Error(w, apierrors.ErrInvalidHealthRequest.Error(), http.StatusBadRequest)
+			return
+		}
+		logData := log.Data{"request": body}
+
+		if (body.Status == "") == (len(body.Steps) == 0) || (body.Duration != "" && body.Status == "") {
+			log.Warn(ctx, "health request must give exactly one of status or steps", logData)
+			http.Error(w, apierrors.ErrHealthSource.Error(), http.StatusBadRequest)
+			return
+		}
+		requested := body.Steps
+		if body.Status != "" {
+			requested = []models.HealthStep{{Status: body.Status, Duration: body.Duration}}
+		}
+
+		steps := make([]healthsim.Step, 0, len(requested))
+		for _, step := range requested {
+			s := healthsim.Step{Status: step.Status}
+			if step.Duration != "" {
+				d, err := clock.ParseDuration(step.Duration)
+				if err != nil {
+					log.Warn(ctx, "invalid health duration", logData)
+					http.Error(w, apierrors.ErrInvalidHealthDuration.Error(), http.StatusBadRequest)
+					return
+				}
+				s.Duration = d
+			}
+			steps = append(steps, s)
+		}
+
+		if err := api.Health.Set(steps, body.Repeat); err != nil {
+			log.Warn(ctx, "invalid health simulation", log.Data{"request": body, "error": err.Error()})
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		log.Info(ctx, "health simulation set", logData)
+		writeHealthSimulation(w, req, api.Health)
+	}
+}
+
+// DeleteHealthSimulation stops forcing the status of the health endpoint, so that it reports the real status
+func DeleteHealthSimulation(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		api.Health.Clear()
+		log.Info(req.Context(), "health simulation cleared")
+		writeHealthSimulation(w, req, api.Health)
+	}
+}
+
+func writeHealthSimulation(w http.ResponseWriter, req *http.Request, h HealthSimulator) {
+	steps, repeat, status, until := h.State()
+
+	state := models.HealthSimulation{
+		Simulated: status != "",
+		Status:    status,
+		Repeat:    repeat,
+	}
+	if !until.IsZero() {
+		state.Until = &until
+	}
+	for _, step := range steps {
+		s := models.HealthStep{Status: step.Status}
+		if step.Duration != 0 {
+			s.Duration = step.Duration.String()
+		}
+		state.Steps = append(state.Steps, s)
+	}
+
+	if err := dpresponse.WriteJSON(w, state, http.StatusOK); err != nil {
+		log.Error(req.Context(), "failed to write response", err, log.Data{"health": state})
+		http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,145 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	apiMock "github.com/ONSdigital/dis-search-upstream-stub/api/mock"
+	"github.com/ONSdigital/dis-search-upstream-stub/apierrors"
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/healthsim"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+var testHealthUntil = time.Date(2025, 1, 6, 9, 30, 30, 0, time.UTC)
+
+func healthSimulatorMock(setErr error) *apiMock.HealthSimulatorMock {
+	var steps []healthsim.Step
+	var repeat bool
+	return &apiMock.HealthSimulatorMock{
+		SetFunc: func(s []healthsim.Step, r bool) error {
+			if setErr == nil {
+				steps, repeat = s, r
+			}
+			return setErr
+		},
+		ClearFunc: func() { steps, repeat = nil, false },
+		StateFunc: func() ([]healthsim.Step, bool, string, time.Time) {
+			if len(steps) == 0 {
+				return nil, false, "", time.Time{}
+			}
+			return steps, repeat, steps[0].Status, testHealthUntil
+		},
+	}
+}
+
+func TestHealthSimulationHandlers(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given an API with a health simulator", t, func() {
+		healthMock := healthSimulatorMock(nil)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, &apiMock.DataStorerMock{}, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, healthMock, nil)
+
+		Convey("When the simulation is requested before one is set", func() {
+			resp := clockRequest(apiInstance, "GET", "/admin/health", "")
+
+			Convey("Then it is reported that the health is not simulated, with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				var got models.HealthSimulation
+				So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+				So(got, ShouldResemble, models.HealthSimulation{})
+			})
+		})
+
+		Convey("When a status is forced for a duration", func() {
+			resp := clockRequest(apiInstance, "PUT", "/admin/health", `{"status": "CRITICAL", "duration": "1h"}`)
+
+			Convey("Then a single step is simulated and the simulation returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(healthMock.SetCalls(), ShouldHaveLength, 1)
+				So(healthMock.SetCalls()[0].Steps, ShouldResemble, []healthsim.Step{{Status: "CRITICAL", Duration: time.Hour}})
+				So(healthMock.SetCalls()[0].Repeat, ShouldBeFalse)
+
+				var got models.HealthSimulation
+				So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+				So(got.Simulated, ShouldBeTrue)
+				So(got.Status, ShouldEqual, "CRITICAL")
+				So(*got.Until, ShouldEqual, testHealthUntil)
+				So(got.Steps, ShouldResemble, []models.HealthStep{{Status: "CRITICAL", Duration: "1h0m0s"}})
+			})
+		})
+
+		Convey("When a repeating schedule of steps is set", func() {
+			resp := clockRequest(apiInstance, "PUT", "/admin/health",
+				`{"steps": [{"status": "OK", "duration": "20s"}, {"status": "WARNING", "duration": "10s"}], "repeat": true}`)
+
+			Convey("Then the steps are simulated, repeating, with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(healthMock.SetCalls(), ShouldHaveLength, 1)
+				So(healthMock.SetCalls()[0].Steps, ShouldResemble, []healthsim.Step{
+					{Status: "OK", Duration: 20 * time.Second},
+					{Status: "WARNING", Duration: 10 * time.Second},
+				})
+				So(healthMock.SetCalls()[0].Repeat, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the simulation is cleared", func() {
+			clockRequest(apiInstance, "PUT", "/admin/health", `{"status": "WARNING"}`)
+			resp := clockRequest(apiInstance, "DELETE", "/admin/health", "")
+
+			Convey("Then the real health is reported again, with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(healthMock.ClearCalls(), ShouldHaveLength, 1)
+				var got models.HealthSimulation
+				So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+				So(got.Simulated, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestHealthSimulationHandlersFailure(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given an API with a health simulator", t, func() {
+		healthMock := healthSimulatorMock(healthsim.ErrInvalidStatus)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, &apiMock.DataStorerMock{}, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, healthMock, nil)
+
+		for _, c := range []struct {
+			name, body, message string
+		}{
+			{"the body is not valid JSON", `{"status":`, apierrors.ErrInvalidHealthRequest.Error()},
+			{"neither a status nor steps are given", `{}`, apierrors.ErrHealthSource.Error()},
+			{"both a status and steps are given", `{"status": "OK", "steps": [{"status": "OK"}]}`, apierrors.ErrHealthSource.Error()},
+			{"a duration is given without a status", `{"duration": "1h", "steps": [{"status": "OK"}]}`, apierrors.ErrHealthSource.Error()},
+			{"a duration is not valid", `{"status": "OK", "duration": "soon"}`, apierrors.ErrInvalidHealthDuration.Error()},
+			{"the simulator rejects the steps", `{"status": "POORLY"}`, healthsim.ErrInvalidStatus.Error()},
+		} {
+			Convey("When the health is set but "+c.name, func() {
+				resp := clockRequest(apiInstance, "PUT", "/admin/health", c.body)
+
+				Convey("Then status code 400 is returned with the reason", func() {
+					So(resp.Code, ShouldEqual, http.StatusBadRequest)
+					So(resp.Body.String(), ShouldEqual, c.message+"\n")
+				})
+			})
+		}
+	})
+}
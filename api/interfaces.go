@@ -2,16 +2,61 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/healthsim"
 	"github.com/ONSdigital/dis-search-upstream-stub/models"
 )
 
 //go:generate moq -out ./mock/data_storer.go -pkg mock . DataStorer
+//go:generate moq -out ./mock/schema_registry.go -pkg mock . SchemaRegistry
+//go:generate moq -out ./mock/event_publisher.go -pkg mock . EventPublisher
+//go:generate moq -out ./mock/virtual_clock.go -pkg mock . VirtualClock
+//go:generate moq -out ./mock/health_simulator.go -pkg mock . HealthSimulator
 
 // DataStorer is an interface for a type that can store and retrieve resources
 type DataStorer interface {
 	GetResources(ctx context.Context, typeParam string, options data.Options) (resource *models.Resources, err error)
+	GetFixturesReport(ctx context.Context) (report *models.FixturesReport, err error)
+	GetFixture(ctx context.Context, file string) (resource models.Resource, err error)
+	CreateResource(ctx context.Context, resource models.SearchContentUpdatedResource) (err error)
+	UpdateResource(ctx context.Context, uri string, resource models.SearchContentUpdatedResource) (err error)
+	DeleteResource(ctx context.Context, uri string) (err error)
+}
+
+// SchemaRegistry is an interface for a type that can look up the registered versions of event schemas
+type SchemaRegistry interface {
+	Subjects() []string
+	Versions(subject string) (versions []int, err error)
+	Version(subject string, version int) (schemaVersion *models.SchemaVersion, err error)
+	Latest(subject string) (schemaVersion *models.SchemaVersion, err error)
+	Schema(id int) (definition string, err error)
+}
+
+// EventPublisher is an interface for a type that can publish resources as events to Kafka topics
+type EventPublisher interface {
+	DecodeResource(topic string, payload []byte) (resource models.Resource, err error)
+	Publish(ctx context.Context, topic string, resource models.Resource) (event *models.PublishedEvent, err error)
+}
+
+// VirtualClock is an interface for a type that tells the stub's time, and can be set, frozen and advanced
+type VirtualClock interface {
+	Now() time.Time
+	Speed() float64
+	Frozen() bool
+	Set(t time.Time)
+	SetSpeed(speed float64)
+	Freeze()
+	Resume()
+	Advance(d time.Duration)
+}
+
+// HealthSimulator is an interface for a type that can force the status reported by the health endpoint
+type HealthSimulator interface {
+	Set(steps []healthsim.Step, repeat bool) error
+	Clear()
+	State() (steps []healthsim.Step, repeat bool, status string, until time.Time)
 }
 
 // Paginator defines the required methods from the paginator package
@@ -21,9 +21,24 @@ var _ api.DataStorer = &DataStorerMock{}
 //
 //		// make and configure a mocked api.DataStorer
 //		mockedDataStorer := &DataStorerMock{
+//			CreateResourceFunc: func(ctx context.Context, resource models.SearchContentUpdatedResource) error {
+//				panic("mock out the CreateResource method")
+//			},
+//			DeleteResourceFunc: func(ctx context.Context, uri string) error {
+//				panic("mock out the DeleteResource method")
+//			},
+//			GetFixtureFunc: func(ctx context.Context, file string) (models.Resource, error) {
+//				panic("mock out the GetFixture method")
+//			},
+//			GetFixturesReportFunc: func(ctx context.Context) (*models.FixturesReport, error) {
+//				panic("mock out the GetFixturesReport method")
+//			},
 //			GetResourcesFunc: func(ctx context.Context, typeParam string, options data.Options) (*models.Resources, error) {
 //				panic("mock out the GetResources method")
 //			},
+//			UpdateResourceFunc: func(ctx context.Context, uri string, resource models.SearchContentUpdatedResource) error {
+//				panic("mock out the UpdateResource method")
+//			},
 //		}
 //
 //		// use mockedDataStorer in code that requires api.DataStorer
@@ -31,11 +46,52 @@ var _ api.DataStorer = &DataStorerMock{}
 //
 //	}
 type DataStorerMock struct {
+	// CreateResourceFunc mocks the CreateResource method.
+	CreateResourceFunc func(ctx context.Context, resource models.SearchContentUpdatedResource) error
+
+	// DeleteResourceFunc mocks the DeleteResource method.
+	DeleteResourceFunc func(ctx context.Context, uri string) error
+
+	// GetFixtureFunc mocks the GetFixture method.
+	GetFixtureFunc func(ctx context.Context, file string) (models.Resource, error)
+
+	// GetFixturesReportFunc mocks the GetFixturesReport method.
+	GetFixturesReportFunc func(ctx context.Context) (*models.FixturesReport, error)
+
 	// GetResourcesFunc mocks the GetResources method.
 	GetResourcesFunc func(ctx context.Context, typeParam string, options data.Options) (*models.Resources, error)
 
+	// UpdateResourceFunc mocks the UpdateResource method.
+	UpdateResourceFunc func(ctx context.Context, uri string, resource models.SearchContentUpdatedResource) error
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// CreateResource holds details about calls to the CreateResource method.
+		CreateResource []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Resource is the resource argument value.
+			Resource models.SearchContentUpdatedResource
+		}
+		// DeleteResource holds details about calls to the DeleteResource method.
+		DeleteResource []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// URI is the uri argument value.
+			URI string
+		}
+		// GetFixture holds details about calls to the GetFixture method.
+		GetFixture []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// File is the file argument value.
+			File string
+		}
+		// GetFixturesReport holds details about calls to the GetFixturesReport method.
+		GetFixturesReport []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
 		// GetResources holds details about calls to the GetResources method.
 		GetResources []struct {
 			// Ctx is the ctx argument value.
@@ -45,8 +101,162 @@ type DataStorerMock struct {
 			// Options is the options argument value.
 			Options data.Options
 		}
+		// UpdateResource holds details about calls to the UpdateResource method.
+		UpdateResource []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// URI is the uri argument value.
+			URI string
+			// Resource is the resource argument value.
+			Resource models.SearchContentUpdatedResource
+		}
+	}
+	lockCreateResource    sync.RWMutex
+	lockDeleteResource    sync.RWMutex
+	lockGetFixture        sync.RWMutex
+	lockGetFixturesReport sync.RWMutex
+	lockGetResources      sync.RWMutex
+	lockUpdateResource    sync.RWMutex
+}
+
+// CreateResource calls CreateResourceFunc.
+func (mock *DataStorerMock) CreateResource(ctx context.Context, resource models.SearchContentUpdatedResource) error {
+	if mock.CreateResourceFunc == nil {
+		panic("DataStorerMock.CreateResourceFunc: method is nil but DataStorer.CreateResource was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Resource models.SearchContentUpdatedResource
+	}{
+		Ctx:      ctx,
+		Resource: resource,
+	}
+	mock.lockCreateResource.Lock()
+	mock.calls.CreateResource = append(mock.calls.CreateResource, callInfo)
+	mock.lockCreateResource.Unlock()
+	return mock.CreateResourceFunc(ctx, resource)
+}
+
+// CreateResourceCalls gets all the calls that were made to CreateResource.
+// Check the length with:
+//
+//	len(mockedDataStorer.CreateResourceCalls())
+func (mock *DataStorerMock) CreateResourceCalls() []struct {
+	Ctx      context.Context
+	Resource models.SearchContentUpdatedResource
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Resource models.SearchContentUpdatedResource
+	}
+	mock.lockCreateResource.RLock()
+	calls = mock.calls.CreateResource
+	mock.lockCreateResource.RUnlock()
+	return calls
+}
+
+// DeleteResource calls DeleteResourceFunc.
+func (mock *DataStorerMock) DeleteResource(ctx context.Context, uri string) error {
+	if mock.DeleteResourceFunc == nil {
+		panic("DataStorerMock.DeleteResourceFunc: method is nil but DataStorer.DeleteResource was just called")
 	}
-	lockGetResources sync.RWMutex
+	callInfo := struct {
+		Ctx context.Context
+		URI string
+	}{
+		Ctx: ctx,
+		URI: uri,
+	}
+	mock.lockDeleteResource.Lock()
+	mock.calls.DeleteResource = append(mock.calls.DeleteResource, callInfo)
+	mock.lockDeleteResource.Unlock()
+	return mock.DeleteResourceFunc(ctx, uri)
+}
+
+// DeleteResourceCalls gets all the calls that were made to DeleteResource.
+// Check the length with:
+//
+//	len(mockedDataStorer.DeleteResourceCalls())
+func (mock *DataStorerMock) DeleteResourceCalls() []struct {
+	Ctx context.Context
+	URI string
+} {
+	var calls []struct {
+		Ctx context.Context
+		URI string
+	}
+	mock.lockDeleteResource.RLock()
+	calls = mock.calls.DeleteResource
+	mock.lockDeleteResource.RUnlock()
+	return calls
+}
+
+// GetFixture calls GetFixtureFunc.
+func (mock *DataStorerMock) GetFixture(ctx context.Context, file string) (models.Resource, error) {
+	if mock.GetFixtureFunc == nil {
+		panic("DataStorerMock.GetFixtureFunc: method is nil but DataStorer.GetFixture was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		File string
+	}{
+		Ctx:  ctx,
+		File: file,
+	}
+	mock.lockGetFixture.Lock()
+	mock.calls.GetFixture = append(mock.calls.GetFixture, callInfo)
+	mock.lockGetFixture.Unlock()
+	return mock.GetFixtureFunc(ctx, file)
+}
+
+// GetFixtureCalls gets all the calls that were made to GetFixture.
+// Check the length with:
+//
+//	len(mockedDataStorer.GetFixtureCalls())
+func (mock *DataStorerMock) GetFixtureCalls() []struct {
+	Ctx  context.Context
+	File string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		File string
+	}
+	mock.lockGetFixture.RLock()
+	calls = mock.calls.GetFixture
+	mock.lockGetFixture.RUnlock()
+	return calls
+}
+
+// GetFixturesReport calls GetFixturesReportFunc.
+func (mock *DataStorerMock) GetFixturesReport(ctx context.Context) (*models.FixturesReport, error) {
+	if mock.GetFixturesReportFunc == nil {
+		panic("DataStorerMock.GetFixturesReportFunc: method is nil but DataStorer.GetFixturesReport was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetFixturesReport.Lock()
+	mock.calls.GetFixturesReport = append(mock.calls.GetFixturesReport, callInfo)
+	mock.lockGetFixturesReport.Unlock()
+	return mock.GetFixturesReportFunc(ctx)
+}
+
+// GetFixturesReportCalls gets all the calls that were made to GetFixturesReport.
+// Check the length with:
+//
+//	len(mockedDataStorer.GetFixturesReportCalls())
+func (mock *DataStorerMock) GetFixturesReportCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetFixturesReport.RLock()
+	calls = mock.calls.GetFixturesReport
+	mock.lockGetFixturesReport.RUnlock()
+	return calls
 }
 
 // GetResources calls GetResourcesFunc.
@@ -88,3 +298,43 @@ func (mock *DataStorerMock) GetResourcesCalls() []struct {
 	mock.lockGetResources.RUnlock()
 	return calls
 }
+
+// UpdateResource calls UpdateResourceFunc.
+func (mock *DataStorerMock) UpdateResource(ctx context.Context, uri string, resource models.SearchContentUpdatedResource) error {
+	if mock.UpdateResourceFunc == nil {
+		panic("DataStorerMock.UpdateResourceFunc: method is nil but DataStorer.UpdateResource was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		URI      string
+		Resource models.SearchContentUpdatedResource
+	}{
+		Ctx:      ctx,
+		URI:      uri,
+		Resource: resource,
+	}
+	mock.lockUpdateResource.Lock()
+	mock.calls.UpdateResource = append(mock.calls.UpdateResource, callInfo)
+	mock.lockUpdateResource.Unlock()
+	return mock.UpdateResourceFunc(ctx, uri, resource)
+}
+
+// UpdateResourceCalls gets all the calls that were made to UpdateResource.
+// Check the length with:
+//
+//	len(mockedDataStorer.UpdateResourceCalls())
+func (mock *DataStorerMock) UpdateResourceCalls() []struct {
+	Ctx      context.Context
+	URI      string
+	Resource models.SearchContentUpdatedResource
+} {
+	var calls []struct {
+		Ctx      context.Context
+		URI      string
+		Resource models.SearchContentUpdatedResource
+	}
+	mock.lockUpdateResource.RLock()
+	calls = mock.calls.UpdateResource
+	mock.lockUpdateResource.RUnlock()
+	return calls
+}
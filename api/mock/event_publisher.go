@@ -0,0 +1,139 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"sync"
+)
+
+// Ensure, that EventPublisherMock does implement api.EventPublisher.
+// If this is not the case, regenerate this file with moq.
+var _ api.EventPublisher = &EventPublisherMock{}
+
+// EventPublisherMock is a mock implementation of api.EventPublisher.
+//
+//	func TestSomethingThatUsesEventPublisher(t *testing.T) {
+//
+//		// make and configure a mocked api.EventPublisher
+//		mockedEventPublisher := &EventPublisherMock{
+//			DecodeResourceFunc: func(topic string, payload []byte) (models.Resource, error) {
+//				panic("mock out the DecodeResource method")
+//			},
+//			PublishFunc: func(ctx context.Context, topic string, resource models.Resource) (*models.PublishedEvent, error) {
+//				panic("mock out the Publish method")
+//			},
+//		}
+//
+//		// use mockedEventPublisher in code that requires api.EventPublisher
+//		// and then make assertions.
+//
+//	}
+type EventPublisherMock struct {
+	// DecodeResourceFunc mocks the DecodeResource method.
+	DecodeResourceFunc func(topic string, payload []byte) (models.Resource, error)
+
+	// PublishFunc mocks the Publish method.
+	PublishFunc func(ctx context.Context, topic string, resource models.Resource) (*models.PublishedEvent, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DecodeResource holds details about calls to the DecodeResource method.
+		DecodeResource []struct {
+			// Topic is the topic argument value.
+			Topic string
+			// Payload is the payload argument value.
+			Payload []byte
+		}
+		// Publish holds details about calls to the Publish method.
+		Publish []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Topic is the topic argument value.
+			Topic string
+			// Resource is the resource argument value.
+			Resource models.Resource
+		}
+	}
+	lockDecodeResource sync.RWMutex
+	lockPublish        sync.RWMutex
+}
+
+// DecodeResource calls DecodeResourceFunc.
+func (mock *EventPublisherMock) DecodeResource(topic string, payload []byte) (models.Resource, error) {
+	if mock.DecodeResourceFunc == nil {
+		panic("EventPublisherMock.DecodeResourceFunc: method is nil but EventPublisher.DecodeResource was just called")
+	}
+	callInfo := struct {
+		Topic   string
+		Payload []byte
+	}{
+		Topic:   topic,
+		Payload: payload,
+	}
+	mock.lockDecodeResource.Lock()
+	mock.calls.DecodeResource = append(mock.calls.DecodeResource, callInfo)
+	mock.lockDecodeResource.Unlock()
+	return mock.DecodeResourceFunc(topic, payload)
+}
+
+// DecodeResourceCalls gets all the calls that were made to DecodeResource.
+// Check the length with:
+//
+//	len(mockedEventPublisher.DecodeResourceCalls())
+func (mock *EventPublisherMock) DecodeResourceCalls() []struct {
+	Topic   string
+	Payload []byte
+} {
+	var calls []struct {
+		Topic   string
+		Payload []byte
+	}
+	mock.lockDecodeResource.RLock()
+	calls = mock.calls.DecodeResource
+	mock.lockDecodeResource.RUnlock()
+	return calls
+}
+
+// Publish calls PublishFunc.
+func (mock *EventPublisherMock) Publish(ctx context.Context, topic string, resource models.Resource) (*models.PublishedEvent, error) {
+	if mock.PublishFunc == nil {
+		panic("EventPublisherMock.PublishFunc: method is nil but EventPublisher.Publish was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Topic    string
+		Resource models.Resource
+	}{
+		Ctx:      ctx,
+		Topic:    topic,
+		Resource: resource,
+	}
+	mock.lockPublish.Lock()
+	mock.calls.Publish = append(mock.calls.Publish, callInfo)
+	mock.lockPublish.Unlock()
+	return mock.PublishFunc(ctx, topic, resource)
+}
+
+// PublishCalls gets all the calls that were made to Publish.
+// Check the length with:
+//
+//	len(mockedEventPublisher.PublishCalls())
+func (mock *EventPublisherMock) PublishCalls() []struct {
+	Ctx      context.Context
+	Topic    string
+	Resource models.Resource
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Topic    string
+		Resource models.Resource
+	}
+	mock.lockPublish.RLock()
+	calls = mock.calls.Publish
+	mock.lockPublish.RUnlock()
+	return calls
+}
@@ -0,0 +1,157 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	"github.com/ONSdigital/dis-search-upstream-stub/healthsim"
+	"sync"
+	"time"
+)
+
+// Ensure, that HealthSimulatorMock does implement api.HealthSimulator.
+// If this is not the case, regenerate this file with moq.
+var _ api.HealthSimulator = &HealthSimulatorMock{}
+
+// HealthSimulatorMock is a mock implementation of api.HealthSimulator.
+//
+//	func TestSomethingThatUsesHealthSimulator(t *testing.T) {
+//
+//		// make and configure a mocked api.HealthSimulator
+//		mockedHealthSimulator := &HealthSimulatorMock{
+//			ClearFunc: func()  {
+//				panic("mock out the Clear method")
+//			},
+//			SetFunc: func(steps []healthsim.Step, repeat bool) error {
+//				panic("mock out the Set method")
+//			},
+//			StateFunc: func() ([]healthsim.Step, bool, string, time.Time) {
+//				panic("mock out the State method")
+//			},
+//		}
+//
+//		// use mockedHealthSimulator in code that requires api.HealthSimulator
+//		// and then make assertions.
+//
+//	}
+type HealthSimulatorMock struct {
+	// ClearFunc mocks the Clear method.
+	ClearFunc func()
+
+	// SetFunc mocks the Set method.
+	SetFunc func(steps []healthsim.Step, repeat bool) error
+
+	// StateFunc mocks the State method.
+	StateFunc func() ([]healthsim.Step, bool, string, time.Time)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Clear holds details about calls to the Clear method.
+		Clear []struct {
+		}
+		// Set holds details about calls to the Set method.
+		Set []struct {
+			// Steps is the steps argument value.
+			Steps []healthsim.Step
+			// Repeat is the repeat argument value.
+			Repeat bool
+		}
+		// State holds details about calls to the State method.
+		State []struct {
+		}
+	}
+	lockClear sync.RWMutex
+	lockSet   sync.RWMutex
+	lockState sync.RWMutex
+}
+
+// Clear calls ClearFunc.
+func (mock *HealthSimulatorMock) Clear() {
+	if mock.ClearFunc == nil {
+		panic("HealthSimulatorMock.ClearFunc: method is nil but HealthSimulator.Clear was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockClear.Lock()
+	mock.calls.Clear = append(mock.calls.Clear, callInfo)
+	mock.lockClear.Unlock()
+	mock.ClearFunc()
+}
+
+// ClearCalls gets all the calls that were made to Clear.
+// Check the length with:
+//
+//	len(mockedHealthSimulator.ClearCalls())
+func (mock *HealthSimulatorMock) ClearCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockClear.RLock()
+	calls = mock.calls.Clear
+	mock.lockClear.RUnlock()
+	return calls
+}
+
+// Set calls SetFunc.
+func (mock *HealthSimulatorMock) Set(steps []healthsim.Step, repeat bool) error {
+	if mock.SetFunc == nil {
+		panic("HealthSimulatorMock.SetFunc: method is nil but HealthSimulator.Set was just called")
+	}
+	callInfo := struct {
+		Steps  []healthsim.Step
+		Repeat bool
+	}{
+		Steps:  steps,
+		Repeat: repeat,
+	}
+	mock.lockSet.Lock()
+	mock.calls.Set = append(mock.calls.Set, callInfo)
+	mock.lockSet.Unlock()
+	return mock.SetFunc(steps, repeat)
+}
+
+// SetCalls gets all the calls that were made to Set.
+// Check the length with:
+//
+//	len(mockedHealthSimulator.SetCalls())
+func (mock *HealthSimulatorMock) SetCalls() []struct {
+	Steps  []healthsim.Step
+	Repeat bool
+} {
+	var calls []struct {
+		Steps  []healthsim.Step
+		Repeat bool
+	}
+	mock.lockSet.RLock()
+	calls = mock.calls.Set
+	mock.lockSet.RUnlock()
+	return calls
+}
+
+// State calls StateFunc.
+func (mock *HealthSimulatorMock) State() ([]healthsim.Step, bool, string, time.Time) {
+	if mock.StateFunc == nil {
+		panic("HealthSimulatorMock.StateFunc: method is nil but HealthSimulator.State was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockState.Lock()
+	mock.calls.State = append(mock.calls.State, callInfo)
+	mock.lockState.Unlock()
+	return mock.StateFunc()
+}
+
+// StateCalls gets all the calls that were made to State.
+// Check the length with:
+//
+//	len(mockedHealthSimulator.StateCalls())
+func (mock *HealthSimulatorMock) StateCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockState.RLock()
+	calls = mock.calls.State
+	mock.lockState.RUnlock()
+	return calls
+}
@@ -0,0 +1,251 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"sync"
+)
+
+// Ensure, that SchemaRegistryMock does implement api.SchemaRegistry.
+// If this is not the case, regenerate this file with moq.
+var _ api.SchemaRegistry = &SchemaRegistryMock{}
+
+// SchemaRegistryMock is a mock implementation of api.SchemaRegistry.
+//
+//	func TestSomethingThatUsesSchemaRegistry(t *testing.T) {
+//
+//		// make and configure a mocked api.SchemaRegistry
+//		mockedSchemaRegistry := &SchemaRegistryMock{
+//			LatestFunc: func(subject string) (*models.SchemaVersion, error) {
+//				panic("mock out the Latest method")
+//			},
+//			SchemaFunc: func(id int) (string, error) {
+//				panic("mock out the Schema method")
+//			},
+//			SubjectsFunc: func() []string {
+//				panic("mock out the Subjects method")
+//			},
+//			VersionFunc: func(subject string, version int) (*models.SchemaVersion, error) {
+//				panic("mock out the Version method")
+//			},
+//			VersionsFunc: func(subject string) ([]int, error) {
+//				panic("mock out the Versions method")
+//			},
+//		}
+//
+//		// use mockedSchemaRegistry in code that requires api.SchemaRegistry
+//		// and then make assertions.
+//
+//	}
+type SchemaRegistryMock struct {
+	// LatestFunc mocks the Latest method.
+	LatestFunc func(subject string) (*models.SchemaVersion, error)
+
+	// SchemaFunc mocks the Schema method.
+	SchemaFunc func(id int) (string, error)
+
+	// SubjectsFunc mocks the Subjects method.
+	SubjectsFunc func() []string
+
+	// VersionFunc mocks the Version method.
+	VersionFunc func(subject string, version int) (*models.SchemaVersion, error)
+
+	// VersionsFunc mocks the Versions method.
+	VersionsFunc func(subject string) ([]int, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Latest holds details about calls to the Latest method.
+		Latest []struct {
+			// Subject is the subject argument value.
+			Subject string
+		}
+		// Schema holds details about calls to the Schema method.
+		Schema []struct {
+			// ID is the id argument value.
+			ID int
+		}
+		// Subjects holds details about calls to the Subjects method.
+		Subjects []struct {
+		}
+		// Version holds details about calls to the Version method.
+		Version []struct {
+			// Subject is the subject argument value.
+			Subject string
+			// Version is the version argument value.
+			Version int
+		}
+		// Versions holds details about calls to the Versions method.
+		Versions []struct {
+			// Subject is the subject argument value.
+			Subject string
+		}
+	}
+	lockLatest   sync.RWMutex
+	lockSchema   sync.RWMutex
+	lockSubjects sync.RWMutex
+	lockVersion  sync.RWMutex
+	lockVersions sync.RWMutex
+}
+
+// Latest calls LatestFunc.
+func (mock *SchemaRegistryMock) Latest(subject string) (*models.SchemaVersion, error) {
+	if mock.LatestFunc == nil {
+		panic("SchemaRegistryMock.LatestFunc: method is nil but SchemaRegistry.Latest was just called")
+	}
+	callInfo := struct {
+		Subject string
+	}{
+		Subject: subject,
+	}
+	mock.lockLatest.Lock()
+	mock.calls.Latest = append(mock.calls.Latest, callInfo)
+	mock.lockLatest.Unlock()
+	return mock.LatestFunc(subject)
+}
+
+// LatestCalls gets all the calls that were made to Latest.
+// Check the length with:
+//
+//	len(mockedSchemaRegistry.LatestCalls())
+func (mock *SchemaRegistryMock) LatestCalls() []struct {
+	Subject string
+} {
+	var calls []struct {
+		Subject string
+	}
+	mock.lockLatest.RLock()
+	calls = mock.calls.Latest
+	mock.lockLatest.RUnlock()
+	return calls
+}
+
+// Schema calls SchemaFunc.
+func (mock *SchemaRegistryMock) Schema(id int) (string, error) {
+	if mock.SchemaFunc == nil {
+		panic("SchemaRegistryMock.SchemaFunc: method is nil but SchemaRegistry.Schema was just called")
+	}
+	callInfo := struct {
+		ID int
+	}{
+		ID: id,
+	}
+	mock.lockSchema.Lock()
+	mock.calls.Schema = append(mock.calls.Schema, callInfo)
+	mock.lockSchema.Unlock()
+	return mock.SchemaFunc(id)
+}
+
+// SchemaCalls gets all the calls that were made to Schema.
+// Check the length with:
+//
+//	len(mockedSchemaRegistry.SchemaCalls())
+func (mock *SchemaRegistryMock) SchemaCalls() []struct {
+	ID int
+} {
+	var calls []struct {
+		ID int
+	}
+	mock.lockSchema.RLock()
+	calls = mock.calls.Schema
+	mock.lockSchema.RUnlock()
+	return calls
+}
+
+// Subjects calls SubjectsFunc.
+func (mock *SchemaRegistryMock) Subjects() []string {
+	if mock.SubjectsFunc == nil {
+		panic("SchemaRegistryMock.SubjectsFunc: method is nil but SchemaRegistry.Subjects was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockSubjects.Lock()
+	mock.calls.Subjects = append(mock.calls.Subjects, callInfo)
+	mock.lockSubjects.Unlock()
+	return mock.SubjectsFunc()
+}
+
+// SubjectsCalls gets all the calls that were made to Subjects.
+// Check the length with:
+//
+//	len(mockedSchemaRegistry.SubjectsCalls())
+func (mock *SchemaRegistryMock) SubjectsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockSubjects.RLock()
+	calls = mock.calls.Subjects
+	mock.lockSubjects.RUnlock()
+	return calls
+}
+
+// Version calls VersionFunc.
+func (mock *SchemaRegistryMock) Version(subject string, version int) (*models.SchemaVersion, error) {
+	if mock.VersionFunc == nil {
+		panic("SchemaRegistryMock.VersionFunc: method is nil but SchemaRegistry.Version was just called")
+	}
+	callInfo := struct {
+		Subject string
+		Version int
+	}{
+		Subject: subject,
+		Version: version,
+	}
+	mock.lockVersion.Lock()
+	mock.calls.Version = append(mock.calls.Version, callInfo)
+	mock.lockVersion.Unlock()
+	return mock.VersionFunc(subject, version)
+}
+
+// VersionCalls gets all the calls that were made to Version.
+// Check the length with:
+//
+//	len(mockedSchemaRegistry.VersionCalls())
+func (mock *SchemaRegistryMock) VersionCalls() []struct {
+	Subject string
+	Version int
+} {
+	var calls []struct {
+		Subject string
+		Version int
+	}
+	mock.lockVersion.RLock()
+	calls = mock.calls.Version
+	mock.lockVersion.RUnlock()
+	return calls
+}
+
+// Versions calls VersionsFunc.
+func (mock *SchemaRegistryMock) Versions(subject string) ([]int, error) {
+	if mock.VersionsFunc == nil {
+		panic("SchemaRegistryMock.VersionsFunc: method is nil but SchemaRegistry.Versions was just called")
+	}
+	callInfo := struct {
+		Subject string
+	}{
+		Subject: subject,
+	}
+	mock.lockVersions.Lock()
+	mock.calls.Versions = append(mock.calls.Versions, callInfo)
+	mock.lockVersions.Unlock()
+	return mock.VersionsFunc(subject)
+}
+
+// VersionsCalls gets all the calls that were made to Versions.
+// Check the length with:
+//
+//	len(mockedSchemaRegistry.VersionsCalls())
+func (mock *SchemaRegistryMock) VersionsCalls() []struct {
+	Subject string
+} {
+	var calls []struct {
+		Subject string
+	}
+	mock.lockVersions.RLock()
+	calls = mock.calls.Versions
+	mock.lockVersions.RUnlock()
+	return calls
+}
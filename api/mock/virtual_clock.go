@@ -0,0 +1,349 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	"sync"
+	"time"
+)
+
+// Ensure, that VirtualClockMock does implement api.VirtualClock.
+// If this is not the case, regenerate this file with moq.
+var _ api.VirtualClock = &VirtualClockMock{}
+
+// VirtualClockMock is a mock implementation of api.VirtualClock.
+//
+//	func TestSomethingThatUsesVirtualClock(t *testing.T) {
+//
+//		// make and configure a mocked api.VirtualClock
+//		mockedVirtualClock := &VirtualClockMock{
+//			AdvanceFunc: func(d time.Duration)  {
+//				panic("mock out the Advance method")
+//			},
+//			FreezeFunc: func()  {
+//				panic("mock out the Freeze method")
+//			},
+//			FrozenFunc: func() bool {
+//				panic("mock out the Frozen method")
+//			},
+//			NowFunc: func() time.Time {
+//				panic("mock out the Now method")
+//			},
+//			ResumeFunc: func()  {
+//				panic("mock out the Resume method")
+//			},
+//			SetFunc: func(t time.Time)  {
+//				panic("mock out the Set method")
+//			},
+//			SetSpeedFunc: func(speed float64)  {
+//				panic("mock out the SetSpeed method")
+//			},
+//			SpeedFunc: func() float64 {
+//				panic("mock out the Speed method")
+//			},
+//		}
+//
+//		// use mockedVirtualClock in code that requires api.VirtualClock
+//		// and then make assertions.
+//
+//	}
+type VirtualClockMock struct {
+	// AdvanceFunc mocks the Advance method.
+	AdvanceFunc func(d time.Duration)
+
+	// FreezeFunc mocks the Freeze method.
+	FreezeFunc func()
+
+	// FrozenFunc mocks the Frozen method.
+	FrozenFunc func() bool
+
+	// NowFunc mocks the Now method.
+	NowFunc func() time.Time
+
+	// ResumeFunc mocks the Resume method.
+	ResumeFunc func()
+
+	// SetFunc mocks the Set method.
+	SetFunc func(t time.Time)
+
+	// SetSpeedFunc mocks the SetSpeed method.
+	SetSpeedFunc func(speed float64)
+
+	// SpeedFunc mocks the Speed method.
+	SpeedFunc func() float64
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Advance holds details about calls to the Advance method.
+		Advance []struct {
+			// D is the d argument value.
+			D time.Duration
+		}
+		// Freeze holds details about calls to the Freeze method.
+		Freeze []struct {
+		}
+		// Frozen holds details about calls to the Frozen method.
+		Frozen []struct {
+		}
+		// Now holds details about calls to the Now method.
+		Now []struct {
+		}
+		// Resume holds details about calls to the Resume method.
+		Resume []struct {
+		}
+		// Set holds details about calls to the Set method.
+		Set []struct {
+			// T is the t argument value.
+			T time.Time
+		}
+		// SetSpeed holds details about calls to the SetSpeed method.
+		SetSpeed []struct {
+			// Speed is the speed argument value.
+			Speed float64
+		}
+		// Speed holds details about calls to the Speed method.
+		Speed []struct {
+		}
+	}
+	lockAdvance  sync.RWMutex
+	lockFreeze   sync.RWMutex
+	lockFrozen   sync.RWMutex
+	lockNow      sync.RWMutex
+	lockResume   sync.RWMutex
+	lockSet      sync.RWMutex
+	lockSetSpeed sync.RWMutex
+	lockSpeed    sync.RWMutex
+}
+
+// Advance calls AdvanceFunc.
+func (mock *VirtualClockMock) Advance(d time.Duration) {
+	if mock.AdvanceFunc == nil {
+		panic("VirtualClockMock.AdvanceFunc: method is nil but VirtualClock.Advance was just called")
+	}
+	callInfo := struct {
+		D time.Duration
+	}{
+		D: d,
+	}
+	mock.lockAdvance.Lock()
+	mock.calls.Advance = append(mock.calls.Advance, callInfo)
+	mock.lockAdvance.Unlock()
+	mock.AdvanceFunc(d)
+}
+
+// AdvanceCalls gets all the calls that were made to Advance.
+// Check the length with:
+//
+//	len(mockedVirtualClock.AdvanceCalls())
+func (mock *VirtualClockMock) AdvanceCalls() []struct {
+	D time.Duration
+} {
+	var calls []struct {
+		D time.Duration
+	}
+	mock.lockAdvance.RLock()
+	calls = mock.calls.Advance
+	mock.lockAdvance.RUnlock()
+	return calls
+}
+
+// Freeze calls FreezeFunc.
+func (mock *VirtualClockMock) Freeze() {
+	if mock.FreezeFunc == nil {
+		panic("VirtualClockMock.FreezeFunc: method is nil but VirtualClock.Freeze was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockFreeze.Lock()
+	mock.calls.Freeze = append(mock.calls.Freeze, callInfo)
+	mock.lockFreeze.Unlock()
+	mock.FreezeFunc()
+}
+
+// FreezeCalls gets all the calls that were made to Freeze.
+// Check the length with:
+//
+//	len(mockedVirtualClock.FreezeCalls())
+func (mock *VirtualClockMock) FreezeCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockFreeze.RLock()
+	calls = mock.calls.Freeze
+	mock.lockFreeze.RUnlock()
+	return calls
+}
+
+// Frozen calls FrozenFunc.
+func (mock *VirtualClockMock) Frozen() bool {
+	if mock.FrozenFunc == nil {
+		panic("VirtualClockMock.FrozenFunc: method is nil but VirtualClock.Frozen was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockFrozen.Lock()
+	mock.calls.Frozen = append(mock.calls.Frozen, callInfo)
+	mock.lockFrozen.Unlock()
+	return mock.FrozenFunc()
+}
+
+// FrozenCalls gets all the calls that were made to Frozen.
+// Check the length with:
+//
+//	len(mockedVirtualClock.FrozenCalls())
+func (mock *VirtualClockMock) FrozenCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockFrozen.RLock()
+	calls = mock.calls.Frozen
+	mock.lockFrozen.RUnlock()
+	return calls
+}
+
+// Now calls NowFunc.
+func (mock *VirtualClockMock) Now() time.Time {
+	if mock.NowFunc == nil {
+		panic("VirtualClockMock.NowFunc: method is nil but VirtualClock.Now was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockNow.Lock()
+	mock.calls.Now = append(mock.calls.Now, callInfo)
+	mock.lockNow.Unlock()
+	return mock.NowFunc()
+}
+
+// NowCalls gets all the calls that were made to Now.
+// Check the length with:
+//
+//	len(mockedVirtualClock.NowCalls())
+func (mock *VirtualClockMock) NowCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockNow.RLock()
+	calls = mock.calls.Now
+	mock.lockNow.RUnlock()
+	return calls
+}
+
+// Resume calls ResumeFunc.
+func (mock *VirtualClockMock) Resume() {
+	if mock.ResumeFunc == nil {
+		panic("VirtualClockMock.ResumeFunc: method is nil but VirtualClock.Resume was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockResume.Lock()
+	mock.calls.Resume = append(mock.calls.Resume, callInfo)
+	mock.lockResume.Unlock()
+	mock.ResumeFunc()
+}
+
+// ResumeCalls gets all the calls that were made to Resume.
+// Check the length with:
+//
+//	len(mockedVirtualClock.ResumeCalls())
+func (mock *VirtualClockMock) ResumeCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockResume.RLock()
+	calls = mock.calls.Resume
+	mock.lockResume.RUnlock()
+	return calls
+}
+
+// Set calls SetFunc.
+func (mock *VirtualClockMock) Set(t time.Time) {
+	if mock.SetFunc == nil {
+		panic("VirtualClockMock.SetFunc: method is nil but VirtualClock.Set was just called")
+	}
+	callInfo := struct {
+		T time.Time
+	}{
+		T: t,
+	}
+	mock.lockSet.Lock()
+	mock.calls.Set = append(mock.calls.Set, callInfo)
+	mock.lockSet.Unlock()
+	mock.SetFunc(t)
+}
+
+// SetCalls gets all the calls that were made to Set.
+// Check the length with:
+//
+//	len(mockedVirtualClock.SetCalls())
+func (mock *VirtualClockMock) SetCalls() []struct {
+	T time.Time
+} {
+	var calls []struct {
+		T time.Time
+	}
+	mock.lockSet.RLock()
+	calls = mock.calls.Set
+	mock.lockSet.RUnlock()
+	return calls
+}
+
+// SetSpeed calls SetSpeedFunc.
+func (mock *VirtualClockMock) SetSpeed(speed float64) {
+	if mock.SetSpeedFunc == nil {
+		panic("VirtualClockMock.SetSpeedFunc: method is nil but VirtualClock.SetSpeed was just called")
+	}
+	callInfo := struct {
+		Speed float64
+	}{
+		Speed: speed,
+	}
+	mock.lockSetSpeed.Lock()
+	mock.calls.SetSpeed = append(mock.calls.SetSpeed, callInfo)
+	mock.lockSetSpeed.Unlock()
+	mock.SetSpeedFunc(speed)
+}
+
+// SetSpeedCalls gets all the calls that were made to SetSpeed.
+// Check the length with:
+//
+//	len(mockedVirtualClock.SetSpeedCalls())
+func (mock *VirtualClockMock) SetSpeedCalls() []struct {
+	Speed float64
+} {
+	var calls []struct {
+		Speed float64
+	}
+	mock.lockSetSpeed.RLock()
+	calls = mock.calls.SetSpeed
+	mock.lockSetSpeed.RUnlock()
+	return calls
+}
+
+// Speed calls SpeedFunc.
+func (mock *VirtualClockMock) Speed() float64 {
+	if mock.SpeedFunc == nil {
+		panic("VirtualClockMock.SpeedFunc: method is nil but VirtualClock.Speed was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockSpeed.Lock()
+	mock.calls.Speed = append(mock.calls.Speed, callInfo)
+	mock.lockSpeed.Unlock()
+	return mock.SpeedFunc()
+}
+
+// SpeedCalls gets all the calls that were made to Speed.
+// Check the length with:
+//
+//	len(mockedVirtualClock.SpeedCalls())
+func (mock *VirtualClockMock) SpeedCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockSpeed.RLock()
+	calls = mock.calls.Speed
+	mock.lockSpeed.RUnlock()
+	return calls
+}
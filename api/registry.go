@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	dpresponse "github.com/ONSdigital/dp-net/v3/handlers/response"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/registry"
+)
+
+// latestVersion can be given in place of a version number to get the latest version of a subject
+const latestVersion = "latest"
+
+// A list of the error codes returned by a Confluent schema registry
+const (
+	errorCodeSubjectNotFound = 40401
+	errorCodeVersionNotFound = 40402
+	errorCodeSchemaNotFound  = 40403
+	errorCodeInvalidVersion  = 42202
+	errorCodeInvalidID       = 42203
+)
+
+// GetSubjects returns the name of every subject in the schema registry
+func GetSubjects(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		writeRegistryResponse(w, req, http.StatusOK, api.SchemaRegistry.Subjects(), log.Data{})
+	}
+}
+
+// GetSubjectVersions returns the version numbers registered under a subject
+func GetSubjectVersions(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		subject := mux.Vars(req)["subject"]
+		logData := log.Data{"subject": subject}
+
+		versions, err := api.SchemaRegistry.Versions(subject)
+		if err != nil {
+			writeRegistryError(w, req, err, logData)
+			return
+		}
+
+		writeRegistryResponse(w, req, http.StatusOK, versions, logData)
+	}
+}
+
+// GetSubjectVersion returns a version of the schema registered under a subject
+func GetSubjectVersion(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		schemaVersion, logData, ok := getSubjectVersion(api, w, req)
+		if !ok {
+			return
+		}
+
+		writeRegistryResponse(w, req, http.StatusOK, schemaVersion, logData)
+	}
+}
+
+// GetSubjectVersionSchema returns only the definition of a version of the schema registered under a subject
+func GetSubjectVersionSchema(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		schemaVersion, logData, ok := getSubjectVersion(api, w, req)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte(schemaVersion.Schema)); err != nil {
+			log.Error(req.Context(), "failed to write response", err, logData)
+		}
+	}
+}
+
+// GetSchemaByID returns the schema with the given ID
+func GetSchemaByID(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		idParam := mux.Vars(req)["id"]
+		logData := log.Data{"id": idParam}
+
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			writeRegistryErrorCode(w, req, http.StatusUnprocessableEntity, errorCodeInvalidID, "Invalid schema id", logData)
+			return
+		}
+
+		definition, err := api.SchemaRegistry.Schema(id)
+		if err != nil {
+			writeRegistryError(w, req, err, logData)
+			return
+		}
+
+		writeRegistryResponse(w, req, http.StatusOK, models.RegisteredSchema{Schema: definition}, logData)
+	}
+}
+
+// getSubjectVersion gets the version of the subject in the request path, writing an error response
+// if it cannot be found
+func getSubjectVersion(api *API, w http.ResponseWriter, req *http.Request) (*models.SchemaVersion, log.Data, bool) {
+	vars := mux.Vars(req)
+	subject, versionParam := vars["subject"], vars["version"]
+	logData := log.Data{"subject": subject, "version": versionParam}
+
+	var schemaVersion *models.SchemaVersion
+	var err error
+
+	if versionParam == latestVersion {
+		schemaVersion, err = api.SchemaRegistry.Latest(subject)
+	} else {
+		version, convErr := strconv.Atoi(versionParam)
+		if convErr != nil || version < 1 {
+			writeRegistryErrorCode(w, req, http.StatusUnprocessableEntity, errorCodeInvalidVersion,
+				"The specified version '"+versionParam+"' is not a valid version id. Allowed values are between [1, 2^31-1] and the string \"latest\"", logData)
+			return nil, logData, false
+		}
+		schemaVersion, err = api.SchemaRegistry.Version(subject, version)
+	}
+
+	if err != nil {
+		writeRegistryError(w, req, err, logData)
+		return nil, logData, false
+	}
+
+	return schemaVersion, logData, true
+}
+
+// writeRegistryError writes the response of a Confluent schema registry for an error returned by the registry
+func writeRegistryError(w http.ResponseWriter, req *http.Request, err error, logData log.Data) {
+	switch {
+	case errors.Is(err, registry.ErrSubjectNotFound):
+		writeRegistryErrorCode(w, req, http.StatusNotFound, errorCodeSubjectNotFound, "Subject not found.", logData)
+	case errors.Is(err, registry.ErrVersionNotFound):
+		writeRegistryErrorCode(w, req, http.StatusNotFound, errorCodeVersionNotFound, "Version not found.", logData)
+	case errors.Is(err, registry.ErrSchemaNotFound):
+		writeRegistryErrorCode(w, req, http.StatusNotFound, errorCodeSchemaNotFound, "Schema not found", logData)
+	default:
+		log.Error(req.Context(), "schema registry lookup failed", err, logData)
+		http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+	}
+}
+
+func writeRegistryErrorCode(w http.ResponseWriter, req *http.Request, status, errorCode int, message string, logData log.Data) {
+	logData["error_code"] = errorCode
+	log.Info(req.Context(), "schema registry request failed", logData)
+
+	writeRegistryResponse(w, req, status, models.RegistryError{ErrorCode: errorCode, Message: message}, logData)
+}
+
+func writeRegistryResponse(w http.ResponseWriter, req *http.Request, status int, body interface{}, logData log.Data) {
+	if err := dpresponse.WriteJSON(w, body, status); err != nil {
+		log.Error(req.Context(), "failed to write response", err, logData)
+		http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+	}
+}
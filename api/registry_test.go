@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	apiMock "github.com/ONSdigital/dis-search-upstream-stub/api/mock"
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/registry"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testSchema = `{"type":"record","name":"test","fields":[]}`
+
+func schemaRegistryMock() *apiMock.SchemaRegistryMock {
+	testVersion := &models.SchemaVersion{Subject: "test-value", Version: 1, ID: 7, Schema: testSchema}
+
+	return &apiMock.SchemaRegistryMock{
+		SubjectsFunc: func() []string {
+			return []string{"test-value"}
+		},
+		VersionsFunc: func(subject string) ([]int, error) {
+			if subject != "test-value" {
+				return nil, registry.ErrSubjectNotFound
+			}
+			return []int{1}, nil
+		},
+		VersionFunc: func(subject string, version int) (*models.SchemaVersion, error) {
+			if subject != "test-value" {
+				return nil, registry.ErrSubjectNotFound
+			}
+			if version != 1 {
+				return nil, registry.ErrVersionNotFound
+			}
+			return testVersion, nil
+		},
+		LatestFunc: func(subject string) (*models.SchemaVersion, error) {
+			if subject != "test-value" {
+				return nil, registry.ErrSubjectNotFound
+			}
+			return testVersion, nil
+		},
+		SchemaFunc: func(id int) (string, error) {
+			if id != 7 {
+				return "", registry.ErrSchemaNotFound
+			}
+			return testSchema, nil
+		},
+	}
+}
+
+func TestSchemaRegistryHandlers(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given a schema registry with one subject", t, func() {
+		apiInstance := api.Setup(mux.NewRouter(), cfg, &apiMock.DataStorerMock{}, schemaRegistryMock(), &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+		get := func(path string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "http://localhost:29600"+path, http.NoBody)
+			resp := httptest.NewRecorder()
+			apiInstance.Router.ServeHTTP(resp, req)
+			return resp
+		}
+
+		Convey("When the subjects are requested", func() {
+			resp := get("/subjects")
+
+			Convey("Then the subject names are returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(resp.Body.String(), ShouldEqual, `["test-value"]`)
+			})
+		})
+
+		Convey("When the versions of the subject are requested", func() {
+			resp := get("/subjects/test-value/versions")
+
+			Convey("Then the version numbers are returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(resp.Body.String(), ShouldEqual, `[1]`)
+			})
+		})
+
+		Convey("When a version of the subject is requested by number or as the latest", func() {
+			for _, version := range []string{"1", "latest"} {
+				resp := get("/subjects/test-value/versions/" + version)
+
+				Convey("Then the schema version is returned with status code 200 for version "+version, func() {
+					So(resp.Code, ShouldEqual, http.StatusOK)
+
+					var got models.SchemaVersion
+					So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+					So(got, ShouldResemble, models.SchemaVersion{Subject: "test-value", Version: 1, ID: 7, Schema: testSchema})
+				})
+			}
+		})
+
+		Convey("When only the schema of a version is requested", func() {
+			resp := get("/subjects/test-value/versions/1/schema")
+
+			Convey("Then the schema definition is returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(resp.Body.String(), ShouldEqual, testSchema)
+			})
+		})
+
+		Convey("When a schema is requested by ID", func() {
+			resp := get("/schemas/ids/7")
+
+			Convey("Then the schema is returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+
+				var got models.RegisteredSchema
+				So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+				So(got.Schema, ShouldEqual, testSchema)
+			})
+		})
+
+		Convey("When something that is not in the registry is requested", func() {
+			cases := []struct {
+				path      string
+				status    int
+				errorCode int
+			}{
+				{"/subjects/unknown-value/versions", http.StatusNotFound, 40401},
+				{"/subjects/unknown-value/versions/latest", http.StatusNotFound, 40401},
+				{"/subjects/test-value/versions/2", http.StatusNotFound, 40402},
+				{"/subjects/test-value/versions/0", http.StatusUnprocessableEntity, 42202},
+				{"/subjects/test-value/versions/first", http.StatusUnprocessableEntity, 42202},
+				{"/schemas/ids/8", http.StatusNotFound, 40403},
+				{"/schemas/ids/seven", http.StatusUnprocessableEntity, 42203},
+			}
+
+			Convey("Then an error is returned in the form of a Confluent schema registry", func() {
+				for _, c := range cases {
+					resp := get(c.path)
+					So(resp.Code, ShouldEqual, c.status)
+
+					var got models.RegistryError
+					So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+					So(got.ErrorCode, ShouldEqual, c.errorCode)
+					So(got.Message, ShouldNotBeEmpty)
+				}
+			})
+		})
+	})
+}
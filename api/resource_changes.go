@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	dpresponse "github.com/ONSdigital/dp-net/v3/handlers/response"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/apierrors"
+	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/validation"
+)
+
+// invalidResourceResponse is the body of the response to a change that would leave a resource breaking the search
+// contract
+type invalidResourceResponse struct {
+	Message    string                 `json:"message"`
+	Violations []validation.Violation `json:"violations"`
+}
+
+// PostResource creates a search-content-updated resource, emitting its event if event publishing is enabled
+func PostResource(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logData := log.Data{}
+
+		var resource models.SearchContentUpdatedResource
+		if err := json.NewDecoder(req.Body).Decode(&resource); err != nil {
+			log.Error(ctx, "failed to decode resource", err, logData)
+			http.Error(w, apierrors.ErrInvalidResourceBody.Error(), http.StatusBadRequest)
+			return
+		}
+		logData["uri"] = resource.URI
+
+		if err := api.DataStore.CreateResource(ctx, resource); err != nil {
+			writeResourceChangeError(w, req, err, logData)
+			return
+		}
+
+		writeResource(w, req, http.StatusCreated, resource, logData)
+	}
+}
+
+// PutResource replaces the search-content-updated resource at the uri in the path, emitting its event if
+// event publishing is enabled
+func PutResource(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		uri := "/" + mux.Vars(req)["uri"]
+		logData := log.Data{"uri": uri}
+
+		var resource models.SearchContentUpdatedResource
+		if err := json.NewDecoder(req.Body).Decode(&resource); err != nil {
+			log.Error(ctx, "failed to decode resource", err, logData)
+			http.Error(w, apierrors.ErrInvalidResourceBody.Error(), http.StatusBadRequest)
+			return
+		}
+		resource.URI = uri
+
+		if err := api.DataStore.UpdateResource(ctx, uri, resource); err != nil {
+			writeResourceChangeError(w, req, err, logData)
+			return
+		}
+
+		writeResource(w, req, http.StatusOK, resource, logData)
+	}
+}
+
+// DeleteResource deletes the search-content-updated resource at the uri in the path, emitting a
+// search-content-deleted event if event publishing is enabled
+func DeleteResource(api *API) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		uri := "/" + mux.Vars(req)["uri"]
+		logData := log.Data{"uri": uri}
+
+		if err := api.DataStore.DeleteResource(req.Context(), uri); err != nil {
+			writeResourceChangeError(w, req, err, logData)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeResourceChangeError(w http.ResponseWriter, req *http.Request, err error, logData log.Data) {
+	var violations validation.Violations
+
+	switch {
+	case errors.Is(err, data.ErrInvalidResource) && errors.As(err, &violations):
+		logData["violations"] = violations.Keys()
+		log.Warn(req.Context(), "resource does not satisfy the search contract", logData)
+		body := invalidResourceResponse{Message: data.ErrInvalidResource.Error(), Violations: violations}
+		if err := dpresponse.WriteJSON(w, body, http.StatusBadRequest); err != nil {
+			log.Error(req.Context(), "failed to write response", err, logData)
+		}
+	case errors.Is(err, data.ErrURIRequired):
+		log.Warn(req.Context(), "resource has no uri", logData)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, data.ErrResourceExists):
+		log.Warn(req.Context(), "resource already exists", logData)
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, data.ErrResourceNotFound):
+		log.Warn(req.Context(), "resource not found", logData)
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		log.Error(req.Context(), "changing resource failed", err, logData)
+		http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+	}
+}
+
+func writeResource(w http.ResponseWriter, req *http.Request, status int, resource models.SearchContentUpdatedResource, logData log.Data) {
+	if err := dpresponse.WriteJSON(w, resource, status); err != nil {
+		log.Error(req.Context(), "failed to write response", err, logData)
+		http.Error(w, serverErrorMessage, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,215 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	apiMock "github.com/ONSdigital/dis-search-upstream-stub/api/mock"
+	"github.com/ONSdigital/dis-search-upstream-stub/apierrors"
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/validation"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func resourceChangesDataStoreMock(err error) *apiMock.DataStorerMock {
+	return &apiMock.DataStorerMock{
+		CreateResourceFunc: func(ctx context.Context, resource models.SearchContentUpdatedResource) error {
+			return err
+		},
+		UpdateResourceFunc: func(ctx context.Context, uri string, resource models.SearchContentUpdatedResource) error {
+			return err
+		},
+		DeleteResourceFunc: func(ctx context.Context, uri string) error {
+			return err
+		},
+	}
+}
+
+// resourceChangesConfig returns a copy of the default configuration with resource changes enabled
+func resourceChangesConfig(t *testing.T) *config.Config {
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+	enabled := *cfg
+	enabled.ResourceChangesEnabled = true
+	return &enabled
+}
+
+func changeResource(apiInstance *api.API, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "http://localhost:29600"+path, strings.NewReader(body))
+	resp := httptest.NewRecorder()
+	apiInstance.Router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestResourceChangeHandlersSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := resourceChangesConfig(t)
+
+	Convey("Given an API with a data store", t, func() {
+		dataStoreMock := resourceChangesDataStoreMock(nil)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStoreMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+		Convey("When a request is made to create a resource", func() {
+			resp := changeResource(apiInstance, "POST", "/admin/resources", `{"uri": "/economy/new", "title": "New"}`)
+
+			Convey("Then the resource is created and returned with status code 201", func() {
+				So(resp.Code, ShouldEqual, http.StatusCreated)
+				So(dataStoreMock.CreateResourceCalls(), ShouldHaveLength, 1)
+				So(dataStoreMock.CreateResourceCalls()[0].Resource, ShouldResemble,
+					models.SearchContentUpdatedResource{URI: "/economy/new", Title: "New"})
+
+				var got models.SearchContentUpdatedResource
+				So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+				So(got.URI, ShouldEqual, "/economy/new")
+			})
+		})
+
+		Convey("When a request is made to update a resource", func() {
+			resp := changeResource(apiInstance, "PUT", "/admin/resources/economy/new", `{"uri": "/ignored", "title": "Updated"}`)
+
+			Convey("Then the resource at the uri in the path is updated and returned with status code 200", func() {
+				So(resp.Code, ShouldEqual, http.StatusOK)
+				So(dataStoreMock.UpdateResourceCalls(), ShouldHaveLength, 1)
+				So(dataStoreMock.UpdateResourceCalls()[0].URI, ShouldEqual, "/economy/new")
+
+				var got models.SearchContentUpdatedResource
+				So(json.Unmarshal(resp.Body.Bytes(), &got), ShouldBeNil)
+				So(got, ShouldResemble, models.SearchContentUpdatedResource{URI: "/economy/new", Title: "Updated"})
+			})
+		})
+
+		Convey("When a request is made to delete a resource", func() {
+			resp := changeResource(apiInstance, "DELETE", "/admin/resources/economy/new", "")
+
+			Convey("Then the resource at the uri in the path is deleted with status code 204", func() {
+				So(resp.Code, ShouldEqual, http.StatusNoContent)
+				So(dataStoreMock.DeleteResourceCalls(), ShouldHaveLength, 1)
+				So(dataStoreMock.DeleteResourceCalls()[0].URI, ShouldEqual, "/economy/new")
+			})
+		})
+	})
+}
+
+func TestResourceChangeHandlersFail(t *testing.T) {
+	t.Parallel()
+
+	cfg := resourceChangesConfig(t)
+
+	Convey("Given an API with a data store", t, func() {
+		apiInstance := api.Setup(mux.NewRouter(), cfg, resourceChangesDataStoreMock(nil), &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+		Convey("When a request is made to create or update a resource with a body that is not json", func() {
+			post := changeResource(apiInstance, "POST", "/admin/resources", `resource`)
+			put := changeResource(apiInstance, "PUT", "/admin/resources/economy/new", `resource`)
+
+			Convey("Then a bad request is returned with status code 400", func() {
+				So(post.Code, ShouldEqual, http.StatusBadRequest)
+				So(strings.TrimSpace(post.Body.String()), ShouldEqual, apierrors.ErrInvalidResourceBody.Error())
+				So(put.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+
+	cases := []struct {
+		description string
+		err         error
+		status      int
+		message     string
+	}{
+		{"requires a uri", data.ErrURIRequired, http.StatusBadRequest, data.ErrURIRequired.Error()},
+		{"already has the resource", data.ErrResourceExists, http.StatusConflict, data.ErrResourceExists.Error()},
+		{"does not have the resource", data.ErrResourceNotFound, http.StatusNotFound, data.ErrResourceNotFound.Error()},
+		{"fails", errors.New("fixtures unreadable"), http.StatusInternalServerError, expectedServerErrorMsg},
+	}
+
+	Convey("Given a data store that rejects resources breaking the search contract", t, func() {
+		violations := validation.Violations{{Field: "title", Rule: validation.RuleRequired, Message: "title is required"}}
+		invalid := fmt.Errorf("%w: %w", data.ErrInvalidResource, violations)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, resourceChangesDataStoreMock(invalid), &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+		Convey("When requests are made to create or update a resource", func() {
+			responses := []*httptest.ResponseRecorder{
+				changeResource(apiInstance, "POST", "/admin/resources", `{"uri": "/economy/new"}`),
+				changeResource(apiInstance, "PUT", "/admin/resources/economy/new", `{}`),
+			}
+
+			Convey("Then each fails with status code 400 and the violations", func() {
+				for _, resp := range responses {
+					So(resp.Code, ShouldEqual, http.StatusBadRequest)
+					var body struct {
+						Message    string                 `json:"message"`
+						Violations []validation.Violation `json:"violations"`
+					}
+					So(json.Unmarshal(resp.Body.Bytes(), &body), ShouldBeNil)
+					So(body.Message, ShouldEqual, data.ErrInvalidResource.Error())
+					So(body.Violations, ShouldResemble, []validation.Violation(violations))
+				}
+			})
+		})
+	})
+
+	for _, c := range cases {
+		Convey("Given a data store that "+c.description, t, func() {
+			apiInstance := api.Setup(mux.NewRouter(), cfg, resourceChangesDataStoreMock(c.err), &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+			Convey("When requests are made to change a resource", func() {
+				responses := []*httptest.ResponseRecorder{
+					changeResource(apiInstance, "POST", "/admin/resources", `{"uri": "/economy/new"}`),
+					changeResource(apiInstance, "PUT", "/admin/resources/economy/new", `{}`),
+					changeResource(apiInstance, "DELETE", "/admin/resources/economy/new", ""),
+				}
+
+				Convey("Then each fails with the expected status and message", func() {
+					for _, resp := range responses {
+						So(resp.Code, ShouldEqual, c.status)
+						So(strings.TrimSpace(resp.Body.String()), ShouldEqual, c.message)
+					}
+				})
+			})
+		})
+	}
+}
+
+func TestResourceChangeHandlersDisabled(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given an API with resource changes disabled", t, func() {
+		dataStoreMock := resourceChangesDataStoreMock(nil)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStoreMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+		Convey("When requests are made to change a resource", func() {
+			responses := []*httptest.ResponseRecorder{
+				changeResource(apiInstance, "POST", "/admin/resources", `{"uri": "/economy/new", "title": "New"}`),
+				changeResource(apiInstance, "PUT", "/admin/resources/economy/new", `{"title": "Updated"}`),
+				changeResource(apiInstance, "DELETE", "/admin/resources/economy/new", ""),
+			}
+
+			Convey("Then the routes are not found and the data store is not changed", func() {
+				for _, resp := range responses {
+					So(resp.Code, ShouldEqual, http.StatusNotFound)
+				}
+				So(dataStoreMock.CreateResourceCalls(), ShouldBeEmpty)
+				So(dataStoreMock.UpdateResourceCalls(), ShouldBeEmpty)
+				So(dataStoreMock.DeleteResourceCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
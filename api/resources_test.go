@@ -124,7 +124,7 @@ func TestGetResourcesHandlerSuccess(t *testing.T) {
 	}
 
 	Convey("Given a list of resources exists in the Data Store", t, func() {
-		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of all resources", func() {
 			req := httptest.NewRequest("GET", "http://localhost:29600/resources", http.NoBody)
@@ -206,7 +206,7 @@ func TestGetResourcesHandlerSuccess(t *testing.T) {
 			},
 		}
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, customValidPaginationDataStore)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, customValidPaginationDataStore, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of resources", func() {
 			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost:29600/resources?offset=%d&limit=%d", validOffset, validLimit), http.NoBody)
@@ -266,7 +266,7 @@ func TestGetResourcesHandlerSuccess(t *testing.T) {
 			},
 		}
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, greaterOffsetDataStore)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, greaterOffsetDataStore, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of resources", func() {
 			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost:29600/resources?offset=%d", greaterOffset), http.NoBody)
@@ -311,7 +311,7 @@ func TestGetResourcesHandlerWithEmptyResourceStoreSuccess(t *testing.T) {
 			},
 		}
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of all the resources that exist in the resources collection", func() {
 			req := httptest.NewRequest("GET", "http://localhost:29600/resources", http.NoBody)
@@ -357,7 +357,7 @@ func TestGetResourcesHandlerFail(t *testing.T) {
 	Convey("Given offset is not numeric", t, func() {
 		nonNumericOffset := "stringOffset"
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of resources", func() {
 			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost:29600/resources?offset=%s", nonNumericOffset), http.NoBody)
@@ -380,7 +380,7 @@ func TestGetResourcesHandlerFail(t *testing.T) {
 	Convey("Given offset is negative", t, func() {
 		negativeOffset := -3
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of resources", func() {
 			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost:29600/resources?offset=%d", negativeOffset), http.NoBody)
@@ -399,7 +399,7 @@ func TestGetResourcesHandlerFail(t *testing.T) {
 	Convey("Given limit is not numeric", t, func() {
 		nonNumericLimit := "stringLimit"
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of resources", func() {
 			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost:29600/resources?limit=%s", nonNumericLimit), http.NoBody)
@@ -418,7 +418,7 @@ func TestGetResourcesHandlerFail(t *testing.T) {
 	Convey("Given limit is negative", t, func() {
 		negativeLimit := -1
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of resources", func() {
 			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost:29600/resources?offset=0&limit=%d", negativeLimit), http.NoBody)
@@ -448,7 +448,7 @@ func TestGetResourcesHandlerFail(t *testing.T) {
 			},
 		}
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, greaterLimitDataStore)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, greaterLimitDataStore, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of resources", func() {
 			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost:29600/resources?limit=%d", greaterLimit), http.NoBody)
@@ -471,7 +471,7 @@ func TestGetResourcesHandlerFail(t *testing.T) {
 			},
 		}
 
-		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock)
+		apiInstance := api.Setup(mux.NewRouter(), cfg, dataStorerMock, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
 
 		Convey("When a request is made to get a list of all the resources that exist in the resources collection", func() {
 			req := httptest.NewRequest("GET", "http://localhost:29600/resources", http.NoBody)
@@ -0,0 +1,442 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	apiMock "github.com/ONSdigital/dis-search-upstream-stub/api/mock"
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/yaml.v3"
+)
+
+const specificationFile = "../specification.yml"
+
+// specNode is a decoded node of an OpenAPI document
+type specNode = map[string]interface{}
+
+// specification is an OpenAPI document, along with any documents it references, which is used
+// to check the responses of the API conform to it. Only the structure of a response is checked;
+// the values of fields (formats and enums) are checked against the search contract by the
+// validation package, as many fixtures are deliberately invalid.
+type specification struct {
+	docs map[string]specNode
+	root string
+}
+
+// specCase is a request made to check the API against the specification
+type specCase struct {
+	name      string
+	query     url.Values
+	failStore bool
+	status    int
+}
+
+func loadSpecification(file string) (*specification, error) {
+	spec := &specification{docs: map[string]specNode{}, root: filepath.Clean(file)}
+	if _, err := spec.doc(spec.root); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// doc returns the document at the given path, loading it if it has not been loaded before
+func (s *specification) doc(path string) (specNode, error) {
+	if doc, ok := s.docs[path]; ok {
+		return doc, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc specNode
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	s.docs[path] = doc
+	return doc, nil
+}
+
+// resolve follows any $ref in the schema, returning the document the schema belongs to and the schema itself
+func (s *specification) resolve(docPath string, schema specNode) (string, specNode, error) {
+	for {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return docPath, schema, nil
+		}
+
+		file, pointer, _ := strings.Cut(ref, "#")
+		if file != "" {
+			docPath = filepath.Join(filepath.Dir(docPath), file)
+		}
+
+		doc, err := s.doc(docPath)
+		if err != nil {
+			return "", nil, err
+		}
+
+		var node interface{} = doc
+		for _, part := range strings.Split(strings.Trim(pointer, "/"), "/") {
+			m, ok := node.(specNode)
+			if !ok {
+				return "", nil, fmt.Errorf("unable to resolve %s", ref)
+			}
+			node = m[part]
+		}
+
+		if schema, ok = node.(specNode); !ok {
+			return "", nil, fmt.Errorf("unable to resolve %s", ref)
+		}
+	}
+}
+
+// operation returns the documented operation for a path and method
+func (s *specification) operation(path, method string) specNode {
+	paths, _ := s.docs[s.root]["paths"].(specNode)
+	item, _ := paths[path].(specNode)
+	op, _ := item[strings.ToLower(method)].(specNode)
+	return op
+}
+
+// objectSchema is the flattened properties and required fields of an object schema and its allOf schemas
+type objectSchema struct {
+	properties map[string]schemaRef
+	required   []string
+}
+
+type schemaRef struct {
+	doc    string
+	schema specNode
+}
+
+func (s *specification) flatten(docPath string, schema specNode, obj *objectSchema) error {
+	docPath, schema, err := s.resolve(docPath, schema)
+	if err != nil {
+		return err
+	}
+
+	allOf, _ := schema["allOf"].([]interface{})
+	for _, sub := range allOf {
+		if err := s.flatten(docPath, sub.(specNode), obj); err != nil {
+			return err
+		}
+	}
+
+	properties, _ := schema["properties"].(specNode)
+	for name, property := range properties {
+		obj.properties[name] = schemaRef{doc: docPath, schema: property.(specNode)}
+	}
+
+	required, _ := schema["required"].([]interface{})
+	for _, name := range required {
+		obj.required = append(obj.required, name.(string))
+	}
+
+	return nil
+}
+
+// check returns every way in which the value does not conform to the schema
+func (s *specification) check(docPath string, schema specNode, value interface{}, at string) []string {
+	docPath, schema, err := s.resolve(docPath, schema)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", at, err)}
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+	}
+
+	// oneOf requires exactly one alternative to match, so that a value is never ambiguously documented
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		var problems []string
+		var matches []int
+		for i, alternative := range oneOf {
+			altProblems := s.check(docPath, alternative.(specNode), value, at)
+			if len(altProblems) == 0 {
+				matches = append(matches, i)
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("alternative %d: %s", i, strings.Join(altProblems, ", ")))
+		}
+		switch len(matches) {
+		case 1:
+			return nil
+		case 0:
+			return []string{fmt.Sprintf("%s: does not match any documented schema (%s)", at, strings.Join(problems, "; "))}
+		default:
+			return []string{fmt.Sprintf("%s: matches more than one documented schema (alternatives %v)", at, matches)}
+		}
+	}
+
+	schemaType, _ := schema["type"].(string)
+	if schemaType == "" && (schema["allOf"] != nil || schema["properties"] != nil) {
+		schemaType = "object"
+	}
+
+	switch schemaType {
+	case "object":
+		return s.checkObject(docPath, schema, value, at)
+	case "array":
+		values, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array but got %T", at, value)}
+		}
+		items, _ := schema["items"].(specNode)
+		var problems []string
+		for i, item := range values {
+			problems = append(problems, s.check(docPath, items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return problems
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: expected a string but got %T", at, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected a boolean but got %T", at, value)}
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return []string{fmt.Sprintf("%s: expected an integer but got %v", at, value)}
+		}
+	}
+
+	return nil
+}
+
+func (s *specification) checkObject(docPath string, schema specNode, value interface{}, at string) []string {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%s: expected an object but got %T", at, value)}
+	}
+
+	obj := &objectSchema{properties: map[string]schemaRef{}}
+	if err := s.flatten(docPath, schema, obj); err != nil {
+		return []string{fmt.Sprintf("%s: %v", at, err)}
+	}
+
+	var problems []string
+
+	for _, name := range obj.required {
+		if _, ok := fields[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s: required field is missing", at, name))
+		}
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := obj.properties[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s: field is not documented", at, name))
+			continue
+		}
+		problems = append(problems, s.check(property.doc, property.schema, fields[name], at+"."+name)...)
+	}
+
+	return problems
+}
+
+// parameterCases returns requests that exercise a documented query parameter with both valid and invalid values
+func parameterCases(parameter specNode) []specCase {
+	name, _ := parameter["name"].(string)
+	schema, _ := parameter["schema"].(specNode)
+	query := func(value string) url.Values { return url.Values{name: []string{value}} }
+
+	var cases []specCase
+
+	if def, ok := schema["default"]; ok {
+		cases = append(cases, specCase{name: name + " set to its default", query: query(fmt.Sprint(def)), status: http.StatusOK})
+	}
+
+	switch schema["type"] {
+	case "integer":
+		cases = append(cases, specCase{name: name + " not an integer", query: query("badger"), status: http.StatusBadRequest})
+		if minimum, ok := schema["minimum"].(int); ok {
+			cases = append(cases,
+				specCase{name: name + " at its minimum", query: query(strconv.Itoa(minimum)), status: http.StatusOK},
+				specCase{name: name + " below its minimum", query: query(strconv.Itoa(minimum - 1)), status: http.StatusBadRequest},
+			)
+		}
+		if maximum, ok := schema["maximum"].(int); ok {
+			cases = append(cases,
+				specCase{name: name + " at its maximum", query: query(strconv.Itoa(maximum)), status: http.StatusOK},
+				specCase{name: name + " above its maximum", query: query(strconv.Itoa(maximum + 1)), status: http.StatusBadRequest},
+			)
+		}
+	case "string":
+		enum, _ := schema["enum"].([]interface{})
+		for _, value := range enum {
+			cases = append(cases, specCase{name: fmt.Sprintf("%s set to %v", name, value), query: query(fmt.Sprint(value)), status: http.StatusOK})
+		}
+	}
+
+	return cases
+}
+
+func TestGetResourcesConformsToSpecification(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.Get()
+	if err != nil {
+		t.Errorf("failed to retrieve default configuration, error: %v", err)
+	}
+
+	Convey("Given the API specification and a Data Store containing the fixtures", t, func() {
+		spec, err := loadSpecification(specificationFile)
+		So(err, ShouldBeNil)
+
+		op := spec.operation("/resources", http.MethodGet)
+		So(op, ShouldNotBeNil)
+		responses, _ := op["responses"].(specNode)
+		parameters, _ := op["parameters"].([]interface{})
+
+		failingStore := &apiMock.DataStorerMock{
+			GetResourcesFunc: func(ctx context.Context, typeParam string, options data.Options) (*models.Resources, error) {
+				return nil, errors.New("data store failure")
+			},
+		}
+
+		cases := []specCase{
+			{name: "no parameters", status: http.StatusOK},
+			{name: "offset past the last resource", query: url.Values{api.ParamOffset: []string{"100000"}}, status: http.StatusOK},
+			{name: "data store failure", failStore: true, status: http.StatusInternalServerError},
+		}
+		for _, parameter := range parameters {
+			cases = append(cases, parameterCases(parameter.(specNode))...)
+		}
+
+		Convey("Then every documented parameter is exercised", func() {
+			for _, parameter := range parameters {
+				name := parameter.(specNode)["name"].(string)
+				exercised := false
+				for _, c := range cases {
+					if c.query.Has(name) {
+						exercised = true
+					}
+				}
+				So(fmt.Sprintf("%s exercised: %t", name, exercised), ShouldEqual, name+" exercised: true")
+			}
+		})
+
+		Convey("Then every documented status code is exercised", func() {
+			for status := range responses {
+				exercised := false
+				for _, c := range cases {
+					if strconv.Itoa(c.status) == status {
+						exercised = true
+					}
+				}
+				So(fmt.Sprintf("%s exercised: %t", status, exercised), ShouldEqual, status+" exercised: true")
+			}
+		})
+
+		for _, c := range cases {
+			var store api.DataStorer = &data.ResourceStore{}
+			if c.failStore {
+				store = failingStore
+			}
+			apiInstance := api.Setup(mux.NewRouter(), cfg, store, &apiMock.SchemaRegistryMock{}, &apiMock.VirtualClockMock{}, &apiMock.HealthSimulatorMock{}, nil)
+
+			Convey(fmt.Sprintf("When a request is made with %s", c.name), func() {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:29600/resources?"+c.query.Encode(), http.NoBody)
+				resp := httptest.NewRecorder()
+				apiInstance.Router.ServeHTTP(resp, req)
+
+				Convey(fmt.Sprintf("Then the documented status code %d is returned", c.status), func() {
+					So(resp.Code, ShouldEqual, c.status)
+					So(responses, ShouldContainKey, strconv.Itoa(resp.Code))
+				})
+
+				response, _ := responses[strconv.Itoa(resp.Code)].(specNode)
+				content, _ := response["content"].(specNode)
+				if len(content) == 0 {
+					return
+				}
+
+				Convey("Then the response body conforms to the documented schema", func() {
+					contentType := strings.Split(resp.Header().Get("Content-Type"), ";")[0]
+					So(content, ShouldContainKey, contentType)
+
+					media, _ := content[contentType].(specNode)
+					schema, _ := media["schema"].(specNode)
+
+					var body interface{}
+					So(json.Unmarshal(resp.Body.Bytes(), &body), ShouldBeNil)
+					So(spec.check(spec.root, schema, body, "response"), ShouldBeEmpty)
+				})
+
+				Convey("Then any omitted parameter takes its documented default", func() {
+					var body map[string]interface{}
+					if json.Unmarshal(resp.Body.Bytes(), &body) != nil {
+						return
+					}
+					for _, parameter := range parameters {
+						p := parameter.(specNode)
+						name := p["name"].(string)
+						schema, _ := p["schema"].(specNode)
+						returned, echoed := body[name]
+						if c.query.Has(name) || !echoed || schema["default"] == nil {
+							continue
+						}
+						So(fmt.Sprint(returned), ShouldEqual, fmt.Sprint(schema["default"]))
+					}
+				})
+			})
+		}
+	})
+}
+
+func TestSpecificationOneOf(t *testing.T) {
+	t.Parallel()
+
+	Convey("Given a schema with oneOf two alternatives that both allow a uri", t, func() {
+		spec := &specification{docs: map[string]specNode{}, root: "inline"}
+		uriObject := func(required string) specNode {
+			return specNode{
+				"type":       "object",
+				"properties": specNode{"uri": specNode{"type": "string"}, "title": specNode{"type": "string"}},
+				"required":   []interface{}{required},
+			}
+		}
+		schema := specNode{"oneOf": []interface{}{uriObject("uri"), uriObject("title")}}
+
+		Convey("When a value matches exactly one alternative, it conforms", func() {
+			So(spec.check(spec.root, schema, map[string]interface{}{"title": "Title"}, "item"), ShouldBeEmpty)
+		})
+
+		Convey("When a value matches both alternatives, it does not conform", func() {
+			problems := spec.check(spec.root, schema, map[string]interface{}{"uri": "/a", "title": "Title"}, "item")
+			So(problems, ShouldResemble, []string{"item: matches more than one documented schema (alternatives [0 1])"})
+		})
+
+		Convey("When a value matches neither alternative, it does not conform", func() {
+			So(spec.check(spec.root, schema, map[string]interface{}{"summary": "Summary"}, "item"), ShouldHaveLength, 1)
+		})
+	})
+}
@@ -10,4 +10,15 @@ var (
 	ErrInvalidOffsetParameter = errors.New("invalid offset query parameter")
 	ErrInvalidLimitParameter  = errors.New("invalid limit query parameter")
 	ErrLimitOverMax           = errors.New("limit query parameter is larger than the maximum allowed")
+	ErrInvalidResourceBody    = errors.New("invalid resource request body")
+	ErrInvalidEventRequest    = errors.New("invalid event request body")
+	ErrEventSource            = errors.New("event request must give exactly one of fixture or payload")
+	ErrInvalidEventPayload    = errors.New("event payload is not a valid resource for the topic")
+	ErrInvalidClockRequest    = errors.New("invalid clock request body")
+	ErrInvalidClockTime       = errors.New("clock time must be an RFC 3339 date-time or relative to now, such as now+7d")
+	ErrInvalidClockSpeed      = errors.New("clock speed must be greater than zero")
+	ErrInvalidClockDuration   = errors.New("clock duration must be given in weeks, days, hours, minutes and seconds, such as 7d or -1w2d12h")
+	ErrInvalidHealthRequest   = errors.New("invalid health request body")
+	ErrHealthSource           = errors.New("health request must give exactly one of status or steps")
+	ErrInvalidHealthDuration  = errors.New("health duration must be given in weeks, days, hours, minutes and seconds, such as 30s or 1h")
 )
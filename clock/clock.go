@@ -0,0 +1,114 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time, so that the stub can run on a time other than the real one
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is a Clock that tells the real time in UTC
+type Real struct{}
+
+// Now returns the current real time in UTC
+func (Real) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// Simulated is a Clock that starts at a chosen time and then runs at a multiple of the speed of its source clock.
+// It can be set, frozen and advanced while it is running.
+type Simulated struct {
+	mutex       sync.RWMutex
+	source      Clock
+	start       time.Time
+	sourceStart time.Time
+	speed       float64
+	frozen      bool
+}
+
+// NewSimulated creates a Simulated clock reading start now, and running speed times as fast as the source clock
+func NewSimulated(source Clock, start time.Time, speed float64) *Simulated {
+	return &Simulated{
+		source:      source,
+		start:       start,
+		sourceStart: source.Now(),
+		speed:       speed,
+	}
+}
+
+// Now returns the simulated time
+func (c *Simulated) Now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.now()
+}
+
+// Speed returns how many times faster than its source the clock runs while it is not frozen
+func (c *Simulated) Speed() float64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.speed
+}
+
+// Frozen reports whether the clock is frozen
+func (c *Simulated) Frozen() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.frozen
+}
+
+// Set moves the clock to the time t, from which it carries on running unless it is frozen
+func (c *Simulated) Set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.restart(t)
+}
+
+// SetSpeed changes how many times faster than its source the clock runs, from the current time
+func (c *Simulated) SetSpeed(speed float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.restart(c.now())
+	c.speed = speed
+}
+
+// Freeze stops the clock at the current time
+func (c *Simulated) Freeze() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.restart(c.now())
+	c.frozen = true
+}
+
+// Resume starts a frozen clock running again from the time it was frozen at
+func (c *Simulated) Resume() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.restart(c.now())
+	c.frozen = false
+}
+
+// Advance moves the clock on by the duration d, or back if d is negative
+func (c *Simulated) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.restart(c.now().Add(d))
+}
+
+// now returns the simulated time. The caller must hold the clock's lock.
+func (c *Simulated) now() time.Time {
+	if c.frozen {
+		return c.start
+	}
+	elapsed := c.source.Now().Sub(c.sourceStart)
+	return c.start.Add(time.Duration(float64(elapsed) * c.speed))
+}
+
+// restart makes the clock read t now. The caller must hold the clock's lock.
+func (c *Simulated) restart(t time.Time) {
+	c.start = t
+	c.sourceStart = c.source.Now()
+}
@@ -0,0 +1,121 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestSimulated(t *testing.T) {
+	Convey("Given a source clock", t, func() {
+		source := &fakeClock{now: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+		start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		Convey("When a simulated clock is created from it", func() {
+			c := clock.NewSimulated(source, start, 1)
+
+			Convey("Then it reads the start time", func() {
+				So(c.Now(), ShouldEqual, start)
+			})
+
+			Convey("Then it moves on at the same speed as the source clock", func() {
+				source.now = source.now.Add(time.Hour)
+				So(c.Now(), ShouldEqual, start.Add(time.Hour))
+			})
+		})
+
+		Convey("When a simulated clock is created to run faster than the source clock", func() {
+			c := clock.NewSimulated(source, start, 24)
+
+			Convey("Then it moves on at a multiple of the source clock's speed", func() {
+				source.now = source.now.Add(time.Hour)
+				So(c.Now(), ShouldEqual, start.Add(24*time.Hour))
+			})
+		})
+
+		Convey("When a simulated clock is created with no speed", func() {
+			c := clock.NewSimulated(source, start, 0)
+
+			Convey("Then it stays at the start time", func() {
+				source.now = source.now.Add(time.Hour)
+				So(c.Now(), ShouldEqual, start)
+			})
+		})
+	})
+}
+
+func TestSimulatedControls(t *testing.T) {
+	Convey("Given a running simulated clock", t, func() {
+		source := &fakeClock{now: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+		start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		c := clock.NewSimulated(source, start, 2)
+
+		Convey("When it is set to another time", func() {
+			source.now = source.now.Add(time.Hour)
+			c.Set(start.Add(-24 * time.Hour))
+
+			Convey("Then it reads that time and carries on running from it", func() {
+				So(c.Now(), ShouldEqual, start.Add(-24*time.Hour))
+				source.now = source.now.Add(time.Hour)
+				So(c.Now(), ShouldEqual, start.Add(-22*time.Hour))
+			})
+		})
+
+		Convey("When its speed is changed", func() {
+			source.now = source.now.Add(time.Hour)
+			c.SetSpeed(10)
+
+			Convey("Then it runs at the new speed from the current time", func() {
+				So(c.Speed(), ShouldEqual, 10)
+				So(c.Now(), ShouldEqual, start.Add(2*time.Hour))
+				source.now = source.now.Add(time.Hour)
+				So(c.Now(), ShouldEqual, start.Add(12*time.Hour))
+			})
+		})
+
+		Convey("When it is frozen", func() {
+			source.now = source.now.Add(time.Hour)
+			c.Freeze()
+			source.now = source.now.Add(time.Hour)
+
+			Convey("Then it stays at the time it was frozen at", func() {
+				So(c.Frozen(), ShouldBeTrue)
+				So(c.Now(), ShouldEqual, start.Add(2*time.Hour))
+			})
+
+			Convey("Then it can still be set and advanced", func() {
+				c.Advance(24 * time.Hour)
+				So(c.Now(), ShouldEqual, start.Add(26*time.Hour))
+				c.Set(start)
+				So(c.Now(), ShouldEqual, start)
+			})
+
+			Convey("Then it carries on from the same time when resumed", func() {
+				c.Resume()
+				So(c.Frozen(), ShouldBeFalse)
+				So(c.Now(), ShouldEqual, start.Add(2*time.Hour))
+				source.now = source.now.Add(time.Hour)
+				So(c.Now(), ShouldEqual, start.Add(4*time.Hour))
+			})
+		})
+
+		Convey("When it is advanced", func() {
+			c.Advance(7 * 24 * time.Hour)
+
+			Convey("Then it reads the later time", func() {
+				So(c.Now(), ShouldEqual, start.Add(7*24*time.Hour))
+			})
+		})
+	})
+}
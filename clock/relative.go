@@ -0,0 +1,66 @@
+package clock
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDuration is returned when a duration is not in the form accepted by ParseDuration
+var ErrInvalidDuration = errors.New("invalid duration")
+
+var (
+	durationPattern = regexp.MustCompile(`^[+-]?(\d+[wdhms])+$`)
+	durationPart    = regexp.MustCompile(`(\d+)([wdhms])`)
+	relativePattern = regexp.MustCompile(`^now([+-].+)?$`)
+)
+
+var durationUnits = map[string]time.Duration{
+	"w": 7 * 24 * time.Hour,
+	"d": 24 * time.Hour,
+	"h": time.Hour,
+	"m": time.Minute,
+	"s": time.Second,
+}
+
+// ParseDuration parses a signed sequence of whole numbers of weeks (w), days (d), hours (h), minutes (m) and
+// seconds (s), such as 7d or -1w2d12h
+func ParseDuration(value string) (time.Duration, error) {
+	if !durationPattern.MatchString(value) {
+		return 0, ErrInvalidDuration
+	}
+
+	var d time.Duration
+	for _, part := range durationPart.FindAllStringSubmatch(value, -1) {
+		n, err := strconv.Atoi(part[1])
+		if err != nil {
+			return 0, ErrInvalidDuration
+		}
+		d += time.Duration(n) * durationUnits[part[2]]
+	}
+
+	if strings.HasPrefix(value, "-") {
+		return -d, nil
+	}
+	return d, nil
+}
+
+// ParseRelative parses a time given relative to now, as now followed by an optional duration in the form accepted by
+// ParseDuration, such as now+7d or now-1w. It reports false if the value is not a relative time.
+func ParseRelative(value string, now time.Time) (time.Time, bool) {
+	match := relativePattern.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, false
+	}
+	if match[1] == "" {
+		return now, true
+	}
+
+	d, err := ParseDuration(match[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return now.Add(d), true
+}
@@ -0,0 +1,71 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
+)
+
+func TestParseDuration(t *testing.T) {
+	Convey("Given durations in weeks, days, hours, minutes and seconds", t, func() {
+		cases := map[string]time.Duration{
+			"7d":       7 * 24 * time.Hour,
+			"+1w":      7 * 24 * time.Hour,
+			"-1w2d12h": -(9*24 + 12) * time.Hour,
+			"90m30s":   90*time.Minute + 30*time.Second,
+		}
+
+		Convey("When they are parsed", func() {
+			Convey("Then the durations they describe are returned", func() {
+				for value, expected := range cases {
+					d, err := clock.ParseDuration(value)
+					So(err, ShouldBeNil)
+					So(d, ShouldEqual, expected)
+				}
+			})
+		})
+	})
+
+	Convey("Given values that are not durations of that form", t, func() {
+		Convey("When they are parsed", func() {
+			Convey("Then an invalid duration error is returned", func() {
+				for _, value := range []string{"", "7", "d", "1.5h", "7 days", "1y", "+-1d"} {
+					_, err := clock.ParseDuration(value)
+					So(err, ShouldEqual, clock.ErrInvalidDuration)
+				}
+			})
+		})
+	})
+}
+
+func TestParseRelative(t *testing.T) {
+	Convey("Given a time", t, func() {
+		now := time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)
+
+		Convey("When times relative to it are parsed", func() {
+			Convey("Then they are resolved against it", func() {
+				for value, expected := range map[string]time.Time{
+					"now":      now,
+					"now+7d":   now.Add(7 * 24 * time.Hour),
+					"now-1w1h": now.Add(-(7*24 + 1) * time.Hour),
+				} {
+					got, ok := clock.ParseRelative(value, now)
+					So(ok, ShouldBeTrue)
+					So(got, ShouldEqual, expected)
+				}
+			})
+		})
+
+		Convey("When values that are not relative times are parsed", func() {
+			Convey("Then they are reported as not relative", func() {
+				for _, value := range []string{"", "2025-01-06T09:30:00Z", "now7d", "now+", "now+1y", "tomorrow"} {
+					_, ok := clock.ParseRelative(value, now)
+					So(ok, ShouldBeFalse)
+				}
+			})
+		})
+	})
+}
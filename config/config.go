@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -12,9 +14,13 @@ const KafkaTLSProtocol = "TLS"
 // Config represents service configuration for dis-search-upstream-stub
 type Config struct {
 	BindAddr                   string        `envconfig:"BIND_ADDR"`
+	ClockStart                 time.Time     `envconfig:"CLOCK_START"`
+	ClockSpeed                 float64       `envconfig:"CLOCK_SPEED"`
 	DefaultLimit               int           `envconfig:"DEFAULT_LIMIT"`
 	DefaultMaxLimit            int           `envconfig:"DEFAULT_MAXIMUM_LIMIT"`
 	DefaultOffset              int           `envconfig:"DEFAULT_OFFSET"`
+	EventPublishingEnabled     bool          `envconfig:"EVENT_PUBLISHING_ENABLED"`
+	EventDelay                 time.Duration `envconfig:"EVENT_DELAY"`
 	GracefulShutdownTimeout    time.Duration `envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT"`
 	HealthCheckInterval        time.Duration `envconfig:"HEALTHCHECK_INTERVAL"`
 	HealthCheckCriticalTimeout time.Duration `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
@@ -22,31 +28,43 @@ type Config struct {
 	OTExporterOTLPEndpoint     string        `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 	OTServiceName              string        `envconfig:"OTEL_SERVICE_NAME"`
 	OtelEnabled                bool          `envconfig:"OTEL_ENABLED"`
+	RejectInvalidFixtures      bool          `envconfig:"REJECT_INVALID_FIXTURES"`
+	ResourceChangesEnabled     bool          `envconfig:"RESOURCE_CHANGES_ENABLED"`
+	ReleaseSchedulerEnabled    bool          `envconfig:"RELEASE_SCHEDULER_ENABLED"`
+	ReleaseSchedulerInterval   time.Duration `envconfig:"RELEASE_SCHEDULER_INTERVAL"`
+	ReleaseConfirmationPeriod  time.Duration `envconfig:"RELEASE_CONFIRMATION_PERIOD"`
 	Kafka                      *Kafka
 }
 
 // Kafka contains the config required to connect to Kafka
 type Kafka struct {
-	ContentUpdatedGroup       string   `envconfig:"KAFKA_CONTENT_UPDATED_GROUP"`
-	ContentUpdatedTopic       string   `envconfig:"KAFKA_CONTENT_UPDATED_TOPIC"`
-	SearchContentUpdatedTopic string   `envconfig:"KAFKA_SEARCH_CONTENT_UPDATED_TOPIC"`
-	SearchContentDeletedTopic string   `envconfig:"KAFKA_SEARCH_CONTENT_DELETED_TOPIC"`
-	Addr                      []string `envconfig:"KAFKA_ADDR"`
-	Version                   string   `envconfig:"KAFKA_VERSION"`
-	OffsetOldest              bool     `envconfig:"KAFKA_OFFSET_OLDEST"`
-	NumWorkers                int      `envconfig:"KAFKA_NUM_WORKERS"`
-	SecProtocol               string   `envconfig:"KAFKA_SEC_PROTO"`
-	SecCACerts                string   `envconfig:"KAFKA_SEC_CA_CERTS"            json:"-"`
-	SecClientCert             string   `envconfig:"KAFKA_SEC_CLIENT_CERT"         json:"-"`
-	SecClientKey              string   `envconfig:"KAFKA_SEC_CLIENT_KEY"          json:"-"`
-	SecSkipVerify             bool     `envconfig:"KAFKA_SEC_SKIP_VERIFY"`
-	MaxBytes                  int      `envconfig:"KAFKA_MAX_BYTES"`
-	ConsumerMinBrokersHealthy int      `envconfig:"KAFKA_CONSUMER_MIN_BROKERS_HEALTHY"`
-	ProducerMinBrokersHealthy int      `envconfig:"KAFKA_PRODUCER_MIN_BROKERS_HEALTHY"`
+	ContentUpdatedGroup          string   `envconfig:"KAFKA_CONTENT_UPDATED_GROUP"`
+	ContentUpdatedTopic          string   `envconfig:"KAFKA_CONTENT_UPDATED_TOPIC"`
+	SearchContentUpdatedTopic    string   `envconfig:"KAFKA_SEARCH_CONTENT_UPDATED_TOPIC"`
+	SearchContentDeletedTopic    string   `envconfig:"KAFKA_SEARCH_CONTENT_DELETED_TOPIC"`
+	ContentUpdatedEncoding       string   `envconfig:"KAFKA_CONTENT_UPDATED_ENCODING"`
+	SearchContentUpdatedEncoding string   `envconfig:"KAFKA_SEARCH_CONTENT_UPDATED_ENCODING"`
+	SearchContentDeletedEncoding string   `envconfig:"KAFKA_SEARCH_CONTENT_DELETED_ENCODING"`
+	SchemaRegistryFraming        bool     `envconfig:"KAFKA_SCHEMA_REGISTRY_FRAMING"`
+	Addr                         []string `envconfig:"KAFKA_ADDR"`
+	Version                      string   `envconfig:"KAFKA_VERSION"`
+	OffsetOldest                 bool     `envconfig:"KAFKA_OFFSET_OLDEST"`
+	NumWorkers                   int      `envconfig:"KAFKA_NUM_WORKERS"`
+	SecProtocol                  string   `envconfig:"KAFKA_SEC_PROTO"`
+	SecCACerts                   string   `envconfig:"KAFKA_SEC_CA_CERTS"            json:"-"`
+	SecClientCert                string   `envconfig:"KAFKA_SEC_CLIENT_CERT"         json:"-"`
+	SecClientKey                 string   `envconfig:"KAFKA_SEC_CLIENT_KEY"          json:"-"`
+	SecSkipVerify                bool     `envconfig:"KAFKA_SEC_SKIP_VERIFY"`
+	MaxBytes                     int      `envconfig:"KAFKA_MAX_BYTES"`
+	ConsumerMinBrokersHealthy    int      `envconfig:"KAFKA_CONSUMER_MIN_BROKERS_HEALTHY"`
+	ProducerMinBrokersHealthy    int      `envconfig:"KAFKA_PRODUCER_MIN_BROKERS_HEALTHY"`
 }
 
 var cfg *Config
 
+// encodings are the encodings that events can be marshalled with, as defined by the events package
+var encodings = map[string]bool{"avro": true, "json": true}
+
 // Get returns the default config with any modifications through environment
 // variables
 func Get() (*Config, error) {
@@ -56,9 +74,13 @@ func Get() (*Config, error) {
 
 	cfg = &Config{
 		BindAddr:                   ":29600",
+		ClockStart:                 time.Time{},
+		ClockSpeed:                 1,
 		DefaultLimit:               20,
 		DefaultMaxLimit:            1000,
 		DefaultOffset:              0,
+		EventPublishingEnabled:     false,
+		EventDelay:                 0,
 		GracefulShutdownTimeout:    5 * time.Second,
 		HealthCheckInterval:        30 * time.Second,
 		HealthCheckCriticalTimeout: 90 * time.Second,
@@ -66,25 +88,57 @@ func Get() (*Config, error) {
 		OTExporterOTLPEndpoint:     "localhost:4317",
 		OTServiceName:              "dis-search-upstream-stub",
 		OtelEnabled:                false,
+		RejectInvalidFixtures:      false,
+		ResourceChangesEnabled:     false,
+		ReleaseSchedulerEnabled:    false,
+		ReleaseSchedulerInterval:   time.Second,
+		ReleaseConfirmationPeriod:  4 * 7 * 24 * time.Hour,
 		Kafka: &Kafka{
-			ContentUpdatedGroup:       "dis-search-upstream-stub",
-			ContentUpdatedTopic:       "content-updated",
-			SearchContentUpdatedTopic: "search-content-updated",
-			SearchContentDeletedTopic: "search-content-deleted",
-			Addr:                      []string{"localhost:9092", "localhost:9093", "localhost:9094"},
-			Version:                   "1.0.2",
-			OffsetOldest:              true,
-			NumWorkers:                1,
-			SecProtocol:               "",
-			SecCACerts:                "",
-			SecClientCert:             "",
-			SecClientKey:              "",
-			SecSkipVerify:             false,
-			MaxBytes:                  2000000,
-			ConsumerMinBrokersHealthy: 1,
-			ProducerMinBrokersHealthy: 1,
+			ContentUpdatedGroup:          "dis-search-upstream-stub",
+			ContentUpdatedTopic:          "content-updated",
+			SearchContentUpdatedTopic:    "search-content-updated",
+			SearchContentDeletedTopic:    "search-content-deleted",
+			ContentUpdatedEncoding:       "avro",
+			SearchContentUpdatedEncoding: "json",
+			SearchContentDeletedEncoding: "json",
+			SchemaRegistryFraming:        false,
+			Addr:                         []string{"localhost:9092", "localhost:9093", "localhost:9094"},
+			Version:                      "1.0.2",
+			OffsetOldest:                 true,
+			NumWorkers:                   1,
+			SecProtocol:                  "",
+			SecCACerts:                   "",
+			SecClientCert:                "",
+			SecClientKey:                 "",
+			SecSkipVerify:                false,
+			MaxBytes:                     2000000,
+			ConsumerMinBrokersHealthy:    1,
+			ProducerMinBrokersHealthy:    1,
 		},
 	}
 
-	return cfg, envconfig.Process("", cfg)
+	if err := envconfig.Process("", cfg); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.validate()
+}
+
+// validate checks the config values that envconfig cannot check by their type
+func (c *Config) validate() error {
+	if c.ClockSpeed <= 0 {
+		return errors.New("CLOCK_SPEED must be greater than zero")
+	}
+
+	for name, encoding := range map[string]string{
+		"KAFKA_CONTENT_UPDATED_ENCODING":        c.Kafka.ContentUpdatedEncoding,
+		"KAFKA_SEARCH_CONTENT_UPDATED_ENCODING": c.Kafka.SearchContentUpdatedEncoding,
+		"KAFKA_SEARCH_CONTENT_DELETED_ENCODING": c.Kafka.SearchContentDeletedEncoding,
+	} {
+		if !encodings[encoding] {
+			return fmt.Errorf("%s must be avro or json, not %q", name, encoding)
+		}
+	}
+
+	return nil
 }
@@ -20,9 +20,13 @@ func TestConfig(t *testing.T) {
 
 			Convey("Then the values should be set to the expected defaults", func() {
 				So(cfg.BindAddr, ShouldEqual, ":29600")
+				So(cfg.ClockStart.IsZero(), ShouldBeTrue)
+				So(cfg.ClockSpeed, ShouldEqual, 1)
 				So(cfg.DefaultLimit, ShouldEqual, 20)
 				So(cfg.DefaultMaxLimit, ShouldEqual, 1000)
 				So(cfg.DefaultOffset, ShouldEqual, 0)
+				So(cfg.EventPublishingEnabled, ShouldBeFalse)
+				So(cfg.EventDelay, ShouldEqual, 0)
 				So(cfg.GracefulShutdownTimeout, ShouldEqual, 5*time.Second)
 				So(cfg.HealthCheckInterval, ShouldEqual, 30*time.Second)
 				So(cfg.HealthCheckCriticalTimeout, ShouldEqual, 90*time.Second)
@@ -30,10 +34,19 @@ func TestConfig(t *testing.T) {
 				So(cfg.OTExporterOTLPEndpoint, ShouldEqual, "localhost:4317")
 				So(cfg.OTServiceName, ShouldEqual, "dis-search-upstream-stub")
 				So(cfg.OtelEnabled, ShouldBeFalse)
+				So(cfg.RejectInvalidFixtures, ShouldBeFalse)
+				So(cfg.ResourceChangesEnabled, ShouldBeFalse)
+				So(cfg.ReleaseSchedulerEnabled, ShouldBeFalse)
+				So(cfg.ReleaseSchedulerInterval, ShouldEqual, time.Second)
+				So(cfg.ReleaseConfirmationPeriod, ShouldEqual, 4*7*24*time.Hour)
 				So(cfg.Kafka.ContentUpdatedGroup, ShouldEqual, "dis-search-upstream-stub")
 				So(cfg.Kafka.ContentUpdatedTopic, ShouldEqual, "content-updated")
 				So(cfg.Kafka.SearchContentUpdatedTopic, ShouldEqual, "search-content-updated")
 				So(cfg.Kafka.SearchContentDeletedTopic, ShouldEqual, "search-content-deleted")
+				So(cfg.Kafka.ContentUpdatedEncoding, ShouldEqual, "avro")
+				So(cfg.Kafka.SearchContentUpdatedEncoding, ShouldEqual, "json")
+				So(cfg.Kafka.SearchContentDeletedEncoding, ShouldEqual, "json")
+				So(cfg.Kafka.SchemaRegistryFraming, ShouldBeFalse)
 				So(cfg.Kafka.Addr, ShouldResemble, []string{"localhost:9092", "localhost:9093", "localhost:9094"})
 				So(cfg.Kafka.Version, ShouldEqual, "1.0.2")
 				So(cfg.Kafka.OffsetOldest, ShouldBeTrue)
@@ -50,3 +63,45 @@ func TestConfig(t *testing.T) {
 		})
 	})
 }
+
+func TestConfigValidation(t *testing.T) {
+	Convey("Given an environment with a clock speed that is not positive", t, func() {
+		os.Clearenv()
+		cfg = nil
+		So(os.Setenv("CLOCK_SPEED", "0"), ShouldBeNil)
+
+		Convey("When the config is retrieved", func() {
+			_, err := Get()
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "CLOCK_SPEED")
+			})
+		})
+
+		Reset(func() {
+			os.Clearenv()
+			cfg = nil
+		})
+	})
+
+	Convey("Given an environment with a kafka encoding that is not avro or json", t, func() {
+		os.Clearenv()
+		cfg = nil
+		So(os.Setenv("KAFKA_SEARCH_CONTENT_DELETED_ENCODING", "protobuf"), ShouldBeNil)
+
+		Convey("When the config is retrieved", func() {
+			_, err := Get()
+
+			Convey("Then an error naming the encoding is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, `KAFKA_SEARCH_CONTENT_DELETED_ENCODING must be avro or json, not "protobuf"`)
+			})
+		})
+
+		Reset(func() {
+			os.Clearenv()
+			cfg = nil
+		})
+	})
+}
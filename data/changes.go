@@ -0,0 +1,227 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/validation"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/pkg/errors"
+)
+
+// A list of errors returned when changing resources
+var (
+	ErrURIRequired      = errors.New("resource uri is required")
+	ErrResourceExists   = errors.New("resource already exists")
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrInvalidResource  = errors.New("resource does not satisfy the search contract")
+)
+
+// changes are the search-content-updated resources created, updated and deleted through the store,
+// which are applied over the fixtures whenever resources are retrieved
+type changes struct {
+	// upserted holds the current version of each created or updated resource, by uri
+	upserted map[string]models.SearchContentUpdatedResource
+	// created lists the uris of resources that are not fixtures, in the order they were created
+	created []string
+	// deleted lists the uris of deleted resources, in the order they were deleted
+	deleted []string
+}
+
+// CreateResource adds a search-content-updated resource to the store and emits its event. The resource must satisfy
+// the search contract once any relative dates are resolved.
+func (r *ResourceStore) CreateResource(ctx context.Context, resource models.SearchContentUpdatedResource) error {
+	if resource.URI == "" {
+		return ErrURIRequired
+	}
+	if err := r.validate(resource); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	fixture, exists, err := r.exists(resource.URI)
+	if err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+	if exists {
+		r.mutex.Unlock()
+		return ErrResourceExists
+	}
+
+	r.changes.upserted[resource.URI] = resource
+	r.changes.deleted = remove(r.changes.deleted, resource.URI)
+	if !fixture {
+		r.changes.created = append(r.changes.created, resource.URI)
+	}
+	r.mutex.Unlock()
+
+	log.Info(ctx, "resource created", log.Data{"uri": resource.URI})
+	r.emit(ctx, r.current(resource))
+	return nil
+}
+
+// UpdateResource replaces the search-content-updated resource at the uri and emits its event. The resource must
+// satisfy the search contract once any relative dates are resolved.
+func (r *ResourceStore) UpdateResource(ctx context.Context, uri string, resource models.SearchContentUpdatedResource) error {
+	if uri == "" {
+		return ErrURIRequired
+	}
+	resource.URI = uri
+	if err := r.validate(resource); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	_, exists, err := r.exists(uri)
+	if err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+	if !exists {
+		r.mutex.Unlock()
+		return ErrResourceNotFound
+	}
+
+	r.changes.upserted[uri] = resource
+	r.mutex.Unlock()
+
+	log.Info(ctx, "resource updated", log.Data{"uri": uri})
+	r.emit(ctx, r.current(resource))
+	return nil
+}
+
+// DeleteResource removes the search-content-updated resource at the uri and emits a search-content-deleted event
+func (r *ResourceStore) DeleteResource(ctx context.Context, uri string) error {
+	if uri == "" {
+		return ErrURIRequired
+	}
+
+	r.mutex.Lock()
+	_, exists, err := r.exists(uri)
+	if err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+	if !exists {
+		r.mutex.Unlock()
+		return ErrResourceNotFound
+	}
+
+	delete(r.changes.upserted, uri)
+	r.changes.created = remove(r.changes.created, uri)
+	r.changes.deleted = append(r.changes.deleted, uri)
+	r.mutex.Unlock()
+
+	log.Info(ctx, "resource deleted", log.Data{"uri": uri})
+	r.emit(ctx, models.SearchContentDeletedResource{URI: uri})
+	return nil
+}
+
+// exists reports whether the uri is a fixture and whether a resource currently exists at the uri.
+// The caller must hold the store's lock.
+func (r *ResourceStore) exists(uri string) (fixture, exists bool, err error) {
+	if r.changes.upserted == nil {
+		r.changes.upserted = map[string]models.SearchContentUpdatedResource{}
+	}
+
+	uris, err := fixtureURIs()
+	if err != nil {
+		return false, false, err
+	}
+	fixture = uris[uri]
+
+	if contains(r.changes.deleted, uri) {
+		return fixture, false, nil
+	}
+	_, upserted := r.changes.upserted[uri]
+	return fixture, fixture || upserted, nil
+}
+
+// applyChanges applies the changes made through the store to the resources read from the fixtures
+func (r *ResourceStore) applyChanges(resourceType string, items []models.Resource) []models.Resource {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	switch resourceType {
+	case searchContentUpdatedResourceType:
+		changed := make([]models.Resource, 0, len(items)+len(r.changes.created))
+		for _, item := range items {
+			uri := item.(models.SearchContentUpdatedResource).URI
+			if contains(r.changes.deleted, uri) {
+				continue
+			}
+			if upserted, ok := r.changes.upserted[uri]; ok {
+				item = upserted
+			}
+			changed = append(changed, item)
+		}
+		for _, uri := range r.changes.created {
+			changed = append(changed, r.changes.upserted[uri])
+		}
+		return changed
+	case searchContentDeletedResourceType:
+		for _, uri := range r.changes.deleted {
+			items = append(items, models.SearchContentDeletedResource{URI: uri})
+		}
+		return items
+	default:
+		return items
+	}
+}
+
+// applyCurrent resolves the relative dates of the search-content-updated resources, and sets the current lifecycle
+// state of any releases among them
+func (r *ResourceStore) applyCurrent(items []models.Resource) []models.Resource {
+	for i, item := range items {
+		if resource, ok := item.(models.SearchContentUpdatedResource); ok {
+			items[i] = r.current(resource)
+		}
+	}
+	return items
+}
+
+// current returns the resource as it is currently served, with its relative dates resolved and the lifecycle state
+// of a release applied
+func (r *ResourceStore) current(resource models.SearchContentUpdatedResource) models.SearchContentUpdatedResource {
+	resource = resolveDates(resource, r.now())
+	if r.Lifecycle == nil {
+		return resource
+	}
+	return r.Lifecycle.Apply(resource)
+}
+
+// validate returns ErrInvalidResource, wrapping the violations, if the resource as it would be served does not satisfy
+// the search contract
+func (r *ResourceStore) validate(resource models.SearchContentUpdatedResource) error {
+	if violations := validation.Validate(r.current(resource)); len(violations) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidResource, violations)
+	}
+	return nil
+}
+
+func (r *ResourceStore) emit(ctx context.Context, resource models.Resource) {
+	if r.Emitter != nil {
+		r.Emitter.Emit(ctx, resource)
+	}
+}
+
+func contains(uris []string, uri string) bool {
+	for _, u := range uris {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(uris []string, uri string) []string {
+	kept := uris[:0]
+	for _, u := range uris {
+		if u != uri {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,177 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/metrics"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/validation"
+)
+
+const fixtureURI = "/aboutus/transparencyandgovernance/freedomofinformationfoi/multiethnicityhouseholdsintheuk"
+
+type recordingEmitter struct {
+	emitted []models.Resource
+}
+
+func (e *recordingEmitter) Emit(ctx context.Context, resource models.Resource) {
+	e.emitted = append(e.emitted, resource)
+}
+
+func findResource(items []models.Resource, uri string) (models.SearchContentUpdatedResource, bool) {
+	for _, item := range items {
+		if resource := item.(models.SearchContentUpdatedResource); resource.URI == uri {
+			return resource, true
+		}
+	}
+	return models.SearchContentUpdatedResource{}, false
+}
+
+// valid returns a resource at the uri that satisfies the search contract
+func valid(uri string) models.SearchContentUpdatedResource {
+	return models.SearchContentUpdatedResource{URI: uri, Title: "Title", ContentType: "bulletin"}
+}
+
+func TestResourceChanges(t *testing.T) {
+	Convey("Given a store with an emitter", t, func() {
+		emitter := &recordingEmitter{}
+		store := &ResourceStore{Emitter: emitter}
+		ctx := context.Background()
+		options := Options{Limit: 1000}
+
+		fixtures, err := store.GetResources(ctx, "search-content-updated", options)
+		So(err, ShouldBeNil)
+
+		Convey("When a new resource is created", func() {
+			created := models.SearchContentUpdatedResource{URI: "/new", Title: "New", ContentType: "bulletin"}
+			So(store.CreateResource(ctx, created), ShouldBeNil)
+
+			Convey("Then it is returned after the fixtures and its event is emitted", func() {
+				resources, err := store.GetResources(ctx, "search-content-updated", options)
+				So(err, ShouldBeNil)
+				So(resources.TotalCount, ShouldEqual, fixtures.TotalCount+1)
+				So(resources.Items[len(resources.Items)-1], ShouldResemble, created)
+				So(emitter.emitted, ShouldResemble, []models.Resource{created})
+			})
+
+			Convey("Then creating it again fails", func() {
+				So(store.CreateResource(ctx, created), ShouldEqual, ErrResourceExists)
+				So(emitter.emitted, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a fixture is updated", func() {
+			So(store.UpdateResource(ctx, fixtureURI, models.SearchContentUpdatedResource{URI: "/ignored", Title: "Updated", ContentType: "bulletin"}), ShouldBeNil)
+
+			Convey("Then it is replaced in place, keeping the uri, and its event is emitted", func() {
+				resources, err := store.GetResources(ctx, "search-content-updated", options)
+				So(err, ShouldBeNil)
+				So(resources.TotalCount, ShouldEqual, fixtures.TotalCount)
+
+				updated, ok := findResource(resources.Items, fixtureURI)
+				So(ok, ShouldBeTrue)
+				So(updated.Title, ShouldEqual, "Updated")
+				So(emitter.emitted, ShouldResemble, []models.Resource{
+					models.SearchContentUpdatedResource{URI: fixtureURI, Title: "Updated", ContentType: "bulletin"},
+				})
+			})
+		})
+
+		Convey("When a fixture is deleted", func() {
+			So(store.DeleteResource(ctx, fixtureURI), ShouldBeNil)
+
+			Convey("Then it is no longer returned and a search-content-deleted event is emitted", func() {
+				resources, err := store.GetResources(ctx, "search-content-updated", options)
+				So(err, ShouldBeNil)
+				So(resources.TotalCount, ShouldEqual, fixtures.TotalCount-1)
+
+				_, ok := findResource(resources.Items, fixtureURI)
+				So(ok, ShouldBeFalse)
+				So(emitter.emitted, ShouldResemble, []models.Resource{models.SearchContentDeletedResource{URI: fixtureURI}})
+			})
+
+			Convey("Then it is returned as a search-content-deleted resource", func() {
+				resources, err := store.GetResources(ctx, "search-content-deleted", options)
+				So(err, ShouldBeNil)
+				So(resources.Items[len(resources.Items)-1], ShouldResemble, models.SearchContentDeletedResource{URI: fixtureURI})
+			})
+
+			Convey("Then it can no longer be updated or deleted, but can be created again", func() {
+				So(store.UpdateResource(ctx, fixtureURI, valid(fixtureURI)), ShouldEqual, ErrResourceNotFound)
+				So(store.DeleteResource(ctx, fixtureURI), ShouldEqual, ErrResourceNotFound)
+				So(store.CreateResource(ctx, valid(fixtureURI)), ShouldBeNil)
+
+				resources, err := store.GetResources(ctx, "search-content-updated", options)
+				So(err, ShouldBeNil)
+				So(resources.TotalCount, ShouldEqual, fixtures.TotalCount)
+			})
+		})
+
+		Convey("When changes are made without a uri or to a resource that does not exist", func() {
+			Convey("Then they fail without emitting events", func() {
+				So(store.CreateResource(ctx, models.SearchContentUpdatedResource{}), ShouldEqual, ErrURIRequired)
+				So(store.CreateResource(ctx, valid(fixtureURI)), ShouldEqual, ErrResourceExists)
+				So(store.UpdateResource(ctx, "/missing", valid("/missing")), ShouldEqual, ErrResourceNotFound)
+				So(store.DeleteResource(ctx, ""), ShouldEqual, ErrURIRequired)
+				So(store.DeleteResource(ctx, "/missing"), ShouldEqual, ErrResourceNotFound)
+				So(emitter.emitted, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When resources that do not satisfy the search contract are created or updated", func() {
+			created := models.SearchContentUpdatedResource{URI: "/new", Title: "New", ContentType: "bulletin", Cancelled: true}
+			createErr := store.CreateResource(ctx, created)
+			updateErr := store.UpdateResource(ctx, fixtureURI, models.SearchContentUpdatedResource{ContentType: "bulletin", ReleaseDate: "soon"})
+
+			Convey("Then they are rejected with their violations, and neither stored nor emitted", func() {
+				var violations validation.Violations
+				So(createErr, ShouldWrap, ErrInvalidResource)
+				So(errors.As(createErr, &violations), ShouldBeTrue)
+				So(violations.Keys(), ShouldResemble, []string{"cancelled:release_only"})
+
+				So(updateErr, ShouldWrap, ErrInvalidResource)
+				So(errors.As(updateErr, &violations), ShouldBeTrue)
+				So(violations.Keys(), ShouldResemble, []string{"title:required", "release_date:format"})
+
+				resources, err := store.GetResources(ctx, "search-content-updated", options)
+				So(err, ShouldBeNil)
+				So(resources.Items, ShouldResemble, fixtures.Items)
+				So(emitter.emitted, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a resource with a relative date is created", func() {
+			created := valid("/new")
+			created.ReleaseDate = "now+1d"
+
+			Convey("Then the date is resolved before the resource is validated", func() {
+				So(store.CreateResource(ctx, created), ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestResourceChangesFixtureReloads(t *testing.T) {
+	Convey("Given a store with metrics", t, func() {
+		store := &ResourceStore{Metrics: metrics.New()}
+		ctx := context.Background()
+
+		Convey("When resources are created, updated and deleted", func() {
+			So(store.CreateResource(ctx, valid("/new")), ShouldBeNil)
+			So(store.UpdateResource(ctx, fixtureURI, valid(fixtureURI)), ShouldBeNil)
+			So(store.DeleteResource(ctx, "/new"), ShouldBeNil)
+
+			Convey("Then the fixtures are not read again", func() {
+				resp := httptest.NewRecorder()
+				store.Metrics.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+				So(resp.Body.String(), ShouldNotContainSubstring, "fixture_reloads_total{")
+			})
+		})
+	})
+}
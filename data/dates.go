@@ -0,0 +1,44 @@
+package data
+
+import (
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// provisionalDateLayout is the month and year form of the provisional dates of releases
+const provisionalDateLayout = "January 2006"
+
+// resolveDates replaces the dates in the resource that are given relative to the clock, such as now+7d, with the
+// dates they resolve to at the time now. Other dates are left as they are.
+func resolveDates(resource models.SearchContentUpdatedResource, now time.Time) models.SearchContentUpdatedResource {
+	if t, ok := clock.ParseRelative(resource.ReleaseDate, now); ok {
+		resource.ReleaseDate = t.UTC().Truncate(time.Second).Format(time.RFC3339)
+	}
+
+	if t, ok := clock.ParseRelative(resource.ProvisionalDate, now); ok {
+		resource.ProvisionalDate = t.UTC().Format(provisionalDateLayout)
+	}
+
+	if len(resource.DateChanges) > 0 {
+		dateChanges := make([]models.ReleaseDateDetails, len(resource.DateChanges))
+		for i, dateChange := range resource.DateChanges {
+			if t, ok := clock.ParseRelative(dateChange.PreviousDate, now); ok {
+				dateChange.PreviousDate = t.UTC().Truncate(time.Second).Format(time.RFC3339)
+			}
+			dateChanges[i] = dateChange
+		}
+		resource.DateChanges = dateChanges
+	}
+
+	return resource
+}
+
+// now returns the time on the store's clock, or the real time if it has no clock
+func (r *ResourceStore) now() time.Time {
+	if r.Clock == nil {
+		return clock.Real{}.Now()
+	}
+	return r.Clock.Now()
+}
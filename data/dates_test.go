@@ -0,0 +1,79 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestResolveDates(t *testing.T) {
+	Convey("Given a release with dates relative to the clock", t, func() {
+		now := time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)
+		dateChanges := []models.ReleaseDateDetails{
+			{ChangeNotice: "moved", PreviousDate: "now-7d"},
+			{ChangeNotice: "fixed", PreviousDate: "2024-12-01T09:30:00Z"},
+		}
+		release := models.SearchContentUpdatedResource{
+			ContentType:     models.ReleaseContentType,
+			ReleaseDate:     "now+28d",
+			ProvisionalDate: "now+90d",
+			DateChanges:     dateChanges,
+		}
+
+		Convey("When its dates are resolved", func() {
+			resolved := resolveDates(release, now)
+
+			Convey("Then the relative dates are replaced with the dates they resolve to", func() {
+				So(resolved.ReleaseDate, ShouldEqual, "2025-02-03T09:30:00Z")
+				So(resolved.ProvisionalDate, ShouldEqual, "April 2025")
+				So(resolved.DateChanges, ShouldResemble, []models.ReleaseDateDetails{
+					{ChangeNotice: "moved", PreviousDate: "2024-12-30T09:30:00Z"},
+					{ChangeNotice: "fixed", PreviousDate: "2024-12-01T09:30:00Z"},
+				})
+			})
+
+			Convey("Then the original date changes are left unchanged", func() {
+				So(dateChanges[0].PreviousDate, ShouldEqual, "now-7d")
+			})
+		})
+	})
+
+	Convey("Given a store with a clock", t, func() {
+		now := time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)
+		store := &ResourceStore{Clock: fixedClock(now)}
+		ctx := context.Background()
+
+		Convey("When a fixture with a relative release date is retrieved", func() {
+			resource, err := store.GetFixture(ctx, "search_content_updated/standard_future_release_date.json")
+			So(err, ShouldBeNil)
+
+			Convey("Then its release date is resolved against the clock", func() {
+				So(resource.(models.SearchContentUpdatedResource).ReleaseDate, ShouldEqual, "2025-01-13T09:30:00Z")
+			})
+		})
+
+		Convey("When a resource is created with a relative release date", func() {
+			So(store.CreateResource(ctx, models.SearchContentUpdatedResource{URI: "/relative", Title: "Relative", ContentType: "bulletin", ReleaseDate: "now+1d"}), ShouldBeNil)
+
+			Convey("Then it is served with the release date resolved against the clock at the time of the request", func() {
+				store.Clock = fixedClock(now.Add(24 * time.Hour))
+				resources, err := store.GetResources(ctx, "search-content-updated", Options{Limit: 1000})
+				So(err, ShouldBeNil)
+
+				created, ok := findResource(resources.Items, "/relative")
+				So(ok, ShouldBeTrue)
+				So(created.ReleaseDate, ShouldEqual, "2025-01-08T09:30:00Z")
+			})
+		})
+	})
+}
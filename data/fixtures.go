@@ -0,0 +1,202 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/validation"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	jsonFilesDir = "json_files"
+	// fixturesFile holds the metadata of any fixture that is intentionally invalid
+	fixturesFile = jsonFilesDir + "/fixtures.json"
+)
+
+// ErrFixtureNotFound is returned when there is no fixture at the requested path
+var ErrFixtureNotFound = errors.New("fixture not found")
+
+// fixtureURIs returns the set of uris of the search-content-updated fixtures. The fixtures are embedded, so they are
+// only read once, rather than every time a resource is changed.
+var fixtureURIs = sync.OnceValues(readFixtureURIs)
+
+// fixture is a resource read from one of the embedded json files
+type fixture struct {
+	// file is the path of the json file relative to the json_files directory
+	file     string
+	resource models.Resource
+}
+
+// FixtureExpectation is the metadata of a fixture declaring which contract rules it is written to
+// violate, each in the form "field:rule". Fixtures without metadata are expected to be valid.
+type FixtureExpectation struct {
+	Description        string   `json:"description"`
+	ExpectedViolations []string `json:"expected_violations"`
+}
+
+// GetFixturesReport validates every fixture against the search contract and reports the
+// violations found alongside the violations its metadata expects
+func (r *ResourceStore) GetFixturesReport(ctx context.Context) (*models.FixturesReport, error) {
+	log.Info(ctx, "getting fixtures report")
+
+	expectations, err := readFixtureExpectations()
+	if err != nil {
+		log.Error(ctx, "failed to read fixture metadata", err)
+		return nil, err
+	}
+
+	report := &models.FixturesReport{
+		Items: []models.FixtureReport{},
+	}
+
+	for _, resourceType := range []string{contentUpdatedResourceType, searchContentUpdatedResourceType, searchContentDeletedResourceType} {
+		fixtures, err := r.readFixtures(ctx, resourceType)
+		if err != nil {
+			log.Error(ctx, "failed to read fixtures", err, log.Data{"type": resourceType})
+			return nil, err
+		}
+
+		for _, f := range fixtures {
+			item := newFixtureReport(f, expectations[f.file])
+			if !item.Matches {
+				report.Mismatches++
+			}
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	report.Count = len(report.Items)
+
+	log.Info(ctx, "retrieved fixtures report", log.Data{
+		"count":      report.Count,
+		"mismatches": report.Mismatches,
+	})
+	return report, nil
+}
+
+// GetFixture returns the resource read from a fixture, given by its path relative to the json_files directory, with
+// any dates relative to the clock resolved
+func (r *ResourceStore) GetFixture(ctx context.Context, file string) (models.Resource, error) {
+	var resourceType string
+
+	switch strings.SplitN(file, "/", 2)[0] {
+	case "content_updated":
+		resourceType = contentUpdatedResourceType
+	case "search_content_updated":
+		resourceType = searchContentUpdatedResourceType
+	case "search_content_deleted":
+		resourceType = searchContentDeletedResourceType
+	default:
+		return nil, ErrFixtureNotFound
+	}
+
+	fixtures, err := r.readFixtures(ctx, resourceType)
+	if err != nil {
+		log.Error(ctx, "failed to read fixtures", err, log.Data{"type": resourceType})
+		return nil, err
+	}
+
+	for _, f := range fixtures {
+		if f.file == file {
+			return f.resource, nil
+		}
+	}
+
+	return nil, ErrFixtureNotFound
+}
+
+func newFixtureReport(f fixture, expectation FixtureExpectation) models.FixtureReport {
+	expected := expectation.ExpectedViolations
+	if expected == nil {
+		expected = []string{}
+	}
+	actual := validation.Validate(f.resource).Keys()
+
+	item := models.FixtureReport{
+		File:               f.file,
+		ResourceType:       f.resource.GetResourceType(),
+		Description:        expectation.Description,
+		ExpectedViolations: expected,
+		ActualViolations:   actual,
+		Matches:            violationsMatch(expected, actual),
+	}
+
+	if r, ok := f.resource.(models.SearchContentUpdatedResource); ok {
+		item.ContentType = r.ContentType
+	}
+
+	return item
+}
+
+// readFixtureExpectations reads the fixture metadata, keyed by file path relative to the json_files directory
+func readFixtureExpectations() (map[string]FixtureExpectation, error) {
+	fileBytes, err := jsonFiles.ReadFile(fixturesFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read fixtures file")
+	}
+
+	var expectations map[string]FixtureExpectation
+	if err := json.Unmarshal(fileBytes, &expectations); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal fixtures file")
+	}
+
+	return expectations, nil
+}
+
+// readFixtureURIs reads the uri of each search-content-updated fixture, without resolving its dates
+func readFixtureURIs() (map[string]bool, error) {
+	dir := jsonFilesDir + "/search_content_updated"
+	dirEntries, err := fs.ReadDir(jsonFiles, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read json_files directory")
+	}
+
+	uris := make(map[string]bool, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			continue
+		}
+
+		fileBytes, err := jsonFiles.ReadFile(dir + "/" + dirEntry.Name())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read file")
+		}
+
+		var resource struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(fileBytes, &resource); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal SearchContentUpdatedResource JSON")
+		}
+		uris[resource.URI] = true
+	}
+
+	return uris, nil
+}
+
+// violationsMatch checks that two lists of violation keys contain the same keys, in any order
+func violationsMatch(expected, actual []string) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	e := append([]string{}, expected...)
+	a := append([]string{}, actual...)
+	sort.Strings(e)
+	sort.Strings(a)
+
+	for i := range e {
+		if e[i] != a[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,88 @@
+package data
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetFixturesReport(t *testing.T) {
+	Convey("Given the embedded fixtures and their metadata", t, func() {
+		store := &ResourceStore{}
+
+		Convey("When the fixtures report is retrieved", func() {
+			report, err := store.GetFixturesReport(context.Background())
+			So(err, ShouldBeNil)
+
+			Convey("Then every fixture is included in the report", func() {
+				So(report.Count, ShouldEqual, len(report.Items))
+				So(report.Count, ShouldBeGreaterThan, 0)
+			})
+
+			Convey("Then every fixture violates the search contract exactly as its metadata expects", func() {
+				for _, item := range report.Items {
+					So(item.ActualViolations, ShouldResemble, item.ExpectedViolations)
+					So(item.Matches, ShouldBeTrue)
+				}
+				So(report.Mismatches, ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given the fixture metadata", t, func() {
+		expectations, err := readFixtureExpectations()
+		So(err, ShouldBeNil)
+
+		Convey("Then every file it describes exists", func() {
+			for file := range expectations {
+				_, err := fs.Stat(jsonFiles, jsonFilesDir+"/"+file)
+				So(err, ShouldBeNil)
+			}
+		})
+	})
+}
+
+func TestGetFixture(t *testing.T) {
+	Convey("Given the embedded fixtures", t, func() {
+		store := &ResourceStore{}
+		ctx := context.Background()
+
+		Convey("When a fixture is retrieved by its path", func() {
+			resource, err := store.GetFixture(ctx, "search_content_deleted/bit_content_delete.json")
+
+			Convey("Then the resource read from it is returned", func() {
+				So(err, ShouldBeNil)
+				deleted, ok := resource.(models.SearchContentDeletedResource)
+				So(ok, ShouldBeTrue)
+				So(deleted.URI, ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When a fixture that does not exist is retrieved", func() {
+			_, missingErr := store.GetFixture(ctx, "search_content_deleted/missing.json")
+			_, unknownDirErr := store.GetFixture(ctx, "fixtures.json")
+
+			Convey("Then a fixture not found error is returned", func() {
+				So(missingErr, ShouldEqual, ErrFixtureNotFound)
+				So(unknownDirErr, ShouldEqual, ErrFixtureNotFound)
+			})
+		})
+	})
+}
+
+func TestViolationsMatch(t *testing.T) {
+	Convey("Given lists of violation keys", t, func() {
+		Convey("Then lists with the same keys in any order match", func() {
+			So(violationsMatch(nil, []string{}), ShouldBeTrue)
+			So(violationsMatch([]string{"uri:required", "title:required"}, []string{"title:required", "uri:required"}), ShouldBeTrue)
+		})
+
+		Convey("Then lists with different keys do not match", func() {
+			So(violationsMatch([]string{"uri:required"}, []string{}), ShouldBeFalse)
+			So(violationsMatch([]string{"uri:required"}, []string{"title:required"}), ShouldBeFalse)
+		})
+	})
+}
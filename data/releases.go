@@ -0,0 +1,30 @@
+package data
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// GetReleases retrieves every search-content-updated resource with the release content type, as it is currently
+// served with any changes, relative dates and its lifecycle state applied. The release scheduler calls it at every
+// check, so unlike serving resources it neither traces nor counts the reading of the fixtures.
+func (r *ResourceStore) GetReleases(ctx context.Context) ([]models.SearchContentUpdatedResource, error) {
+	fixtures, err := r.parseFixtures(searchContentUpdatedResourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.fixtureItems(ctx, fixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []models.SearchContentUpdatedResource
+	for _, item := range r.applyCurrent(r.applyChanges(searchContentUpdatedResourceType, items)) {
+		if resource := item.(models.SearchContentUpdatedResource); resource.ContentType == models.ReleaseContentType {
+			releases = append(releases, resource)
+		}
+	}
+	return releases, nil
+}
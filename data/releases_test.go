@@ -0,0 +1,85 @@
+package data
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/metrics"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// publishedLifecycle moves every release to the published state
+type publishedLifecycle struct{}
+
+func (publishedLifecycle) Apply(resource models.SearchContentUpdatedResource) models.SearchContentUpdatedResource {
+	if resource.ContentType == models.ReleaseContentType {
+		resource.Cancelled, resource.Finalised, resource.Published = false, true, true
+	}
+	return resource
+}
+
+func TestGetReleases(t *testing.T) {
+	Convey("Given a store with a release lifecycle", t, func() {
+		emitter := &recordingEmitter{}
+		store := &ResourceStore{Emitter: emitter, Lifecycle: publishedLifecycle{}}
+		ctx := context.Background()
+
+		Convey("When the releases are retrieved", func() {
+			releases, err := store.GetReleases(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then only releases are returned, with the lifecycle applied", func() {
+				So(releases, ShouldNotBeEmpty)
+				for _, release := range releases {
+					So(release.ContentType, ShouldEqual, models.ReleaseContentType)
+					So(release.Published, ShouldBeTrue)
+				}
+			})
+		})
+
+		Convey("When the resources are retrieved", func() {
+			resources, err := store.GetResources(ctx, "search-content-updated", Options{Limit: 1000})
+			So(err, ShouldBeNil)
+
+			Convey("Then the lifecycle is applied to the releases", func() {
+				for _, item := range resources.Items {
+					if resource := item.(models.SearchContentUpdatedResource); resource.ContentType == models.ReleaseContentType {
+						So(resource.Published, ShouldBeTrue)
+						So(resource.Cancelled, ShouldBeFalse)
+					}
+				}
+			})
+		})
+
+		Convey("When a release is created", func() {
+			So(store.CreateResource(ctx, models.SearchContentUpdatedResource{URI: "/releases/new", Title: "New release", ContentType: models.ReleaseContentType}), ShouldBeNil)
+
+			Convey("Then its event carries the lifecycle state it is served with", func() {
+				So(emitter.emitted, ShouldHaveLength, 1)
+				So(emitter.emitted[0].(models.SearchContentUpdatedResource).Published, ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestGetReleasesFixtureReloads(t *testing.T) {
+	Convey("Given a store with metrics", t, func() {
+		store := &ResourceStore{Metrics: metrics.New(), Lifecycle: publishedLifecycle{}}
+		ctx := context.Background()
+
+		Convey("When the releases are retrieved, as the release scheduler does at every check", func() {
+			_, err := store.GetReleases(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then the reading of the fixtures is not counted as a reload", func() {
+				resp := httptest.NewRecorder()
+				store.Metrics.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+				So(resp.Body.String(), ShouldNotContainSubstring, "fixture_reloads_total{")
+			})
+		})
+	})
+}
@@ -1,8 +1,41 @@
 package data
 
+import (
+	"context"
+	"sync"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
+	"github.com/ONSdigital/dis-search-upstream-stub/metrics"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
 // ResourceStore is a type that contains an implementation of the DataStorer interface, which can be used for
 // getting Resources.
 type ResourceStore struct {
+	// RejectInvalidFixtures leaves fixtures that do not satisfy the search contract out of the store
+	RejectInvalidFixtures bool
+	// Emitter, if set, is given every resource that is created, updated or deleted through the store
+	Emitter EventEmitter
+	// Clock is the time that dates given relative to the clock, such as now+7d, are resolved against. The real time
+	// is used if it is not set.
+	Clock clock.Clock
+	// Lifecycle, if set, moves releases through their lifecycle states as time passes
+	Lifecycle ReleaseLifecycle
+	// Metrics, if set, counts the fixtures read and the resources served
+	Metrics *metrics.Metrics
+
+	mutex   sync.RWMutex
+	changes changes
+}
+
+// EventEmitter emits the event corresponding to a change to a resource
+type EventEmitter interface {
+	Emit(ctx context.Context, resource models.Resource)
+}
+
+// ReleaseLifecycle sets the state of a release at the current time
+type ReleaseLifecycle interface {
+	Apply(resource models.SearchContentUpdatedResource) models.SearchContentUpdatedResource
 }
 
 // Options contains information for pagination which includes offset and limit
@@ -9,11 +9,19 @@ import (
 	"encoding/json"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/validation"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-//go:embed json_files/search_content_updated/*.json json_files/search_content_deleted/*.json json_files/content_updated/*.json
+// tracerName names the tracer of the spans started by the data store
+const tracerName = "github.com/ONSdigital/dis-search-upstream-stub/data"
+
+//go:embed json_files/search_content_updated/*.json json_files/search_content_deleted/*.json json_files/content_updated/*.json json_files/fixtures.json
 var jsonFiles embed.FS
 
 var searchContentUpdatedResourceType = "SearchContentUpdatedResource"
@@ -41,11 +49,20 @@ func (r *ResourceStore) GetResources(ctx context.Context, typeParam string, opti
 
 // GetResourcesWithType retrieves all the resources from the collection
 func (r *ResourceStore) GetResourcesWithType(ctx context.Context, resourceType string, options Options) (*models.Resources, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "GetResourcesWithType", trace.WithAttributes(
+		attribute.String("resource.type", resourceType),
+		attribute.Int("pagination.limit", options.Limit),
+		attribute.Int("pagination.offset", options.Offset),
+	))
+	defer span.End()
+
 	logData := log.Data{"options": options}
 	log.Info(ctx, "getting list of resources", logData)
 
-	items, err := populateItems(resourceType)
+	items, err := r.populateItems(ctx, resourceType)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		logData["items"] = items
 		logData["count"] = len(items)
 		logData["type"] = resourceType
@@ -53,7 +70,13 @@ func (r *ResourceStore) GetResourcesWithType(ctx context.Context, resourceType s
 		return nil, err
 	}
 
+	items = r.applyCurrent(r.applyChanges(resourceType, items))
 	filteredItems := filterItems(items, options)
+	r.Metrics.ResourcesServed(resourceType, len(filteredItems))
+	span.SetAttributes(
+		attribute.Int("pagination.count", len(filteredItems)),
+		attribute.Int("pagination.total_count", len(items)),
+	)
 
 	resources := &models.Resources{
 		Count:      len(filteredItems),
@@ -68,29 +91,94 @@ func (r *ResourceStore) GetResourcesWithType(ctx context.Context, resourceType s
 	return resources, nil
 }
 
-// populateItems retrieves items from the content_updated and search_content_updated directories
-func populateItems(resourceType string) ([]models.Resource, error) {
+// populateItems retrieves items from the content_updated and search_content_updated directories, with any dates
+// relative to the clock resolved at the current time. Each item is validated against the search contract, and any
+// item that does not violate the contract in the way its fixture metadata expects is logged. Invalid items are left
+// out of the returned list if RejectInvalidFixtures is set.
+func (r *ResourceStore) populateItems(ctx context.Context, resourceType string) ([]models.Resource, error) {
+	fixtures, err := r.readFixtures(ctx, resourceType)
+	if err != nil {
+		return nil, err
+	}
+	return r.fixtureItems(ctx, fixtures)
+}
+
+// fixtureItems returns the resources of the fixtures as populateItems does, validating each against the search contract
+func (r *ResourceStore) fixtureItems(ctx context.Context, fixtures []fixture) ([]models.Resource, error) {
+	expectations, err := readFixtureExpectations()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.Resource, 0, len(fixtures))
+
+	for _, f := range fixtures {
+		violations := validation.Validate(f.resource)
+		expected := expectations[f.file]
+
+		if !violationsMatch(expected.ExpectedViolations, violations.Keys()) {
+			log.Warn(ctx, "fixture does not violate the search contract as expected", log.Data{
+				"file":                f.file,
+				"expected_violations": expected.ExpectedViolations,
+				"actual_violations":   violations.Keys(),
+			})
+		}
+
+		if r.RejectInvalidFixtures && len(violations) > 0 {
+			log.Info(ctx, "rejecting fixture that does not satisfy the search contract", log.Data{"file": f.file})
+			continue
+		}
+
+		items = append(items, f.resource)
+	}
+
+	return items, nil
+}
+
+// readFixtures reads and unmarshals every json file in the directory for the given resource type, resolving any dates
+// relative to the clock at the current time
+func (r *ResourceStore) readFixtures(ctx context.Context, resourceType string) ([]fixture, error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, "parse fixtures", trace.WithAttributes(
+		attribute.String("resource.type", resourceType),
+	))
+	defer span.End()
+
+	fixtures, err := r.parseFixtures(resourceType)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("fixtures.count", len(fixtures)))
+
+	r.Metrics.FixturesReloaded(resourceType)
+	return fixtures, nil
+}
+
+// parseFixtures unmarshals the json files of the given resource type, as read by readFixtures
+func (r *ResourceStore) parseFixtures(resourceType string) ([]fixture, error) {
 	var dir string
 
 	// Determine which directory to read from based on the resource type
 	switch resourceType {
 	case contentUpdatedResourceType:
-		dir = "json_files/content_updated"
+		dir = "content_updated"
 	case searchContentUpdatedResourceType:
-		dir = "json_files/search_content_updated"
+		dir = "search_content_updated"
 	case searchContentDeletedResourceType:
-		dir = "json_files/search_content_deleted"
+		dir = "search_content_deleted"
 	default:
 		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
 	}
 
 	// Read files from the appropriate directory
-	dirEntries, err := fs.ReadDir(jsonFiles, dir)
+	dirEntries, err := fs.ReadDir(jsonFiles, jsonFilesDir+"/"+dir)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read json_files directory")
 	}
 
-	items := make([]models.Resource, 0, len(dirEntries))
+	now := r.now()
+	fixtures := make([]fixture, 0, len(dirEntries))
 
 	// Loop through files, read, and unmarshal each JSON file into Go structs.
 	for _, dirEntry := range dirEntries {
@@ -99,7 +187,8 @@ func populateItems(resourceType string) ([]models.Resource, error) {
 		}
 
 		// Read the content of each file
-		fileBytes, err := jsonFiles.ReadFile(dir + "/" + dirEntry.Name())
+		file := dir + "/" + dirEntry.Name()
+		fileBytes, err := jsonFiles.ReadFile(jsonFilesDir + "/" + file)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to read file")
 		}
@@ -121,7 +210,7 @@ func populateItems(resourceType string) ([]models.Resource, error) {
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to unmarshal SearchContentUpdatedResource JSON")
 			}
-			resource = searchContentUpdated
+			resource = resolveDates(searchContentUpdated, now)
 		case searchContentDeletedResourceType:
 			var searchContentDeleted models.SearchContentDeletedResource
 			err = json.Unmarshal(fileBytes, &searchContentDeleted)
@@ -131,15 +220,19 @@ func populateItems(resourceType string) ([]models.Resource, error) {
 			resource = searchContentDeleted
 		}
 
-		items = append(items, resource)
+		fixtures = append(fixtures, fixture{file: file, resource: resource})
 	}
 
-	return items, nil
+	return fixtures, nil
 }
 
 // filterItems filters a list of resources by limit and offset, capping the maximum value
 // at the returned items length
 func filterItems(items []models.Resource, options Options) []models.Resource {
+	if options.Offset >= len(items) {
+		return []models.Resource{}
+	}
+
 	var maxItem int
 	maxRequested := options.Offset + options.Limit
 
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/oteltest"
+)
+
+func TestFilterItems(t *testing.T) {
+	Convey("Given a list of three resources", t, func() {
+		items := []models.Resource{
+			models.SearchContentDeletedResource{URI: "/a"},
+			models.SearchContentDeletedResource{URI: "/b"},
+			models.SearchContentDeletedResource{URI: "/c"},
+		}
+
+		Convey("When the list is filtered by an offset and limit within the list", func() {
+			filtered := filterItems(items, Options{Offset: 1, Limit: 1})
+
+			Convey("Then the requested resources are returned", func() {
+				So(filtered, ShouldResemble, items[1:2])
+			})
+		})
+
+		Convey("When the list is filtered by a limit past the end of the list", func() {
+			filtered := filterItems(items, Options{Offset: 1, Limit: 10})
+
+			Convey("Then the remaining resources are returned", func() {
+				So(filtered, ShouldResemble, items[1:])
+			})
+		})
+
+		Convey("When the list is filtered by an offset past the end of the list", func() {
+			filtered := filterItems(items, Options{Offset: 10, Limit: 10})
+
+			Convey("Then an empty list is returned", func() {
+				So(filtered, ShouldNotBeNil)
+				So(filtered, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestGetResourcesWithTypeSpans(t *testing.T) {
+	Convey("Given OpenTelemetry is set up with an in-memory exporter", t, func() {
+		exporter, restore := oteltest.Setup()
+		Reset(restore)
+
+		store := &ResourceStore{}
+
+		Convey("When a page of resources of a type is retrieved", func() {
+			resources, err := store.GetResourcesWithType(context.Background(), searchContentDeletedResourceType, Options{Offset: 1, Limit: 2})
+			So(err, ShouldBeNil)
+
+			Convey("Then the fixtures are parsed within a span for the retrieval, with the type and pagination", func() {
+				spans := exporter.GetSpans()
+				So(spans, ShouldHaveLength, 2)
+				parse, get := spans[0], spans[1]
+
+				So(get.Name, ShouldEqual, "GetResourcesWithType")
+				attributes := oteltest.Attributes(get)
+				So(attributes["resource.type"].AsString(), ShouldEqual, searchContentDeletedResourceType)
+				So(attributes["pagination.limit"].AsInt64(), ShouldEqual, 2)
+				So(attributes["pagination.offset"].AsInt64(), ShouldEqual, 1)
+				So(attributes["pagination.count"].AsInt64(), ShouldEqual, resources.Count)
+				So(attributes["pagination.total_count"].AsInt64(), ShouldEqual, resources.TotalCount)
+
+				So(parse.Name, ShouldEqual, "parse fixtures")
+				So(parse.Parent.SpanID(), ShouldEqual, get.SpanContext.SpanID())
+				So(oteltest.Attributes(parse)["resource.type"].AsString(), ShouldEqual, searchContentDeletedResourceType)
+				So(oteltest.Attributes(parse)["fixtures.count"].AsInt64(), ShouldEqual, resources.TotalCount)
+			})
+		})
+
+		Convey("When resources of an unknown type are retrieved", func() {
+			_, err := store.GetResourcesWithType(context.Background(), "UnknownResource", Options{Limit: 10})
+			So(err, ShouldNotBeNil)
+
+			Convey("Then the spans record the error", func() {
+				spans := exporter.GetSpans()
+				So(spans, ShouldHaveLength, 2)
+				for _, span := range spans {
+					So(span.Status.Code, ShouldEqual, codes.Error)
+					So(span.Events, ShouldNotBeEmpty)
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Emitter publishes the event for each resource changed in the store to the resource's topic,
+// after an optional delay to mimic the latency of a real upstream service
+type Emitter struct {
+	Publisher *Publisher
+	Delay     time.Duration
+
+	mutex  sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+	done   chan struct{}
+}
+
+// NewEmitter creates an Emitter that publishes events with the publisher after the delay
+func NewEmitter(publisher *Publisher, delay time.Duration) *Emitter {
+	return &Emitter{
+		Publisher: publisher,
+		Delay:     delay,
+		done:      make(chan struct{}),
+	}
+}
+
+// Emit publishes the event for the resource, immediately if there is no delay, or otherwise in the
+// background once the delay has passed. Failures are logged, as the change has already been made.
+// Once the emitter is closing, events are dropped.
+func (e *Emitter) Emit(ctx context.Context, resource models.Resource) {
+	topic, err := Topic(e.Publisher.Encoder.Kafka, resource)
+	if err != nil {
+		log.Error(ctx, "cannot emit event for resource", err)
+		return
+	}
+
+	if !e.start() {
+		log.Warn(ctx, "emitter closed before event was emitted", log.Data{"topic": topic})
+		return
+	}
+
+	if e.Delay <= 0 {
+		defer e.wg.Done()
+		e.publish(ctx, topic, resource)
+		return
+	}
+
+	// the request that changed the resource will have finished by the time the event is published
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		defer e.wg.Done()
+
+		timer := time.NewTimer(e.Delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			e.publish(ctx, topic, resource)
+		case <-e.done:
+			log.Warn(ctx, "emitter closed before delayed event was published", log.Data{"topic": topic})
+		}
+	}()
+}
+
+// start counts an event being emitted, so that Close waits for it, unless the emitter is closing
+func (e *Emitter) start() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.closed {
+		return false
+	}
+	e.wg.Add(1)
+	return true
+}
+
+func (e *Emitter) publish(ctx context.Context, topic string, resource models.Resource) {
+	if _, err := e.Publisher.Publish(ctx, topic, resource); err != nil {
+		log.Error(ctx, "failed to emit event", err, log.Data{"topic": topic})
+	}
+}
+
+// Close drops any events still waiting for their delay to pass, and any emitted from then on, and
+// waits for events being published to be handed to their producers
+func (e *Emitter) Close() {
+	e.mutex.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.done)
+	}
+	e.mutex.Unlock()
+
+	e.wg.Wait()
+}
@@ -0,0 +1,95 @@
+package events_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+)
+
+func TestEmit(t *testing.T) {
+	Convey("Given an emitter without a delay", t, func() {
+		publisher, producers := newTestPublisher(nil)
+		emitter := events.NewEmitter(publisher, 0)
+
+		Convey("When a resource is emitted", func() {
+			emitter.Emit(context.Background(), searchContentDeleted)
+
+			Convey("Then it is published to its topic straight away", func() {
+				So(producers["search-content-deleted"].SendBytesCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+
+	Convey("Given an emitter with a delay", t, func() {
+		publisher, producers := newTestPublisher(nil)
+		emitter := events.NewEmitter(publisher, 50*time.Millisecond)
+
+		Convey("When a resource is emitted", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			emitter.Emit(ctx, searchContentDeleted)
+			cancel()
+
+			Convey("Then it is not published until the delay has passed, even if the request has finished", func() {
+				So(producers["search-content-deleted"].SendBytesCalls(), ShouldBeEmpty)
+
+				time.Sleep(100 * time.Millisecond)
+				emitter.Close()
+				So(producers["search-content-deleted"].SendBytesCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+
+	Convey("Given an emitter with a long delay", t, func() {
+		publisher, producers := newTestPublisher(nil)
+		emitter := events.NewEmitter(publisher, time.Hour)
+
+		Convey("When a resource is emitted and the emitter is closed", func() {
+			emitter.Emit(context.Background(), searchContentDeleted)
+			emitter.Close()
+
+			Convey("Then the pending event is dropped", func() {
+				So(producers["search-content-deleted"].SendBytesCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+	Convey("Given a closed emitter", t, func() {
+		publisher, producers := newTestPublisher(nil)
+		emitter := events.NewEmitter(publisher, 0)
+		emitter.Close()
+
+		Convey("When a resource is emitted", func() {
+			emitter.Emit(context.Background(), searchContentDeleted)
+
+			Convey("Then it is dropped rather than sent to a closed producer", func() {
+				So(producers["search-content-deleted"].SendBytesCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given an emitter with a delay", t, func() {
+		publisher, _ := newTestPublisher(nil)
+		emitter := events.NewEmitter(publisher, time.Millisecond)
+
+		Convey("When resources are emitted while the emitter is closed", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					emitter.Emit(context.Background(), searchContentDeleted)
+				}()
+			}
+			emitter.Close()
+			wg.Wait()
+
+			Convey("Then the emitter closes, and can be closed again", func() {
+				So(emitter.Close, ShouldNotPanic)
+			})
+		})
+	})
+}
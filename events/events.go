@@ -0,0 +1,91 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+)
+
+// A list of the encodings that events can be marshalled with
+const (
+	EncodingAvro = "avro"
+	EncodingJSON = "json"
+)
+
+// A list of the event types produced for each type of resource
+const (
+	ContentPublishedEvent     = "ContentPublishedEvent"
+	SearchContentUpdatedEvent = "SearchContentUpdatedEvent"
+	SearchContentDeletedEvent = "SearchContentDeletedEvent"
+)
+
+// EventType returns the type of event that is produced for the resource
+func EventType(resource models.Resource) (string, error) {
+	switch resource.(type) {
+	case models.ContentUpdatedResource:
+		return ContentPublishedEvent, nil
+	case models.SearchContentUpdatedResource:
+		return SearchContentUpdatedEvent, nil
+	case models.SearchContentDeletedResource:
+		return SearchContentDeletedEvent, nil
+	default:
+		return "", fmt.Errorf("unsupported resource type: %T", resource)
+	}
+}
+
+// Schema returns the Avro schema of the event that is produced for the resource
+func Schema(resource models.Resource) (*avro.Schema, error) {
+	switch resource.(type) {
+	case models.ContentUpdatedResource:
+		return schema.ContentPublishedEvent, nil
+	case models.SearchContentUpdatedResource:
+		return schema.SearchContentUpdateEvent, nil
+	case models.SearchContentDeletedResource:
+		return schema.SearchContentDeletedEvent, nil
+	default:
+		return nil, fmt.Errorf("unsupported resource type: %T", resource)
+	}
+}
+
+// Marshal marshals the resource into an event payload with the given encoding
+func Marshal(resource models.Resource, encoding string) ([]byte, error) {
+	switch encoding {
+	case EncodingAvro:
+		s, err := Schema(resource)
+		if err != nil {
+			return nil, err
+		}
+		return s.Marshal(resource)
+	case EncodingJSON:
+		if _, err := EventType(resource); err != nil {
+			return nil, err
+		}
+		return json.Marshal(resource)
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %q", encoding)
+	}
+}
+
+// TopicEncoding returns the encoding configured for events sent to the topic, defaulting to JSON
+// for any topic without an encoding
+func TopicEncoding(kcfg *config.Kafka, topic string) string {
+	var encoding string
+
+	switch topic {
+	case kcfg.ContentUpdatedTopic:
+		encoding = kcfg.ContentUpdatedEncoding
+	case kcfg.SearchContentUpdatedTopic:
+		encoding = kcfg.SearchContentUpdatedEncoding
+	case kcfg.SearchContentDeletedTopic:
+		encoding = kcfg.SearchContentDeletedEncoding
+	}
+
+	if encoding == "" {
+		return EncodingJSON
+	}
+	return encoding
+}
@@ -0,0 +1,123 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+)
+
+var (
+	contentUpdated = models.ContentUpdatedResource{
+		URI:          "/economy",
+		DataType:     "legacy",
+		CollectionID: "COLLECTIONID",
+		TraceID:      "1234",
+	}
+	searchContentUpdated = models.SearchContentUpdatedResource{
+		URI:         "/releases/a-release",
+		ContentType: models.ReleaseContentType,
+		Title:       "A Release",
+		Topics:      []string{"4972"},
+		DateChanges: []models.ReleaseDateDetails{
+			{ChangeNotice: "a change notice", PreviousDate: "2024-10-01T07:00:00Z"},
+		},
+		Finalised: true,
+	}
+	searchContentDeleted = models.SearchContentDeletedResource{
+		URI:          "/economy",
+		CollectionID: "COLLECTIONID",
+		SearchIndex:  "ons",
+		TraceID:      "1234",
+	}
+)
+
+func TestMarshalAvro(t *testing.T) {
+	Convey("Given a resource of each type", t, func() {
+		Convey("When a content-updated resource is marshalled as Avro", func() {
+			payload, err := events.Marshal(contentUpdated, events.EncodingAvro)
+			So(err, ShouldBeNil)
+
+			Convey("Then it can be unmarshalled with the ContentPublishedEvent schema", func() {
+				var got models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(payload, &got), ShouldBeNil)
+				So(got, ShouldResemble, contentUpdated)
+			})
+		})
+
+		Convey("When a search-content-updated resource is marshalled as Avro", func() {
+			payload, err := events.Marshal(searchContentUpdated, events.EncodingAvro)
+			So(err, ShouldBeNil)
+
+			Convey("Then it can be unmarshalled with the SearchContentUpdateEvent schema", func() {
+				var got models.SearchContentUpdatedResource
+				So(schema.SearchContentUpdateEvent.Unmarshal(payload, &got), ShouldBeNil)
+				So(got.URI, ShouldEqual, searchContentUpdated.URI)
+				So(got.DateChanges, ShouldResemble, searchContentUpdated.DateChanges)
+				So(got.Finalised, ShouldBeTrue)
+			})
+		})
+
+		Convey("When a search-content-deleted resource is marshalled as Avro", func() {
+			payload, err := events.Marshal(searchContentDeleted, events.EncodingAvro)
+			So(err, ShouldBeNil)
+
+			Convey("Then it can be unmarshalled with the SearchContentDeletedEvent schema", func() {
+				var got models.SearchContentDeletedResource
+				So(schema.SearchContentDeletedEvent.Unmarshal(payload, &got), ShouldBeNil)
+				So(got, ShouldResemble, searchContentDeleted)
+			})
+		})
+	})
+}
+
+func TestMarshalJSON(t *testing.T) {
+	Convey("Given a search-content-deleted resource", t, func() {
+		Convey("When it is marshalled as JSON", func() {
+			payload, err := events.Marshal(searchContentDeleted, events.EncodingJSON)
+			So(err, ShouldBeNil)
+
+			Convey("Then it can be unmarshalled back into the resource", func() {
+				var got models.SearchContentDeletedResource
+				So(json.Unmarshal(payload, &got), ShouldBeNil)
+				So(got, ShouldResemble, searchContentDeleted)
+			})
+		})
+
+		Convey("When it is marshalled with an unknown encoding", func() {
+			_, err := events.Marshal(searchContentDeleted, "xml")
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, `unsupported encoding: "xml"`)
+			})
+		})
+	})
+}
+
+func TestTopicEncoding(t *testing.T) {
+	Convey("Given a Kafka config with an encoding for each topic", t, func() {
+		kcfg := &config.Kafka{
+			ContentUpdatedTopic:          "content-updated",
+			SearchContentUpdatedTopic:    "search-content-updated",
+			SearchContentDeletedTopic:    "search-content-deleted",
+			ContentUpdatedEncoding:       events.EncodingAvro,
+			SearchContentUpdatedEncoding: events.EncodingAvro,
+		}
+
+		Convey("Then the encoding of each topic is returned", func() {
+			So(events.TopicEncoding(kcfg, "content-updated"), ShouldEqual, events.EncodingAvro)
+			So(events.TopicEncoding(kcfg, "search-content-updated"), ShouldEqual, events.EncodingAvro)
+		})
+
+		Convey("Then JSON is returned for a topic with no encoding", func() {
+			So(events.TopicEncoding(kcfg, "search-content-deleted"), ShouldEqual, events.EncodingJSON)
+			So(events.TopicEncoding(kcfg, "another-topic"), ShouldEqual, events.EncodingJSON)
+		})
+	})
+}
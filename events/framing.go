@@ -0,0 +1,77 @@
+package events
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/registry"
+)
+
+// magicByte is the first byte of every message in the Confluent wire format, followed by the
+// 4 byte big-endian ID of the schema the payload is encoded with
+const (
+	magicByte    byte = 0
+	headerLength      = 5
+)
+
+// ErrNotFramed is returned when a message is not in the Confluent wire format
+var ErrNotFramed = errors.New("message is not framed with a schema ID")
+
+// Frame prefixes the payload with the magic byte and schema ID of the Confluent wire format
+func Frame(schemaID int, payload []byte) []byte {
+	message := make([]byte, headerLength, headerLength+len(payload))
+	message[0] = magicByte
+	binary.BigEndian.PutUint32(message[1:headerLength], uint32(schemaID))
+	return append(message, payload...)
+}
+
+// Unframe splits a message in the Confluent wire format into its schema ID and payload
+func Unframe(message []byte) (schemaID int, payload []byte, err error) {
+	if len(message) < headerLength || message[0] != magicByte {
+		return 0, nil, ErrNotFramed
+	}
+	return int(binary.BigEndian.Uint32(message[1:headerLength])), message[headerLength:], nil
+}
+
+// Encoder marshals resources into the payloads of messages for a topic, using the encoding
+// configured for the topic. Avro payloads are framed with the ID of the topic's latest schema in
+// the registry if schema registry framing is enabled.
+type Encoder struct {
+	Kafka    *config.Kafka
+	Registry *registry.Registry
+}
+
+// NewEncoder creates an Encoder with a registry of the schemas for the topics in the Kafka config
+func NewEncoder(kcfg *config.Kafka) (*Encoder, error) {
+	r, err := registry.New(kcfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{Kafka: kcfg, Registry: r}, nil
+}
+
+// Framed reports whether payloads for the topic are framed with a schema ID
+func (e *Encoder) Framed(topic string) bool {
+	return e.Kafka.SchemaRegistryFraming && TopicEncoding(e.Kafka, topic) == EncodingAvro
+}
+
+// Encode marshals the resource into the payload of a message for the topic
+func (e *Encoder) Encode(topic string, resource models.Resource) ([]byte, error) {
+	payload, err := Marshal(resource, TopicEncoding(e.Kafka, topic))
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.Framed(topic) {
+		return payload, nil
+	}
+
+	schemaID, err := e.Registry.LatestID(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema ID for topic %q: %w", topic, err)
+	}
+	return Frame(schemaID, payload), nil
+}
@@ -0,0 +1,98 @@
+package events_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+)
+
+func TestFrame(t *testing.T) {
+	Convey("Given a payload", t, func() {
+		payload := []byte("payload")
+
+		Convey("When it is framed with a schema ID", func() {
+			message := events.Frame(258, payload)
+
+			Convey("Then it is prefixed with the magic byte and the big-endian schema ID", func() {
+				So(message[:5], ShouldResemble, []byte{0, 0, 0, 1, 2})
+				So(message[5:], ShouldResemble, payload)
+			})
+
+			Convey("Then it can be unframed", func() {
+				id, got, err := events.Unframe(message)
+				So(err, ShouldBeNil)
+				So(id, ShouldEqual, 258)
+				So(got, ShouldResemble, payload)
+			})
+		})
+
+		Convey("When a message that is not framed is unframed", func() {
+			_, _, err := events.Unframe(payload)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldEqual, events.ErrNotFramed)
+			})
+		})
+	})
+}
+
+func TestEncoder(t *testing.T) {
+	Convey("Given an encoder for Avro content-updated and JSON search-content-deleted topics", t, func() {
+		kcfg := &config.Kafka{
+			ContentUpdatedTopic:          "content-updated",
+			SearchContentUpdatedTopic:    "search-content-updated",
+			SearchContentDeletedTopic:    "search-content-deleted",
+			ContentUpdatedEncoding:       events.EncodingAvro,
+			SearchContentDeletedEncoding: events.EncodingJSON,
+		}
+		encoder, err := events.NewEncoder(kcfg)
+		So(err, ShouldBeNil)
+
+		Convey("When schema registry framing is disabled", func() {
+			payload, err := encoder.Encode("content-updated", contentUpdated)
+			So(err, ShouldBeNil)
+
+			Convey("Then Avro payloads are not framed", func() {
+				So(encoder.Framed("content-updated"), ShouldBeFalse)
+
+				var got models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(payload, &got), ShouldBeNil)
+				So(got, ShouldResemble, contentUpdated)
+			})
+		})
+
+		Convey("When schema registry framing is enabled", func() {
+			kcfg.SchemaRegistryFraming = true
+
+			Convey("Then Avro payloads are framed with the ID of the topic's latest schema", func() {
+				So(encoder.Framed("content-updated"), ShouldBeTrue)
+
+				message, err := encoder.Encode("content-updated", contentUpdated)
+				So(err, ShouldBeNil)
+
+				id, payload, err := events.Unframe(message)
+				So(err, ShouldBeNil)
+				latestID, err := encoder.Registry.LatestID("content-updated")
+				So(err, ShouldBeNil)
+				So(id, ShouldEqual, latestID)
+
+				var got models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(payload, &got), ShouldBeNil)
+				So(got, ShouldResemble, contentUpdated)
+			})
+
+			Convey("Then JSON payloads are not framed", func() {
+				So(encoder.Framed("search-content-deleted"), ShouldBeFalse)
+
+				payload, err := encoder.Encode("search-content-deleted", searchContentDeleted)
+				So(err, ShouldBeNil)
+				So(payload[0], ShouldEqual, '{')
+			})
+		})
+	})
+}
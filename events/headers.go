@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/registry"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// A list of the headers describing the events produced by the stub and the kafka tools
+const (
+	HeaderContentType   = "content-type"
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+	HeaderTraceParent   = "traceparent"
+)
+
+// A list of the content types of event payloads in each encoding
+const (
+	ContentTypeAvro = "avro/binary"
+	ContentTypeJSON = "application/json"
+)
+
+// TopicEventType returns the type of event that is produced to the topic
+func TopicEventType(kcfg *config.Kafka, topic string) (string, error) {
+	switch topic {
+	case kcfg.ContentUpdatedTopic:
+		return ContentPublishedEvent, nil
+	case kcfg.SearchContentUpdatedTopic:
+		return SearchContentUpdatedEvent, nil
+	case kcfg.SearchContentDeletedTopic:
+		return SearchContentDeletedEvent, nil
+	default:
+		return "", ErrUnknownTopic
+	}
+}
+
+// Headers returns the headers describing the events produced to the topic, which are the same for every event. The
+// schema version is the version of the topic's latest schema in the registry, whatever the encoding.
+func (e *Encoder) Headers(topic string) (map[string]string, error) {
+	eventType, err := TopicEventType(e.Kafka, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := e.Registry.Latest(registry.Subject(topic))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema version for topic %q: %w", topic, err)
+	}
+
+	contentType := ContentTypeJSON
+	if TopicEncoding(e.Kafka, topic) == EncodingAvro {
+		contentType = ContentTypeAvro
+	}
+
+	return map[string]string{
+		HeaderContentType:   contentType,
+		HeaderEventType:     eventType,
+		HeaderSchemaVersion: fmt.Sprint(latest.Version),
+	}, nil
+}
+
+// TraceParent returns a W3C traceparent header value for the span in the context, or for a new sampled trace if the
+// context has no span
+func TraceParent(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		var traceID trace.TraceID
+		var spanID trace.SpanID
+		_, _ = rand.Read(traceID[:])
+		_, _ = rand.Read(spanID[:])
+		sc = trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		})
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags())
+}
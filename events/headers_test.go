@@ -0,0 +1,92 @@
+package events_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/registry"
+)
+
+var traceParentPattern = regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`)
+
+func TestHeaders(t *testing.T) {
+	Convey("Given an encoder for an Avro content-updated and a JSON search-content-deleted topic", t, func() {
+		kcfg := &config.Kafka{
+			ContentUpdatedTopic:          "content-updated",
+			SearchContentUpdatedTopic:    "search-content-updated",
+			SearchContentDeletedTopic:    "search-content-deleted",
+			ContentUpdatedEncoding:       events.EncodingAvro,
+			SearchContentDeletedEncoding: events.EncodingJSON,
+		}
+		encoder, err := events.NewEncoder(kcfg)
+		So(err, ShouldBeNil)
+
+		Convey("When the headers of the content-updated topic are requested", func() {
+			headers, err := encoder.Headers("content-updated")
+			So(err, ShouldBeNil)
+
+			Convey("Then they describe Avro content published events of the latest schema version", func() {
+				latest, err := encoder.Registry.Latest(registry.Subject("content-updated"))
+				So(err, ShouldBeNil)
+
+				So(headers, ShouldResemble, map[string]string{
+					events.HeaderContentType:   events.ContentTypeAvro,
+					events.HeaderEventType:     events.ContentPublishedEvent,
+					events.HeaderSchemaVersion: fmt.Sprint(latest.Version),
+				})
+			})
+		})
+
+		Convey("When the headers of the search-content-deleted topic are requested", func() {
+			headers, err := encoder.Headers("search-content-deleted")
+			So(err, ShouldBeNil)
+
+			Convey("Then they describe JSON search content deleted events", func() {
+				So(headers[events.HeaderContentType], ShouldEqual, events.ContentTypeJSON)
+				So(headers[events.HeaderEventType], ShouldEqual, events.SearchContentDeletedEvent)
+			})
+		})
+
+		Convey("When the headers of an unknown topic are requested", func() {
+			headers, err := encoder.Headers("unknown")
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldEqual, events.ErrUnknownTopic)
+				So(headers, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestTraceParent(t *testing.T) {
+	Convey("Given a context with a span", t, func() {
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			TraceFlags: trace.FlagsSampled,
+		})
+		ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+		Convey("Then the traceparent identifies the span", func() {
+			So(events.TraceParent(ctx), ShouldEqual, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		})
+	})
+
+	Convey("Given a context without a span", t, func() {
+		ctx := context.Background()
+
+		Convey("Then each traceparent identifies a new sampled trace", func() {
+			first, second := events.TraceParent(ctx), events.TraceParent(ctx)
+			So(traceParentPattern.MatchString(first), ShouldBeTrue)
+			So(traceParentPattern.MatchString(second), ShouldBeTrue)
+			So(first, ShouldNotEqual, second)
+		})
+	})
+}
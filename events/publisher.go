@@ -0,0 +1,178 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/metrics"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	kafka "github.com/ONSdigital/dp-kafka/v4"
+	"github.com/ONSdigital/log.go/v2/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer of the spans started by the publisher
+const tracerName = "github.com/ONSdigital/dis-search-upstream-stub/events"
+
+// A list of errors returned by the publisher
+var (
+	ErrUnknownTopic  = errors.New("no producer for topic")
+	ErrTopicMismatch = errors.New("resource type is not published to topic")
+)
+
+// Publisher publishes resources as events, using a producer for each topic
+type Publisher struct {
+	Encoder   *Encoder
+	Producers map[string]kafka.IProducer
+	// Metrics, if set, counts the events sent to and failed for each topic
+	Metrics *metrics.Metrics
+}
+
+// NewPublisher creates a Publisher that sends events with the producers, keyed by topic
+func NewPublisher(encoder *Encoder, producers map[string]kafka.IProducer) *Publisher {
+	return &Publisher{
+		Encoder:   encoder,
+		Producers: producers,
+	}
+}
+
+// AddHeaders adds the headers describing the events produced to each topic to its producer. Producers send the same
+// headers with every event, and propagate the trace context of each event as a traceparent header if OpenTelemetry
+// is enabled.
+func (p *Publisher) AddHeaders() error {
+	for topic, producer := range p.Producers {
+		headers, err := p.Encoder.Headers(topic)
+		if err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, len(headers))
+		for key := range headers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			producer.AddHeader(key, headers[key])
+		}
+	}
+	return nil
+}
+
+// Topic returns the topic that events for the resource are published to
+func Topic(kcfg *config.Kafka, resource models.Resource) (string, error) {
+	switch resource.(type) {
+	case models.ContentUpdatedResource:
+		return kcfg.ContentUpdatedTopic, nil
+	case models.SearchContentUpdatedResource:
+		return kcfg.SearchContentUpdatedTopic, nil
+	case models.SearchContentDeletedResource:
+		return kcfg.SearchContentDeletedTopic, nil
+	default:
+		return "", fmt.Errorf("unsupported resource type: %T", resource)
+	}
+}
+
+// DecodeResource unmarshals a JSON payload into the type of resource that is published to the topic
+func (p *Publisher) DecodeResource(topic string, payload []byte) (models.Resource, error) {
+	return DecodeResource(p.Encoder.Kafka, topic, payload)
+}
+
+// DecodeResource unmarshals a JSON payload into the type of resource that is published to the topic
+// in the kafka config
+func DecodeResource(kcfg *config.Kafka, topic string, payload []byte) (models.Resource, error) {
+	switch topic {
+	case kcfg.ContentUpdatedTopic:
+		var r models.ContentUpdatedResource
+		err := json.Unmarshal(payload, &r)
+		return r, err
+	case kcfg.SearchContentUpdatedTopic:
+		var r models.SearchContentUpdatedResource
+		err := json.Unmarshal(payload, &r)
+		return r, err
+	case kcfg.SearchContentDeletedTopic:
+		var r models.SearchContentDeletedResource
+		err := json.Unmarshal(payload, &r)
+		return r, err
+	default:
+		return nil, ErrUnknownTopic
+	}
+}
+
+// Publish encodes the resource with the encoding configured for the topic and sends it to the topic
+func (p *Publisher) Publish(ctx context.Context, topic string, resource models.Resource) (*models.PublishedEvent, error) {
+	producer, ok := p.Producers[topic]
+	if !ok {
+		return nil, ErrUnknownTopic
+	}
+
+	resourceTopic, err := Topic(p.Encoder.Kafka, resource)
+	if err != nil {
+		return nil, err
+	}
+	if resourceTopic != topic {
+		return nil, ErrTopicMismatch
+	}
+
+	eventType, err := EventType(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	// The producer propagates the trace context of the span in the traceparent header of the event
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "send "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("event.type", eventType),
+		),
+	)
+	defer span.End()
+
+	payload, err := p.Encoder.Encode(topic, resource)
+	if err != nil {
+		p.Metrics.MessagePublished(topic, metrics.ResultFailed)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if err := producer.SendBytes(ctx, payload); err != nil {
+		p.Metrics.MessagePublished(topic, metrics.ResultFailed)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to send event: %w", err)
+	}
+	p.Metrics.MessagePublished(topic, metrics.ResultSent)
+
+	event := &models.PublishedEvent{
+		Topic:     topic,
+		EventType: eventType,
+		Encoding:  TopicEncoding(p.Encoder.Kafka, topic),
+		Framed:    p.Encoder.Framed(topic),
+	}
+
+	log.Info(ctx, "event published", log.Data{"event": event})
+	return event, nil
+}
+
+// Close closes every producer, returning the last error if any fail to close
+func (p *Publisher) Close(ctx context.Context) error {
+	var closeErr error
+
+	for topic, producer := range p.Producers {
+		if err := producer.Close(ctx); err != nil {
+			log.Error(ctx, "failed to close kafka producer", err, log.Data{"topic": topic})
+			closeErr = err
+		}
+	}
+
+	return closeErr
+}
@@ -0,0 +1,231 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/oteltest"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+	kafka "github.com/ONSdigital/dp-kafka/v4"
+	"github.com/ONSdigital/dp-kafka/v4/kafkatest"
+)
+
+func newTestPublisher(sendErr error) (*events.Publisher, map[string]*kafkatest.IProducerMock) {
+	kcfg := &config.Kafka{
+		ContentUpdatedTopic:          "content-updated",
+		SearchContentUpdatedTopic:    "search-content-updated",
+		SearchContentDeletedTopic:    "search-content-deleted",
+		ContentUpdatedEncoding:       events.EncodingAvro,
+		SearchContentUpdatedEncoding: events.EncodingJSON,
+		SearchContentDeletedEncoding: events.EncodingJSON,
+	}
+	encoder, err := events.NewEncoder(kcfg)
+	So(err, ShouldBeNil)
+
+	mocks := map[string]*kafkatest.IProducerMock{}
+	producers := map[string]kafka.IProducer{}
+	for _, topic := range []string{kcfg.ContentUpdatedTopic, kcfg.SearchContentUpdatedTopic, kcfg.SearchContentDeletedTopic} {
+		mocks[topic] = &kafkatest.IProducerMock{
+			SendBytesFunc: func(ctx context.Context, b []byte) error { return sendErr },
+			CloseFunc:     func(ctx context.Context) error { return nil },
+			AddHeaderFunc: func(key, value string) {},
+		}
+		producers[topic] = mocks[topic]
+	}
+
+	return events.NewPublisher(encoder, producers), mocks
+}
+
+func TestAddHeaders(t *testing.T) {
+	Convey("Given a publisher with a producer for each topic", t, func() {
+		publisher, producers := newTestPublisher(nil)
+
+		Convey("When the headers are added to the producers", func() {
+			So(publisher.AddHeaders(), ShouldBeNil)
+
+			Convey("Then each producer has the headers describing the events produced to its topic", func() {
+				calls := producers["content-updated"].AddHeaderCalls()
+				So(calls, ShouldHaveLength, 3)
+				So(calls[0].Key, ShouldEqual, events.HeaderContentType)
+				So(calls[0].Value, ShouldEqual, events.ContentTypeAvro)
+				So(calls[1].Key, ShouldEqual, events.HeaderEventType)
+				So(calls[1].Value, ShouldEqual, events.ContentPublishedEvent)
+				So(calls[2].Key, ShouldEqual, events.HeaderSchemaVersion)
+
+				calls = producers["search-content-deleted"].AddHeaderCalls()
+				So(calls, ShouldHaveLength, 3)
+				So(calls[0].Value, ShouldEqual, events.ContentTypeJSON)
+				So(calls[1].Value, ShouldEqual, events.SearchContentDeletedEvent)
+			})
+		})
+	})
+}
+
+func TestPublish(t *testing.T) {
+	Convey("Given a publisher with a producer for each topic", t, func() {
+		publisher, producers := newTestPublisher(nil)
+		ctx := context.Background()
+
+		Convey("When a content-updated resource is published to its topic", func() {
+			event, err := publisher.Publish(ctx, "content-updated", contentUpdated)
+			So(err, ShouldBeNil)
+
+			Convey("Then it is sent as Avro by the topic's producer only", func() {
+				So(producers["content-updated"].SendBytesCalls(), ShouldHaveLength, 1)
+				So(producers["search-content-updated"].SendBytesCalls(), ShouldBeEmpty)
+				So(producers["search-content-deleted"].SendBytesCalls(), ShouldBeEmpty)
+
+				var got models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(producers["content-updated"].SendBytesCalls()[0].B, &got), ShouldBeNil)
+				So(got, ShouldResemble, contentUpdated)
+			})
+
+			Convey("Then the published event is described", func() {
+				So(event, ShouldResemble, &models.PublishedEvent{
+					Topic:     "content-updated",
+					EventType: events.ContentPublishedEvent,
+					Encoding:  events.EncodingAvro,
+				})
+			})
+		})
+
+		Convey("When a search-content-deleted resource is published to its topic", func() {
+			_, err := publisher.Publish(ctx, "search-content-deleted", searchContentDeleted)
+			So(err, ShouldBeNil)
+
+			Convey("Then it is sent as JSON", func() {
+				So(producers["search-content-deleted"].SendBytesCalls(), ShouldHaveLength, 1)
+
+				var got models.SearchContentDeletedResource
+				So(json.Unmarshal(producers["search-content-deleted"].SendBytesCalls()[0].B, &got), ShouldBeNil)
+				So(got, ShouldResemble, searchContentDeleted)
+			})
+		})
+
+		Convey("When a resource is published to another resource type's topic", func() {
+			_, err := publisher.Publish(ctx, "content-updated", searchContentDeleted)
+
+			Convey("Then a topic mismatch error is returned and nothing is sent", func() {
+				So(err, ShouldEqual, events.ErrTopicMismatch)
+				So(producers["content-updated"].SendBytesCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a resource is published to a topic without a producer", func() {
+			_, err := publisher.Publish(ctx, "unknown", searchContentDeleted)
+
+			Convey("Then an unknown topic error is returned", func() {
+				So(err, ShouldEqual, events.ErrUnknownTopic)
+			})
+		})
+	})
+
+	Convey("Given a publisher whose producers fail to send", t, func() {
+		publisher, _ := newTestPublisher(errors.New("output channel closed"))
+
+		Convey("When a resource is published", func() {
+			_, err := publisher.Publish(context.Background(), "search-content-deleted", searchContentDeleted)
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "failed to send event: output channel closed")
+			})
+		})
+	})
+}
+
+func TestPublishSpan(t *testing.T) {
+	Convey("Given OpenTelemetry is set up with an in-memory exporter", t, func() {
+		exporter, restore := oteltest.Setup()
+		Reset(restore)
+
+		Convey("When a resource is published within a span", func() {
+			publisher, producers := newTestPublisher(nil)
+			ctx, parent := otel.Tracer("test").Start(context.Background(), "PUT /admin/resources")
+			_, err := publisher.Publish(ctx, "search-content-deleted", searchContentDeleted)
+			So(err, ShouldBeNil)
+			parent.End()
+
+			Convey("Then it is sent within a producer span of the parent, which the producer is given to propagate", func() {
+				spans := exporter.GetSpans()
+				So(spans, ShouldHaveLength, 2)
+				send := spans[0]
+				So(send.Name, ShouldEqual, "send search-content-deleted")
+				So(send.SpanKind, ShouldEqual, trace.SpanKindProducer)
+				So(send.Parent.SpanID(), ShouldEqual, spans[1].SpanContext.SpanID())
+
+				sendCtx := producers["search-content-deleted"].SendBytesCalls()[0].Ctx
+				So(trace.SpanContextFromContext(sendCtx).SpanID(), ShouldEqual, send.SpanContext.SpanID())
+			})
+		})
+
+		Convey("When a resource fails to be sent", func() {
+			publisher, _ := newTestPublisher(errors.New("output channel closed"))
+			_, err := publisher.Publish(context.Background(), "search-content-deleted", searchContentDeleted)
+			So(err, ShouldNotBeNil)
+
+			Convey("Then the producer span records the error", func() {
+				spans := exporter.GetSpans()
+				So(spans, ShouldHaveLength, 1)
+				So(spans[0].Status.Code, ShouldEqual, codes.Error)
+				So(spans[0].Status.Description, ShouldEqual, "output channel closed")
+			})
+		})
+	})
+}
+
+func TestDecodeResource(t *testing.T) {
+	Convey("Given a publisher", t, func() {
+		publisher, _ := newTestPublisher(nil)
+
+		Convey("When a payload is decoded for each topic", func() {
+			contentUpdatedResource, err1 := publisher.DecodeResource("content-updated", []byte(`{"uri": "/a", "data_type": "legacy"}`))
+			searchContentUpdatedResource, err2 := publisher.DecodeResource("search-content-updated", []byte(`{"uri": "/b", "content_type": "release"}`))
+			searchContentDeletedResource, err3 := publisher.DecodeResource("search-content-deleted", []byte(`{"uri": "/c"}`))
+
+			Convey("Then each is decoded into the resource type published to the topic", func() {
+				So(err1, ShouldBeNil)
+				So(err2, ShouldBeNil)
+				So(err3, ShouldBeNil)
+				So(contentUpdatedResource, ShouldResemble, models.ContentUpdatedResource{URI: "/a", DataType: "legacy"})
+				So(searchContentUpdatedResource, ShouldResemble, models.SearchContentUpdatedResource{URI: "/b", ContentType: "release"})
+				So(searchContentDeletedResource, ShouldResemble, models.SearchContentDeletedResource{URI: "/c"})
+			})
+		})
+
+		Convey("When a payload is decoded for an unknown topic", func() {
+			_, err := publisher.DecodeResource("unknown", []byte(`{}`))
+
+			Convey("Then an unknown topic error is returned", func() {
+				So(err, ShouldEqual, events.ErrUnknownTopic)
+			})
+		})
+	})
+}
+
+func TestPublisherClose(t *testing.T) {
+	Convey("Given a publisher", t, func() {
+		publisher, producers := newTestPublisher(nil)
+
+		Convey("When it is closed", func() {
+			err := publisher.Close(context.Background())
+
+			Convey("Then every producer is closed", func() {
+				So(err, ShouldBeNil)
+				for _, producer := range producers {
+					So(producer.CloseCalls(), ShouldHaveLength, 1)
+				}
+			})
+		})
+	})
+}
@@ -38,6 +38,8 @@ func NewComponent() (*Component, error) {
 		return nil, err
 	}
 
+	c.Config.ClockStart = time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)
+
 	initMock := &mock.InitialiserMock{
 		DoGetHealthCheckFunc: c.DoGetHealthcheckOk,
 		DoGetHTTPServerFunc:  c.DoGetHTTPServer,
@@ -70,6 +72,10 @@ func (c *Component) InitialiseService() (http.Handler, error) {
 		return nil, err
 	}
 
+	// freeze the clock at its start so that the fixture dates relative to it match the expected responses
+	c.svc.Clock.Freeze()
+	c.svc.Clock.Set(c.Config.ClockStart)
+
 	c.ServiceRunning = true
 	return c.HTTPServer.Handler, nil
 }
@@ -0,0 +1,208 @@
+package healthsim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
+	"github.com/ONSdigital/dis-search-upstream-stub/metrics"
+)
+
+// CheckName is the name of the check added to the health response while its status is simulated
+const CheckName = "Health simulation"
+
+// A list of errors returned when a simulation cannot be set
+var (
+	ErrNoSteps        = errors.New("a health simulation must have at least one step")
+	ErrInvalidStatus  = errors.New("health status must be OK, WARNING or CRITICAL")
+	ErrUnboundedStep  = errors.New("only the last step of a health simulation may have no duration")
+	ErrUnboundedCycle = errors.New("every step of a repeating health simulation must have a duration")
+)
+
+// Step is a health status that is reported for a duration, or from then on if the duration is zero
+type Step struct {
+	Status   string
+	Duration time.Duration
+}
+
+// Simulator forces the status reported by the health endpoint, following a schedule of steps that can repeat so that
+// the status flaps. It runs on a real clock, not the stub's virtual clock, as dependent services check the stub's
+// health in real time.
+type Simulator struct {
+	// Metrics, if set, counts the health responses degraded by the simulation
+	Metrics *metrics.Metrics
+
+	mutex  sync.RWMutex
+	clock  clock.Clock
+	steps  []Step
+	repeat bool
+	start  time.Time
+}
+
+// New creates a Simulator that is not simulating, telling the time with the clock
+func New(c clock.Clock) *Simulator {
+	return &Simulator{clock: c}
+}
+
+// Set starts simulating the steps from now, replacing any simulation in progress. Once the steps are over, the real
+// status is reported again, unless they repeat.
+func (s *Simulator) Set(steps []Step, repeat bool) error {
+	if len(steps) == 0 {
+		return ErrNoSteps
+	}
+	for i, step := range steps {
+		switch step.Status {
+		case healthcheck.StatusOK, healthcheck.StatusWarning, healthcheck.StatusCritical:
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidStatus, step.Status)
+		}
+		if step.Duration < 0 {
+			return fmt.Errorf("step %d has a negative duration", i+1)
+		}
+		if step.Duration == 0 && repeat {
+			return ErrUnboundedCycle
+		}
+		if step.Duration == 0 && i < len(steps)-1 {
+			return ErrUnboundedStep
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.steps = append([]Step(nil), steps...)
+	s.repeat = repeat
+	s.start = s.clock.Now()
+	return nil
+}
+
+// Clear stops simulating, so that the real status is reported
+func (s *Simulator) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.steps = nil
+	s.repeat = false
+}
+
+// State returns the steps being simulated, whether they repeat, and the current step's status and end. The status is
+// empty once the steps are over, or if none are being simulated, and the end is zero if the current step has no end.
+func (s *Simulator) State() (steps []Step, repeat bool, status string, until time.Time) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	status, until = s.current()
+	return append([]Step(nil), s.steps...), s.repeat, status, until
+}
+
+// Status returns the simulated status, which is empty if the real status should be reported
+func (s *Simulator) Status() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	status, _ := s.current()
+	return status
+}
+
+// current returns the status of the step at the current time and when it ends
+func (s *Simulator) current() (string, time.Time) {
+	if len(s.steps) == 0 {
+		return "", time.Time{}
+	}
+
+	now := s.clock.Now()
+	elapsed := now.Sub(s.start)
+	if s.repeat {
+		var cycle time.Duration
+		for _, step := range s.steps {
+			cycle += step.Duration
+		}
+		elapsed %= cycle
+	}
+
+	for _, step := range s.steps {
+		if step.Duration == 0 {
+			return step.Status, time.Time{}
+		}
+		if elapsed < step.Duration {
+			return step.Status, now.Add(step.Duration - elapsed)
+		}
+		elapsed -= step.Duration
+	}
+	return "", time.Time{}
+}
+
+// Handler wraps a health handler so that, while a status is simulated, its response reports that status with the
+// status code dp-healthcheck gives it, and a check explaining why. Otherwise the real response is passed through.
+func (s *Simulator) Handler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		status := s.Status()
+		if status == "" {
+			next(w, req)
+			return
+		}
+
+		if status != healthcheck.StatusOK {
+			s.Metrics.FaultInjected("health_" + strings.ToLower(status))
+		}
+
+		recorded := &bufferedResponse{header: http.Header{}}
+		next(recorded, req)
+
+		var body map[string]interface{}
+		if err := json.Unmarshal(recorded.body.Bytes(), &body); err != nil {
+			body = map[string]interface{}{}
+		}
+		checks, _ := body["checks"].([]interface{})
+		body["status"] = status
+		body["checks"] = append(checks, map[string]interface{}{
+			"name":         CheckName,
+			"status":       status,
+			"message":      fmt.Sprintf("health status forced to %s by /admin/health", status),
+			"last_checked": s.clock.Now(),
+		})
+
+		b, err := json.Marshal(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(StatusCode(status))
+		_, _ = w.Write(b)
+	}
+}
+
+// bufferedResponse holds the body written by the real health handler, so that it can be rewritten to report the
+// simulated status. The real status code and headers are discarded.
+type bufferedResponse struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *bufferedResponse) WriteHeader(int) {}
+
+// StatusCode returns the status code of a health response reporting the status, as given by dp-healthcheck and
+// expected by the health checker of dp-api-clients-go
+func StatusCode(status string) int {
+	switch status {
+	case healthcheck.StatusOK:
+		return http.StatusOK
+	case healthcheck.StatusWarning:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,168 @@
+package healthsim_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/healthsim"
+	"github.com/ONSdigital/dis-search-upstream-stub/metrics"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// realHealth responds as a healthy dp-healthcheck with a kafka producer check
+func realHealth(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"status":"OK","version":{"version":"v1"},"checks":[{"name":"Kafka producer (content-updated)","status":"OK"}]}`))
+}
+
+func TestSet(t *testing.T) {
+	Convey("Given a simulator", t, func() {
+		s := healthsim.New(&fakeClock{})
+
+		Convey("When steps that are not valid are set, an error is returned and nothing is simulated", func() {
+			So(s.Set(nil, false), ShouldEqual, healthsim.ErrNoSteps)
+			So(s.Set([]healthsim.Step{{Status: "POORLY"}}, false), ShouldWrap, healthsim.ErrInvalidStatus)
+			So(s.Set([]healthsim.Step{{Status: healthcheck.StatusOK, Duration: -time.Second}}, false), ShouldNotBeNil)
+			So(s.Set([]healthsim.Step{{Status: healthcheck.StatusCritical}, {Status: healthcheck.StatusOK}}, false), ShouldEqual, healthsim.ErrUnboundedStep)
+			So(s.Set([]healthsim.Step{{Status: healthcheck.StatusCritical}}, true), ShouldEqual, healthsim.ErrUnboundedCycle)
+			So(s.Status(), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestStatus(t *testing.T) {
+	Convey("Given a simulator", t, func() {
+		c := &fakeClock{now: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+		s := healthsim.New(c)
+
+		Convey("When a status is forced without a duration", func() {
+			So(s.Set([]healthsim.Step{{Status: healthcheck.StatusCritical}}, false), ShouldBeNil)
+			c.now = c.now.Add(24 * time.Hour)
+
+			Convey("Then it is reported until the simulation is cleared", func() {
+				steps, repeat, status, until := s.State()
+				So(steps, ShouldResemble, []healthsim.Step{{Status: healthcheck.StatusCritical}})
+				So(repeat, ShouldBeFalse)
+				So(status, ShouldEqual, healthcheck.StatusCritical)
+				So(until, ShouldBeZeroValue)
+
+				s.Clear()
+				So(s.Status(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a schedule of steps is set", func() {
+			So(s.Set([]healthsim.Step{
+				{Status: healthcheck.StatusWarning, Duration: 10 * time.Second},
+				{Status: healthcheck.StatusCritical, Duration: 20 * time.Second},
+			}, false), ShouldBeNil)
+			start := c.now
+
+			Convey("Then each step's status is reported in turn, and then the real status", func() {
+				So(s.Status(), ShouldEqual, healthcheck.StatusWarning)
+
+				c.now = start.Add(15 * time.Second)
+				_, _, status, until := s.State()
+				So(status, ShouldEqual, healthcheck.StatusCritical)
+				So(until, ShouldEqual, start.Add(30*time.Second))
+
+				c.now = start.Add(30 * time.Second)
+				So(s.Status(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a repeating schedule is set", func() {
+			So(s.Set([]healthsim.Step{
+				{Status: healthcheck.StatusOK, Duration: 3 * time.Second},
+				{Status: healthcheck.StatusCritical, Duration: time.Second},
+			}, true), ShouldBeNil)
+			start := c.now
+
+			Convey("Then the status flaps between the steps", func() {
+				var statuses []string
+				for i := 0; i < 8; i++ {
+					c.now = start.Add(time.Duration(i) * time.Second)
+					statuses = append(statuses, s.Status())
+				}
+				So(statuses, ShouldResemble, []string{"OK", "OK", "OK", "CRITICAL", "OK", "OK", "OK", "CRITICAL"})
+			})
+		})
+	})
+}
+
+func TestHandler(t *testing.T) {
+	Convey("Given a health handler wrapped by a simulator", t, func() {
+		s := healthsim.New(&fakeClock{now: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)})
+		handler := s.Handler(realHealth)
+
+		Convey("When nothing is simulated, the real response is returned", func() {
+			resp := httptest.NewRecorder()
+			handler(resp, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+
+			So(resp.Code, ShouldEqual, http.StatusOK)
+			So(resp.Body.String(), ShouldContainSubstring, `"status":"OK"`)
+			So(resp.Body.String(), ShouldNotContainSubstring, healthsim.CheckName)
+		})
+
+		for status, code := range map[string]int{
+			healthcheck.StatusOK:       http.StatusOK,
+			healthcheck.StatusWarning:  http.StatusTooManyRequests,
+			healthcheck.StatusCritical: http.StatusInternalServerError,
+		} {
+			Convey("When "+status+" is simulated, the real response reports it with its status code and a check", func() {
+				So(s.Set([]healthsim.Step{{Status: status}}, false), ShouldBeNil)
+				resp := httptest.NewRecorder()
+				handler(resp, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+
+				So(resp.Code, ShouldEqual, code)
+				var body struct {
+					Status  string                 `json:"status"`
+					Version map[string]interface{} `json:"version"`
+					Checks  []map[string]interface{}
+				}
+				So(json.Unmarshal(resp.Body.Bytes(), &body), ShouldBeNil)
+				So(body.Status, ShouldEqual, status)
+				So(body.Version["version"], ShouldEqual, "v1")
+				So(body.Checks, ShouldHaveLength, 2)
+				So(body.Checks[1]["name"], ShouldEqual, healthsim.CheckName)
+				So(body.Checks[1]["status"], ShouldEqual, status)
+			})
+		}
+	})
+}
+
+func TestHandlerMetrics(t *testing.T) {
+	Convey("Given a health handler wrapped by a simulator with metrics", t, func() {
+		s := healthsim.New(&fakeClock{now: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)})
+		s.Metrics = metrics.New()
+		handler := s.Handler(realHealth)
+
+		Convey("When OK and then WARNING are simulated", func() {
+			So(s.Set([]healthsim.Step{{Status: healthcheck.StatusOK}}, false), ShouldBeNil)
+			handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+			So(s.Set([]healthsim.Step{{Status: healthcheck.StatusWarning}}, false), ShouldBeNil)
+			handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+
+			Convey("Then only the degraded response is counted as an injected fault", func() {
+				resp := httptest.NewRecorder()
+				s.Metrics.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+				So(resp.Body.String(), ShouldContainSubstring, `dis_search_upstream_stub_faults_injected_total{fault="health_warning"} 1`)
+				So(resp.Body.String(), ShouldNotContainSubstring, `fault="health_ok"`)
+			})
+		})
+	})
+}
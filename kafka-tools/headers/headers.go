@@ -0,0 +1,72 @@
+package headers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	dpotelgo "github.com/ONSdigital/dp-otel-go"
+	"github.com/Shopify/sarama"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer of the spans started by the kafka tools
+const tracerName = "github.com/ONSdigital/dis-search-upstream-stub/kafka-tools"
+
+// SetupOTel sets up OpenTelemetry as the service does if it is enabled, so that the trace context of each message
+// is exported and propagated. The returned function must be called to flush any spans before exiting.
+func SetupOTel(ctx context.Context, cfg *config.Config) (shutdown func(context.Context) error, err error) {
+	if !cfg.OtelEnabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	return dpotelgo.SetupOTelSDK(ctx, dpotelgo.Config{
+		OtelServiceName:          cfg.OTServiceName,
+		OtelExporterOtlpEndpoint: cfg.OTExporterOTLPEndpoint,
+		OtelBatchTimeout:         cfg.OTBatchTimeout,
+	})
+}
+
+// StartSpan starts a producer span for a message sent to the topic. The span does nothing unless OpenTelemetry has
+// been set up.
+func StartSpan(ctx context.Context, topic string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "send "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+		),
+	)
+}
+
+// Set sets the headers describing the events produced to the topic on a message, and the trace context of ctx. The
+// trace context is propagated with the configured propagators if ctx has a span, or is a new trace otherwise, so
+// that every message has a traceparent header.
+func Set(ctx context.Context, message *sarama.ProducerMessage, encoder *events.Encoder, topic string) error {
+	headers, err := encoder.Headers(topic)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		message.Headers = append(message.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(headers[key])})
+	}
+
+	carrier := otelsarama.NewProducerMessageCarrier(message)
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		otel.GetTextMapPropagator().Inject(ctx, carrier)
+	}
+	if carrier.Get(events.HeaderTraceParent) == "" {
+		carrier.Set(events.HeaderTraceParent, events.TraceParent(ctx))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package headers_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/headers"
+	"github.com/Shopify/sarama"
+)
+
+func newTestEncoder() *events.Encoder {
+	encoder, err := events.NewEncoder(&config.Kafka{
+		ContentUpdatedTopic:       "content-updated",
+		SearchContentUpdatedTopic: "search-content-updated",
+		SearchContentDeletedTopic: "search-content-deleted",
+		ContentUpdatedEncoding:    events.EncodingAvro,
+	})
+	So(err, ShouldBeNil)
+	return encoder
+}
+
+func header(message *sarama.ProducerMessage, key string) string {
+	for _, h := range message.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestSet(t *testing.T) {
+	Convey("Given a message for the content-updated topic and a context without a span", t, func() {
+		encoder := newTestEncoder()
+		message := &sarama.ProducerMessage{Topic: "content-updated"}
+
+		Convey("When its headers are set", func() {
+			err := headers.Set(context.Background(), message, encoder, "content-updated")
+			So(err, ShouldBeNil)
+
+			Convey("Then it has the headers describing the topic's events and a new trace", func() {
+				So(message.Headers, ShouldHaveLength, 4)
+				So(header(message, events.HeaderContentType), ShouldEqual, events.ContentTypeAvro)
+				So(header(message, events.HeaderEventType), ShouldEqual, events.ContentPublishedEvent)
+				So(header(message, events.HeaderSchemaVersion), ShouldNotBeEmpty)
+				So(header(message, events.HeaderTraceParent), ShouldStartWith, "00-")
+			})
+		})
+	})
+
+	Convey("Given OpenTelemetry is set up with the trace context propagator", t, func() {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		Reset(func() {
+			otel.SetTracerProvider(previousProvider)
+			otel.SetTextMapPropagator(previousPropagator)
+		})
+
+		encoder := newTestEncoder()
+		message := &sarama.ProducerMessage{Topic: "search-content-deleted"}
+
+		Convey("When the headers of a message are set within its producer span", func() {
+			ctx, span := headers.StartSpan(context.Background(), "search-content-deleted")
+			err := headers.Set(ctx, message, encoder, "search-content-deleted")
+			So(err, ShouldBeNil)
+			span.End()
+
+			Convey("Then the traceparent header propagates the span", func() {
+				spans := exporter.GetSpans()
+				So(spans, ShouldHaveLength, 1)
+				So(spans[0].Name, ShouldEqual, "send search-content-deleted")
+
+				sc := spans[0].SpanContext
+				So(header(message, events.HeaderTraceParent), ShouldEqual, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01")
+				So(message.Headers, ShouldHaveLength, 4)
+			})
+		})
+	})
+
+	Convey("Given a message for an unknown topic", t, func() {
+		encoder := newTestEncoder()
+		message := &sarama.ProducerMessage{Topic: "unknown"}
+
+		Convey("When its headers are set", func() {
+			err := headers.Set(context.Background(), message, encoder, "unknown")
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldEqual, events.ErrUnknownTopic)
+				So(message.Headers, ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// injector decides which messages are re-sent, delayed or paired with a delete of the same URI, so that the load test
+// can check that consumers are idempotent and cope with events arriving out of order
+type injector struct {
+	duplicateRatio float64
+	delayRatio     float64
+	maxDelay       time.Duration
+	pairRatio      float64
+	shuffle        bool
+	deleted        *target // the target that the deletes of pairs are sent to
+
+	mutex      sync.Mutex
+	duplicates int
+	delayed    int
+	longest    time.Duration
+	pairs      int
+	reversed   int
+}
+
+// injectionReport describes the duplicates, delays and out of order pairs injected into a run
+type injectionReport struct {
+	Duplicates    int     `json:"duplicates"`
+	Delayed       int     `json:"delayed"`
+	MaxDelay      float64 `json:"max_delay_ms"`
+	Pairs         int     `json:"pairs"`
+	ReversedPairs int     `json:"reversed_pairs"`
+}
+
+// spread reports whether the message at index is one of a ratio of messages spread evenly through a run, and its
+// number among the messages selected
+func spread(index int, ratio float64) (n int, selected bool) {
+	n = int(float64(index) * ratio)
+	return n, int(float64(index+1)*ratio) > n
+}
+
+// inject marks a job to be re-sent or delayed, and pairs search-content-updated jobs with a delete of the same URI,
+// returning the job to queue and the index of the next message. The delete of a pair is sent after the update, or in
+// a random order if shuffling, by the same worker so that the order is kept.
+func (in *injector) inject(j *job, next int) (*job, int) {
+	if in == nil {
+		return j, next
+	}
+
+	_, j.duplicate = spread(j.index, in.duplicateRatio)
+	if _, delayed := spread(j.index, in.delayRatio); delayed && in.maxDelay > 0 {
+		j.delay = rand.N(in.maxDelay)
+	}
+
+	updated, ok := j.item.(models.SearchContentUpdatedResource)
+	if !ok || in.deleted == nil {
+		return j, next
+	}
+	if _, paired := spread(j.target.queued, in.pairRatio); !paired {
+		return j, next
+	}
+
+	deleted := &job{
+		target: in.deleted,
+		item:   models.SearchContentDeletedResource{URI: updated.URI},
+		index:  next,
+	}
+	if in.shuffle && rand.IntN(2) == 0 {
+		deleted.delay, j.delay = j.delay, 0
+		deleted.then = j
+		return deleted, next + 1
+	}
+	j.then = deleted
+	return j, next + 1
+}
+
+// wait waits for the delay of a job, unless the context is done first
+func (in *injector) wait(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	in.delayed++
+	in.longest = max(in.longest, delay)
+}
+
+// duplicated records a message that was re-sent
+func (in *injector) duplicated() {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	in.duplicates++
+}
+
+// paired records a pair that was sent, which was reversed if its delete was sent first
+func (in *injector) paired(head *job) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	in.pairs++
+	if head.target == in.deleted {
+		in.reversed++
+	}
+}
+
+// report returns what was injected into the run, or nil if nothing could have been
+func (in *injector) report() *injectionReport {
+	if in == nil {
+		return nil
+	}
+
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	return &injectionReport{
+		Duplicates:    in.duplicates,
+		Delayed:       in.delayed,
+		MaxDelay:      milliseconds(in.longest),
+		Pairs:         in.pairs,
+		ReversedPairs: in.reversed,
+	}
+}
@@ -2,19 +2,28 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"sync"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/config"
 	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/headers"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/malformed"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/partitioning"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/sink"
 	"github.com/ONSdigital/dis-search-upstream-stub/models"
-	"github.com/ONSdigital/dis-search-upstream-stub/schema"
 	kafka "github.com/ONSdigital/dp-kafka/v4"
 	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/Shopify/sarama"
+	"go.opentelemetry.io/otel/codes"
 )
 
 const (
@@ -23,136 +32,193 @@ const (
 	defaultLegacyContentUpdatedMessages = 1
 	defaultSearchContentUpdatedMessages = 1
 	defaultSearchContentDeletedMessages = 1
+	// Default values for pacing
+	defaultWorkers = 10
+	defaultSteps   = 4
+	// Default file for the JSON report
+	defaultReportJSON = "load-test-report.json"
+	// Default time to wait for verification
+	defaultVerifyTimeout = 30 * time.Second
 )
 
-type producers struct {
-	ContentUpdatedProducer       *kafka.Producer
-	SearchContentUpdatedProducer *kafka.Producer
-	SearchContentDeletedProducer *kafka.Producer
-}
-
 type resources struct {
 	contentUpdatedResources       *models.Resources
 	searchContentUpdatedResources *models.Resources
 	searchContentDeletedResources *models.Resources
 }
 
+// traceIDPattern matches the trace IDs made by makeStubTraceID, in JSON and Avro payloads alike
+var traceIDPattern = regexp.MustCompile(`stub-[0-9]+-[0-9]+`)
+
 func makeStubTraceID(loopIndex int) string {
 	return fmt.Sprintf("stub-%d-%d", time.Now().UnixMilli(), loopIndex)
 }
 
-func sendMessageToKafka(producer *kafka.Producer, item models.Resource, loopIndex int, wg *sync.WaitGroup) {
-	defer wg.Done()
+// sender sends the load test's messages
+type sender struct {
+	producer     sink.Producer
+	encoder      *events.Encoder
+	partitioning *partitioning.Strategy
+	tracker      *tracker  // nil unless verifying
+	poison       *poison   // nil unless sending malformed events
+	injector     *injector // nil unless injecting duplicates, delays or pairs
+}
 
-	var (
-		messageBytes []byte
-		err          error
-		eventType    string
-		traceID      string
-	)
+// sendMessageToKafka sends a message and waits for kafka to acknowledge it, returning the time that took. If the
+// sender has a tracker, the message is sent with a unique trace ID and recorded with the tracker. If kind is set, a
+// malformed event of that kind is sent instead of the resource, which is not expected to be consumed. If duplicate is
+// set, the same message is sent again once it has been acknowledged, and the tracker is told to expect it twice.
+func (s *sender) sendMessageToKafka(topic string, item models.Resource, loopIndex int, kind string, duplicate bool) (time.Duration, error) {
+	item, traceID, err := withTraceID(item, loopIndex, s.tracker != nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Marshal the resource to Kafka message format with the encoding configured for the topic
+	eventType, err := events.EventType(item)
+	if err != nil {
+		return 0, err
+	}
+	eventType = fmt.Sprintf("%s(%s)", eventType, strings.ToUpper(events.TopicEncoding(s.encoder.Kafka, topic)))
 
-	// Marshal the resource to Kafka message format based on the topic type
+	messageBytes, err := s.encoder.Encode(topic, item)
+	if kind != "" {
+		messageBytes, err = s.poison.generator.Payload(kind, topic, item)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	// Send message to Kafka in a producer span, with headers describing the event and carrying the span's context
+	ctx, span := headers.StartSpan(context.Background(), topic)
+	defer span.End()
+
+	message := &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(messageBytes)}
+	key := s.partitioning.Apply(message, item)
+	if err := headers.Set(ctx, message, s.encoder, topic); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	tracked := s.tracker != nil && kind == ""
+	if tracked {
+		s.tracker.expect(traceID, start)
+	}
+	partition, offset, err := s.producer.SendMessage(message)
+	latency := time.Since(start)
+	if err != nil {
+		if tracked {
+			s.tracker.forget(traceID)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return latency, err
+	}
+	if duplicate {
+		again := &sarama.ProducerMessage{
+			Topic:     message.Topic,
+			Key:       message.Key,
+			Value:     message.Value,
+			Headers:   message.Headers,
+			Partition: message.Partition,
+		}
+		if _, _, err := s.producer.SendMessage(again); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return latency, fmt.Errorf("failed to re-send duplicate: %w", err)
+		}
+		if tracked {
+			s.tracker.resend(traceID)
+		}
+	}
+	log.Info(ctx, "resource delivered to Kafka", log.Data{
+		"event_type": eventType,
+		"framed":     s.encoder.Framed(topic),
+		"malformed":  kind,
+		"duplicate":  duplicate,
+		"trace_id":   traceID,
+		"topic":      topic,
+		"key":        key,
+		"partition":  partition,
+		"offset":     offset,
+		"latency":    latency.String(),
+		"item":       item,
+	})
+	return latency, nil
+}
+
+// withTraceID sets a trace ID on the resource if it does not already have one, or always if unique is set
+func withTraceID(item models.Resource, loopIndex int, unique bool) (models.Resource, string, error) {
 	switch r := item.(type) {
 	case models.ContentUpdatedResource:
-		// Marshal for the legacy topic (ContentPublishedEvent)
-		if r.TraceID == "" {
+		if r.TraceID == "" || unique {
 			r.TraceID = makeStubTraceID(loopIndex)
 		}
-		traceID = r.TraceID
-		messageBytes, err = schema.ContentPublishedEvent.Marshal(r)
-		eventType = "ContentPublishedEvent(AVRO)"
+		return r, r.TraceID, nil
 	case models.SearchContentUpdatedResource:
-		// Marshal for the new topic (SearchContentUpdatedEvent)
-		if r.TraceID == "" {
+		if r.TraceID == "" || unique {
 			r.TraceID = makeStubTraceID(loopIndex)
 		}
-		traceID = r.TraceID
-		messageBytes, err = json.Marshal(r)
-		eventType = "SearchContentUpdatedEvent(JSON)"
+		return r, r.TraceID, nil
 	case models.SearchContentDeletedResource:
-		// Marshal for the new topic (SearchContentDeletedEvent)
-		if r.TraceID == "" {
+		if r.TraceID == "" || unique {
 			r.TraceID = makeStubTraceID(loopIndex)
 		}
-		traceID = r.TraceID
-		messageBytes, err = json.Marshal(r)
-		eventType = "SearchContentDeleteEvent(JSON)"
+		return r, r.TraceID, nil
 	default:
-		log.Error(context.Background(), "unsupported resource type", fmt.Errorf("resource type: %v", item))
-		return
+		return nil, "", fmt.Errorf("unsupported resource type: %T", item)
 	}
+}
 
-	if err != nil {
-		log.Error(context.Background(), "event marshal error", err, log.Data{"event_type": eventType})
-		return
+// securityConfig returns the kafka TLS config, if TLS is configured
+func securityConfig(cfg *config.Config) *kafka.SecurityConfig {
+	if cfg.Kafka.SecProtocol != config.KafkaTLSProtocol {
+		return nil
 	}
+	return kafka.GetSecurityConfig(
+		cfg.Kafka.SecCACerts,
+		cfg.Kafka.SecClientCert,
+		cfg.Kafka.SecClientKey,
+		cfg.Kafka.SecSkipVerify,
+	)
+}
 
-	// Send message to Kafka
-	producer.Channels().Output <- kafka.BytesMessage{Value: messageBytes}
-	log.Info(context.Background(), "resource sent to Kafka", log.Data{
-		"event_type": eventType,
-		"trace_id":   traceID,
-		"item":       item,
-	})
+// createProducer creates a producer capturing the messages to the file given by the flags, or sending them to kafka
+func createProducer(cfg *config.Config, opts loadOptions, strategy *partitioning.Strategy, p *poison) (sink.Producer, error) {
+	if opts.capture != "" {
+		return sink.OpenCapture(opts.capture)
+	}
+	return createKafkaProducer(cfg, strategy, p)
 }
 
-func createKafkaProducer(ctx context.Context, cfg *config.Config, topic string) (*kafka.Producer, error) {
+// createKafkaProducer creates a synchronous sarama producer with the config dp-kafka would use, so that each message
+// can wait for kafka to acknowledge it
+func createKafkaProducer(cfg *config.Config, strategy *partitioning.Strategy, p *poison) (sarama.SyncProducer, error) {
 	// Create Kafka producer configuration
 	producerConfig := &kafka.ProducerConfig{
 		BrokerAddrs:     cfg.Kafka.Addr,
-		Topic:           topic,
 		KafkaVersion:    &cfg.Kafka.Version,
 		MaxMessageBytes: &cfg.Kafka.MaxBytes,
+		SecurityConfig:  securityConfig(cfg),
 	}
 
-	// Set security config if needed
-	if cfg.Kafka.SecProtocol == config.KafkaTLSProtocol {
-		producerConfig.SecurityConfig = kafka.GetSecurityConfig(
-			cfg.Kafka.SecCACerts,
-			cfg.Kafka.SecClientCert,
-			cfg.Kafka.SecClientKey,
-			cfg.Kafka.SecSkipVerify,
-		)
-	}
-
-	// Create and return the Kafka producer
-	producer, err := kafka.NewProducer(ctx, producerConfig)
+	saramaConfig, err := producerConfig.Get()
 	if err != nil {
-		return nil, fmt.Errorf("fatal error trying to create kafka producer for topic %s: %w", topic, err)
+		return nil, fmt.Errorf("invalid kafka producer config: %w", err)
 	}
-
-	return producer, nil
-}
-
-func buildAndInitProducers(ctx context.Context, cfg *config.Config) (producers, error) {
-	// inner helper: create + log errors + initialise
-	mk := func(topic string) (*kafka.Producer, error) {
-		p, err := createKafkaProducer(ctx, cfg, topic)
-		if err != nil {
-			return nil, err
-		}
-		p.LogErrors(ctx)
-		if err := p.Initialise(ctx); err != nil {
-			_ = p.Close(ctx) // best-effort cleanup on init failure
-			return nil, err
-		}
-		return p, nil
+	saramaConfig.Producer.Return.Successes = true
+	strategy.Configure(saramaConfig)
+	if p != nil {
+		p.generator.Configure(saramaConfig, p.kinds)
 	}
 
-	contentUpdated, err := mk(cfg.Kafka.ContentUpdatedTopic)
-	if err != nil {
-		return producers{}, fmt.Errorf("legacy content-updated producer: %w", err)
-	}
-	searchContentUpdated, err := mk(cfg.Kafka.SearchContentUpdatedTopic)
-	if err != nil {
-		return producers{}, fmt.Errorf("search-content-updated producer: %w", err)
-	}
-	searchContentDeleted, err := mk(cfg.Kafka.SearchContentDeletedTopic)
+	// Create and return the Kafka producer
+	producer, err := sarama.NewSyncProducer(cfg.Kafka.Addr, saramaConfig)
 	if err != nil {
-		return producers{}, fmt.Errorf("search-content-deleted producer: %w", err)
+		return nil, fmt.Errorf("fatal error trying to create kafka producer: %w", err)
 	}
 
-	return producers{ContentUpdatedProducer: contentUpdated, SearchContentUpdatedProducer: searchContentUpdated, SearchContentDeletedProducer: searchContentDeleted}, nil
+	return producer, nil
 }
 
 func fetchAllResources(ctx context.Context) (resources, error) {
@@ -181,99 +247,424 @@ func fetchAllResources(ctx context.Context) (resources, error) {
 	}, nil
 }
 
-func main() {
-	log.Namespace = serviceName
-	ctx := context.Background()
+// loadOptions are the flags that shape the load test
+type loadOptions struct {
+	numContentUpdated       int
+	numSearchContentUpdated int
+	numSearchContentDeleted int
+	rate                    float64
+	duration                time.Duration
+	rampUp                  time.Duration
+	profile                 string
+	steps                   int
+	workers                 int
+	reportJSON              string
+	reportText              string
+	verify                  bool
+	verifyTopics            string
+	verifyGroup             string
+	verifyTimeout           time.Duration
+	keyBy                   string
+	partition               int
+	malformed               string
+	malformedRatio          float64
+	duplicateRatio          float64
+	delayRatio              float64
+	maxDelay                time.Duration
+	pairRatio               float64
+	shufflePairs            bool
+	capture                 string
+}
+
+func parseFlags() loadOptions {
+	var opts loadOptions
 
 	// Define flags for how many messages to send per topic
-	var numContentUpdated, numSearchContentUpdated, numSearchContentDeleted int
-	flag.IntVar(&numContentUpdated, "num-content-updated", defaultLegacyContentUpdatedMessages,
-		"Number of legacy 'content-updated' messages to send (Avro)")
-	flag.IntVar(&numSearchContentUpdated, "num-search-content-updated", defaultSearchContentUpdatedMessages,
-		"Number of 'search-content-updated' messages to send (JSON)")
-	flag.IntVar(&numSearchContentDeleted, "num-search-content-deleted", defaultSearchContentDeletedMessages,
-		"Number of 'search-content-deleted' messages to send (JSON)")
+	flag.IntVar(&opts.numContentUpdated, "num-content-updated", defaultLegacyContentUpdatedMessages,
+		"Number of legacy 'content-updated' messages to send (encoding set by KAFKA_CONTENT_UPDATED_ENCODING)")
+	flag.IntVar(&opts.numSearchContentUpdated, "num-search-content-updated", defaultSearchContentUpdatedMessages,
+		"Number of 'search-content-updated' messages to send (encoding set by KAFKA_SEARCH_CONTENT_UPDATED_ENCODING)")
+	flag.IntVar(&opts.numSearchContentDeleted, "num-search-content-deleted", defaultSearchContentDeletedMessages,
+		"Number of 'search-content-deleted' messages to send (encoding set by KAFKA_SEARCH_CONTENT_DELETED_ENCODING)")
+
+	// Define flags for how fast and for how long to send them
+	flag.Float64Var(&opts.rate, "rate", 0,
+		"Target messages per second across all topics (0 sends as fast as the workers can)")
+	flag.DurationVar(&opts.duration, "duration", 0,
+		"Send messages for this long, using the message numbers as the mix of topics, instead of sending them once")
+	flag.DurationVar(&opts.rampUp, "ramp-up", 0,
+		"Time taken to ramp up to the target rate")
+	flag.StringVar(&opts.profile, "profile", profileLinear,
+		"Ramp-up profile: constant, linear or step")
+	flag.IntVar(&opts.steps, "steps", defaultSteps,
+		"Number of steps in the step ramp-up profile")
+	flag.IntVar(&opts.workers, "workers", defaultWorkers,
+		"Number of workers sending messages concurrently")
+
+	// Define flags for where to send the messages
+	flag.StringVar(&opts.capture, "capture", "",
+		"File to capture the messages to as NDJSON, instead of sending them to kafka")
+
+	// Define flags for where to write the report of the run
+	flag.StringVar(&opts.reportJSON, "report-json", defaultReportJSON,
+		"File to write the JSON report of the run to (no JSON report if empty)")
+	flag.StringVar(&opts.reportText, "report-text", "-",
+		"File to write the text report of the run to, or - for stdout (no text report if empty)")
+
+	// Define flags for how messages are keyed and partitioned
+	flag.StringVar(&opts.keyBy, "key-by", partitioning.KeyNone,
+		"Key strategy of the messages: none, uri, collection-id or random")
+	flag.IntVar(&opts.partition, "partition", partitioning.AnyPartition,
+		"Partition to send all the messages to (chosen by the partitioner from the key if -1)")
+
+	// Define flags for sending malformed events
+	flag.StringVar(&opts.malformed, "malformed", "",
+		"Comma-separated kinds of malformed event to send, or all: "+strings.Join(malformed.Kinds, ", "))
+	flag.Float64Var(&opts.malformedRatio, "malformed-ratio", 1,
+		"Fraction of the messages that are malformed events, when -malformed is set")
+
+	// Define flags for injecting duplicate, delayed and out of order messages
+	flag.Float64Var(&opts.duplicateRatio, "duplicate-ratio", 0,
+		"Fraction of the messages to send twice")
+	flag.Float64Var(&opts.delayRatio, "delay-ratio", 0,
+		"Fraction of the messages to hold back for a random time up to -max-delay before sending")
+	flag.DurationVar(&opts.maxDelay, "max-delay", time.Second,
+		"Longest time to hold back a delayed message")
+	flag.Float64Var(&opts.pairRatio, "pair-ratio", 0,
+		"Fraction of the search-content-updated messages to pair with a search-content-deleted message for the same URI")
+	flag.BoolVar(&opts.shufflePairs, "shuffle-pairs", true,
+		"Send each pair in a random order, so that some deletes arrive before their updates")
+
+	// Define flags for verifying that the messages are consumed
+	flag.BoolVar(&opts.verify, "verify", false,
+		"Consume the verification topics and report the end-to-end lag and missing or duplicated messages")
+	flag.StringVar(&opts.verifyTopics, "verify-topics", "",
+		"Comma-separated topics to consume the messages from (defaults to the topics they are sent to)")
+	flag.StringVar(&opts.verifyGroup, "verify-group", "",
+		"Consumer group of the verification consumer (defaults to KAFKA_CONTENT_UPDATED_GROUP)")
+	flag.DurationVar(&opts.verifyTimeout, "verify-timeout", defaultVerifyTimeout,
+		"Time to wait for the verification consumer to join its group, and for the messages to be consumed")
 	flag.Parse()
 
+	return opts
+}
+
+func (opts loadOptions) validate() error {
+	if opts.numContentUpdated < 0 || opts.numSearchContentUpdated < 0 || opts.numSearchContentDeleted < 0 {
+		return errors.New("message numbers must not be negative")
+	}
+	if opts.duration < 0 {
+		return fmt.Errorf("duration must not be negative: %v", opts.duration)
+	}
+	if opts.workers < 1 {
+		return fmt.Errorf("workers must be at least 1: %d", opts.workers)
+	}
+	ratios := map[string]float64{
+		"malformed-ratio": opts.malformedRatio,
+		"duplicate-ratio": opts.duplicateRatio,
+		"delay-ratio":     opts.delayRatio,
+		"pair-ratio":      opts.pairRatio,
+	}
+	for name, ratio := range ratios {
+		if ratio < 0 || ratio > 1 {
+			return fmt.Errorf("%s must be between 0 and 1: %v", name, ratio)
+		}
+	}
+	if opts.delayRatio > 0 && opts.maxDelay <= 0 {
+		return fmt.Errorf("max-delay must be positive to delay messages: %v", opts.maxDelay)
+	}
+	if opts.verify && opts.capture != "" {
+		return errors.New("captured messages cannot be verified, as they are not sent to kafka")
+	}
+	if opts.verifyTimeout <= 0 {
+		return fmt.Errorf("verify-timeout must be positive: %v", opts.verifyTimeout)
+	}
+	return nil
+}
+
+func buildTargets(ctx context.Context, cfg *config.Config, opts loadOptions, res resources) []*target {
+	targets := []*target{
+		{topic: cfg.Kafka.ContentUpdatedTopic, items: res.contentUpdatedResources.Items, weight: opts.numContentUpdated},
+		{topic: cfg.Kafka.SearchContentUpdatedTopic, items: res.searchContentUpdatedResources.Items, weight: opts.numSearchContentUpdated},
+		{topic: cfg.Kafka.SearchContentDeletedTopic, items: res.searchContentDeletedResources.Items, weight: opts.numSearchContentDeleted},
+	}
+
+	// Guard: avoid modulo by zero if caller asked for >0, but we have no resources
+	for _, t := range targets {
+		if t.weight > 0 && len(t.items) == 0 {
+			log.Error(ctx, "no resources available for topic but requested count > 0", nil, log.Data{"topic": t.topic})
+			t.weight = 0
+		}
+	}
+	return targets
+}
+
+func main() {
+	// keep main tiny to satisfy gocyclo
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	log.Namespace = serviceName
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := parseFlags()
+	if err := opts.validate(); err != nil {
+		log.Error(ctx, "invalid load test options", err)
+		return err
+	}
+	pace, err := newPacer(opts.profile, opts.rate, opts.rampUp, opts.steps)
+	if err != nil {
+		log.Error(ctx, "invalid load test options", err)
+		return err
+	}
+	strategy, err := partitioning.New(opts.keyBy, opts.partition)
+	if err != nil {
+		log.Error(ctx, "invalid load test options", err)
+		return err
+	}
+
 	// Get Config
 	cfg, err := config.Get()
 	if err != nil {
 		log.Error(ctx, "error getting config", err)
-		os.Exit(1)
+		return err
 	}
 
 	log.Info(ctx, "Script config", log.Data{"cfg": cfg})
 
-	prods, err := buildAndInitProducers(ctx, cfg)
+	// Set up OpenTelemetry, if enabled, to export the spans of the messages sent
+	otelShutdown, err := headers.SetupOTel(ctx, cfg)
 	if err != nil {
-		log.Error(ctx, "failed to create/initialise producers", err)
-		os.Exit(1)
+		log.Error(ctx, "error setting up OpenTelemetry - hint: ensure OTEL_EXPORTER_OTLP_ENDPOINT is set", err)
+		return err
+	}
+	defer func() {
+		if err := otelShutdown(context.Background()); err != nil {
+			log.Error(ctx, "failed to shut down OpenTelemetry", err)
+		}
+	}()
+
+	encoder, err := events.NewEncoder(cfg.Kafka)
+	if err != nil {
+		log.Error(ctx, "failed to create event encoder", err)
+		return err
+	}
+
+	p, err := newPoison(cfg, opts, encoder)
+	if err != nil {
+		log.Error(ctx, "invalid load test options", err)
+		return err
 	}
-	log.Info(ctx, "kafka producers initialised", log.Data{})
+
+	producer, err := createProducer(cfg, opts, strategy, p)
+	if err != nil {
+		log.Error(ctx, "failed to create producer", err)
+		return err
+	}
+	log.Info(ctx, "producer initialised", log.Data{"capture": opts.capture})
+
+	// Close producer to release resources
+	defer func() {
+		if err := producer.Close(); err != nil {
+			log.Error(ctx, "error closing producer", err)
+		}
+	}()
 
 	res, err := fetchAllResources(ctx)
 	if err != nil {
 		log.Error(ctx, "failed to fetch resources", err)
-		os.Exit(1)
+		return err
 	}
 
-	// Validate message numbers
-	if numContentUpdated < 0 || numSearchContentUpdated < 0 || numSearchContentDeleted < 0 {
-		log.Error(ctx, "invalid message numbers for topics", nil)
-		os.Exit(1)
+	targets := buildTargets(ctx, cfg, opts, res)
+	m := newMix(targets)
+	in := newInjector(cfg, opts, targets)
+	if m.total == 0 {
+		log.Info(ctx, "no messages to send", log.Data{})
+		return nil
 	}
 
-	// Guard: avoid modulo by zero if caller asked for >0, but we have no resources
-	if numContentUpdated > 0 && len(res.contentUpdatedResources.Items) == 0 {
-		log.Error(ctx, "no content-updated resources available but requested legacy count > 0", nil)
-		numContentUpdated = 0
+	// Send each message once, or keep sending the mix of messages until the duration is up
+	limit := m.total
+	dispatchCtx := ctx
+	if opts.duration > 0 {
+		limit = -1
+		var cancel context.CancelFunc
+		dispatchCtx, cancel = context.WithTimeout(ctx, opts.duration)
+		defer cancel()
 	}
-	if numSearchContentUpdated > 0 && len(res.searchContentUpdatedResources.Items) == 0 {
-		log.Error(ctx, "no search-content-updated resources available but requested new count > 0", nil)
-		numSearchContentUpdated = 0
+
+	log.Info(ctx, "sending messages", log.Data{
+		"rate":            opts.rate,
+		"duration":        opts.duration.String(),
+		"ramp_up":         opts.rampUp.String(),
+		"profile":         opts.profile,
+		"workers":         opts.workers,
+		"key_by":          strategy.Key,
+		"malformed":       opts.malformed,
+		"duplicate_ratio": opts.duplicateRatio,
+		"delay_ratio":     opts.delayRatio,
+		"pair_ratio":      opts.pairRatio,
+	})
+
+	// Start consuming before sending, so that no messages are missed
+	var t *tracker
+	var v *verifier
+	if opts.verify {
+		t = newTracker()
+		v, err = startVerifier(ctx, cfg, opts, sentTopics(m, in), t)
+		if err != nil {
+			log.Error(ctx, "failed to start verification consumer", err)
+			return err
+		}
+		defer func() {
+			if err := v.close(); err != nil {
+				log.Error(ctx, "error closing verification consumer", err)
+			}
+		}()
+	}
+
+	start := time.Now()
+	jobs := make(chan job, opts.workers)
+	go dispatch(dispatchCtx, m, pace, in, limit, jobs)
+	runWorkers(ctx, opts.workers, &sender{producer: producer, encoder: encoder, partitioning: strategy, tracker: t, poison: p, injector: in}, jobs)
+	elapsed := time.Since(start)
+
+	r := newReport(targets, opts.rate, start, elapsed)
+	r.Injected = in.report()
+	if opts.verify {
+		log.Info(ctx, "waiting for messages to be consumed", log.Data{"topics": v.topics, "outstanding": t.outstanding()})
+		t.wait(ctx, opts.verifyTimeout)
+		r.Verification = t.report(v.topics)
+	}
+	log.Info(ctx, "done sending messages", log.Data{
+		"sent":       r.Sent,
+		"failed":     r.Failed,
+		"elapsed":    elapsed.String(),
+		"throughput": r.Throughput,
+		"latency":    r.Latency,
+	})
+	if err := writeReports(r, opts); err != nil {
+		log.Error(ctx, "failed to write report", err)
+		return err
+	}
+
+	if r.Failed > 0 {
+		return fmt.Errorf("failed to deliver %d messages", r.Failed)
 	}
-	if numSearchContentDeleted > 0 && len(res.searchContentDeletedResources.Items) == 0 {
-		log.Error(ctx, "no search-content-deleted resources available but requested deleted count > 0", nil)
-		numSearchContentDeleted = 0
+	if r.Verification != nil && r.Verification.Missing > 0 {
+		return fmt.Errorf("%d messages were not consumed", r.Verification.Missing)
 	}
+	return nil
+}
+
+// newPoison returns the poison that decides which messages are malformed events, or nil if none are
+func newPoison(cfg *config.Config, opts loadOptions, encoder *events.Encoder) (*poison, error) {
+	if opts.malformed == "" {
+		return nil, nil
+	}
+
+	kinds, err := malformed.ParseKinds(opts.malformed)
+	if err != nil {
+		return nil, err
+	}
+	return &poison{
+		generator: &malformed.Generator{Encoder: encoder, MaxBytes: cfg.Kafka.MaxBytes},
+		kinds:     kinds,
+		ratio:     opts.malformedRatio,
+	}, nil
+}
 
-	// Create a WaitGroup to wait for all goroutines to finish
-	var wg sync.WaitGroup
+// newInjector returns the injector that decides which messages are duplicated, delayed or paired, or nil if none are
+func newInjector(cfg *config.Config, opts loadOptions, targets []*target) *injector {
+	if opts.duplicateRatio == 0 && opts.delayRatio == 0 && opts.pairRatio == 0 {
+		return nil
+	}
 
-	// Send messages to legacy topic concurrently
-	for i := 0; i < numContentUpdated; i++ {
-		item := &res.contentUpdatedResources.Items[i%len(res.contentUpdatedResources.Items)]
-		wg.Add(1)
-		go sendMessageToKafka(prods.ContentUpdatedProducer, *item, i, &wg)
+	in := &injector{
+		duplicateRatio: opts.duplicateRatio,
+		delayRatio:     opts.delayRatio,
+		maxDelay:       opts.maxDelay,
+		pairRatio:      opts.pairRatio,
+		shuffle:        opts.shufflePairs,
+	}
+	if opts.pairRatio > 0 {
+		for _, t := range targets {
+			if t.topic == cfg.Kafka.SearchContentDeletedTopic {
+				in.deleted = t
+			}
+		}
 	}
+	return in
+}
 
-	// Send messages to search-content-updated concurrently
-	for i := 0; i < numSearchContentUpdated; i++ {
-		item := &res.searchContentUpdatedResources.Items[i%len(res.searchContentUpdatedResources.Items)]
-		wg.Add(1)
-		go sendMessageToKafka(prods.SearchContentUpdatedProducer, *item, i, &wg)
+// sentTopics returns the topics that messages are sent to, including the topic of the deletes of any pairs
+func sentTopics(m *mix, in *injector) []string {
+	var topics []string
+	for _, t := range m.targets {
+		topics = append(topics, t.topic)
+	}
+	if in != nil && in.deleted != nil && in.deleted.weight == 0 {
+		topics = append(topics, in.deleted.topic)
 	}
+	return topics
+}
 
-	// Send messages to search-content-deleted concurrently
-	for i := 0; i < numSearchContentDeleted; i++ {
-		item := &res.searchContentDeletedResources.Items[i%len(res.searchContentDeletedResources.Items)]
-		wg.Add(1)
-		go sendMessageToKafka(prods.SearchContentDeletedProducer, *item, i, &wg)
+// startVerifier starts consuming the verification topics, which default to the topics that messages are sent to
+func startVerifier(ctx context.Context, cfg *config.Config, opts loadOptions, topics []string, t *tracker) (*verifier, error) {
+	if opts.verifyTopics != "" {
+		topics = nil
+		for _, topic := range strings.Split(opts.verifyTopics, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, topic)
+			}
+		}
 	}
 
-	// Wait for all messages to be processed
-	wg.Wait()
+	group := opts.verifyGroup
+	if group == "" {
+		group = cfg.Kafka.ContentUpdatedGroup
+	}
 
-	// Close producers to flush and release resources
-	if err := prods.ContentUpdatedProducer.Close(ctx); err != nil {
-		log.Error(ctx, "error closing legacy kafka producer", err)
+	v, err := newVerifier(cfg, group, topics, t)
+	if err != nil {
+		return nil, err
 	}
-	if err := prods.SearchContentUpdatedProducer.Close(ctx); err != nil {
-		log.Error(ctx, "error closing updated kafka producer", err)
+	if err := v.start(ctx, opts.verifyTimeout); err != nil {
+		_ = v.close()
+		return nil, err
 	}
-	if err := prods.SearchContentDeletedProducer.Close(ctx); err != nil {
-		log.Error(ctx, "error closing deleted kafka producer", err)
+
+	log.Info(ctx, "verification consumer ready", log.Data{"topics": topics, "group": group})
+	return v, nil
+}
+
+// writeReports writes the report of the run to the files given by the flags
+func writeReports(r report, opts loadOptions) error {
+	if opts.reportJSON != "" {
+		if err := r.writeJSON(opts.reportJSON); err != nil {
+			return err
+		}
 	}
 
-	log.Info(ctx, "done sending messages", log.Data{})
+	switch opts.reportText {
+	case "":
+		return nil
+	case "-":
+		return r.writeText(os.Stdout)
+	default:
+		f, err := os.Create(opts.reportText)
+		if err != nil {
+			return err
+		}
+		if err := r.writeText(f); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/malformed"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/partitioning"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/sink"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+)
+
+const (
+	contentUpdatedTopic       = "content-updated"
+	searchContentUpdatedTopic = "search-content-updated"
+	searchContentDeletedTopic = "search-content-deleted"
+)
+
+var (
+	contentUpdated       = models.ContentUpdatedResource{URI: "/economy", CollectionID: "COLLECTIONID", TraceID: "fixture-trace"}
+	searchContentUpdated = models.SearchContentUpdatedResource{URI: "/releases/a-release", Title: "A release"}
+)
+
+// newTestSender returns a sender that captures its messages, with Avro content-updated events and JSON search events
+func newTestSender(keyBy string) (*sender, *sink.Capture) {
+	encoder, err := events.NewEncoder(&config.Kafka{
+		ContentUpdatedTopic:       contentUpdatedTopic,
+		SearchContentUpdatedTopic: searchContentUpdatedTopic,
+		SearchContentDeletedTopic: searchContentDeletedTopic,
+		ContentUpdatedEncoding:    events.EncodingAvro,
+	})
+	So(err, ShouldBeNil)
+	strategy, err := partitioning.New(keyBy, partitioning.AnyPartition)
+	So(err, ShouldBeNil)
+
+	capture := sink.NewCapture(nil)
+	return &sender{producer: capture, encoder: encoder, partitioning: strategy}, capture
+}
+
+func TestSendMessageToKafka(t *testing.T) {
+	Convey("Given a sender keying messages by URI", t, func() {
+		s, capture := newTestSender(partitioning.KeyURI)
+
+		Convey("When a content-updated resource is sent", func() {
+			_, err := s.sendMessageToKafka(contentUpdatedTopic, contentUpdated, 3, "", false)
+			So(err, ShouldBeNil)
+
+			Convey("Then it is sent as an Avro event with its trace ID, key and headers", func() {
+				messages := capture.Messages()
+				So(messages, ShouldHaveLength, 1)
+				So(messages[0].Topic, ShouldEqual, contentUpdatedTopic)
+				So(messages[0].Key, ShouldEqual, "/economy")
+				So(messages[0].Headers[events.HeaderEventType], ShouldEqual, events.ContentPublishedEvent)
+				So(messages[0].Headers[events.HeaderContentType], ShouldEqual, events.ContentTypeAvro)
+
+				var r models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(messages[0].Value, &r), ShouldBeNil)
+				So(r, ShouldResemble, contentUpdated)
+			})
+		})
+
+		Convey("When a search-content-updated resource without a trace ID is sent", func() {
+			_, err := s.sendMessageToKafka(searchContentUpdatedTopic, searchContentUpdated, 3, "", false)
+			So(err, ShouldBeNil)
+
+			Convey("Then it is sent as a JSON event with a stub trace ID", func() {
+				messages := capture.Messages()
+				So(messages, ShouldHaveLength, 1)
+				So(messages[0].Headers[events.HeaderContentType], ShouldEqual, events.ContentTypeJSON)
+
+				var r models.SearchContentUpdatedResource
+				So(json.Unmarshal(messages[0].Value, &r), ShouldBeNil)
+				So(r.URI, ShouldEqual, searchContentUpdated.URI)
+				So(r.TraceID, ShouldEndWith, "-3")
+				So(traceIDPattern.MatchString(r.TraceID), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a resource is sent as a duplicate", func() {
+			_, err := s.sendMessageToKafka(contentUpdatedTopic, contentUpdated, 3, "", true)
+			So(err, ShouldBeNil)
+
+			Convey("Then the same message is sent twice", func() {
+				messages := capture.Messages()
+				So(messages, ShouldHaveLength, 2)
+				So(messages[1].Value, ShouldResemble, messages[0].Value)
+				So(messages[1].Key, ShouldEqual, messages[0].Key)
+				So(messages[1].Headers, ShouldResemble, messages[0].Headers)
+				So(messages[1].Offset, ShouldEqual, 1)
+			})
+		})
+	})
+
+	Convey("Given a sender that tracks and poisons messages", t, func() {
+		s, capture := newTestSender(partitioning.KeyNone)
+		s.tracker = newTracker()
+		s.poison = &poison{generator: &malformed.Generator{Encoder: s.encoder}, kinds: []string{malformed.Empty}, ratio: 1}
+
+		Convey("When a malformed event is sent", func() {
+			_, err := s.sendMessageToKafka(contentUpdatedTopic, contentUpdated, 0, malformed.Empty, false)
+			So(err, ShouldBeNil)
+
+			Convey("Then its payload is malformed and it is not expected to be consumed", func() {
+				messages := capture.Messages()
+				So(messages, ShouldHaveLength, 1)
+				So(messages[0].Value, ShouldBeEmpty)
+				So(s.tracker.outstanding(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When a well-formed event is sent", func() {
+			_, err := s.sendMessageToKafka(contentUpdatedTopic, contentUpdated, 0, "", false)
+			So(err, ShouldBeNil)
+
+			Convey("Then it is sent with a unique trace ID that is expected to be consumed", func() {
+				var r models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(capture.Messages()[0].Value, &r), ShouldBeNil)
+				So(r.TraceID, ShouldNotEqual, contentUpdated.TraceID)
+				So(s.tracker.outstanding(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When a well-formed event is sent as a duplicate", func() {
+			_, err := s.sendMessageToKafka(contentUpdatedTopic, contentUpdated, 0, "", true)
+			So(err, ShouldBeNil)
+
+			Convey("Then the tracker expects its second receipt", func() {
+				var r models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(capture.Messages()[0].Value, &r), ShouldBeNil)
+				So(s.tracker.resent, ShouldResemble, map[string]int{r.TraceID: 1})
+			})
+		})
+	})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Ramp-up profiles, which describe how the rate rises to its target during the ramp-up period
+const (
+	profileConstant = "constant"
+	profileLinear   = "linear"
+	profileStep     = "step"
+)
+
+// minimumRate stops ramps that start from zero from waiting forever for their first message
+const minimumRate = 1.0
+
+// pacer spaces messages out so that they are sent at a target rate, optionally ramping up to it
+type pacer struct {
+	profile string
+	rate    float64 // messages per second, unlimited if zero
+	rampUp  time.Duration
+	steps   int
+	start   time.Time
+	next    time.Time
+}
+
+func newPacer(profile string, rate float64, rampUp time.Duration, steps int) (*pacer, error) {
+	switch profile {
+	case profileConstant, profileLinear, profileStep:
+	default:
+		return nil, fmt.Errorf("unknown ramp-up profile %q: must be %s, %s or %s", profile, profileConstant, profileLinear, profileStep)
+	}
+	if rate < 0 {
+		return nil, fmt.Errorf("rate must not be negative: %v", rate)
+	}
+	if rampUp < 0 {
+		return nil, fmt.Errorf("ramp-up must not be negative: %v", rampUp)
+	}
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1: %d", steps)
+	}
+
+	return &pacer{profile: profile, rate: rate, rampUp: rampUp, steps: steps}, nil
+}
+
+// rateAt returns the target rate once the load test has been running for elapsed
+func (p *pacer) rateAt(elapsed time.Duration) float64 {
+	if p.profile == profileConstant || p.rampUp == 0 || elapsed >= p.rampUp {
+		return p.rate
+	}
+
+	progress := float64(elapsed) / float64(p.rampUp)
+	if p.profile == profileStep {
+		steps := float64(p.steps)
+		progress = (math.Floor(progress*steps) + 1) / steps
+	}
+	return math.Max(p.rate*progress, minimumRate)
+}
+
+// wait blocks until the next message is due, or the context is done. Time lost while the workers were busy is not
+// made up with a burst of messages, so the achieved rate drops below the target when the workers cannot keep up.
+func (p *pacer) wait(ctx context.Context) error {
+	if p.rate == 0 {
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	if p.start.IsZero() {
+		p.start = now
+	}
+	if p.next.Before(now) {
+		p.next = now
+	}
+
+	if delay := p.next.Sub(now); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	interval := time.Duration(float64(time.Second) / p.rateAt(p.next.Sub(p.start)))
+	p.next = p.next.Add(interval)
+	return ctx.Err()
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/malformed"
+)
+
+// poison decides which of the load test's messages are malformed, spreading them evenly through the run
+type poison struct {
+	generator *malformed.Generator
+	kinds     []string
+	ratio     float64
+}
+
+// kindFor returns the kind of malformed event to send as the message at index, or an empty string if the message is
+// well-formed. A ratio of the messages are malformed, and each malformed message is the next of the kinds in turn.
+func (p *poison) kindFor(index int) string {
+	if p == nil || len(p.kinds) == 0 {
+		return ""
+	}
+
+	n, selected := spread(index, p.ratio)
+	if !selected {
+		return ""
+	}
+	return p.kinds[n%len(p.kinds)]
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// target is a topic that the load test sends messages to, with the resources it cycles through
+type target struct {
+	topic   string
+	items   []models.Resource
+	weight  int
+	current int // smooth weighted round robin state
+	queued  int
+	stats   deliveryStats
+}
+
+// job is a message for a worker to send
+type job struct {
+	target    *target
+	item      models.Resource
+	index     int
+	duplicate bool          // re-send the message once it has been acknowledged
+	delay     time.Duration // wait before sending the message
+	then      *job          // a message to send straight after this one, such as the other half of a pair
+}
+
+// mix interleaves the targets in proportion to their weights, using smooth weighted round robin so that the topics
+// are spread evenly over time rather than sent in batches
+type mix struct {
+	targets []*target
+	total   int
+}
+
+func newMix(targets []*target) *mix {
+	m := &mix{}
+	for _, t := range targets {
+		if t.weight > 0 {
+			m.targets = append(m.targets, t)
+			m.total += t.weight
+		}
+	}
+	return m
+}
+
+// next returns the target of the next message. Each cycle of total messages sends weight messages to each target.
+func (m *mix) next() *target {
+	var selected *target
+	for _, t := range m.targets {
+		t.current += t.weight
+		if selected == nil || t.current > selected.current {
+			selected = t
+		}
+	}
+	selected.current -= m.total
+	return selected
+}
+
+// dispatch queues paced jobs for the workers until limit jobs have been queued, or until the context is done if
+// limit is negative, and returns the number of jobs queued. Messages injected alongside the jobs, such as the deletes
+// of pairs, do not count towards the limit.
+func dispatch(ctx context.Context, m *mix, p *pacer, in *injector, limit int, jobs chan<- job) int {
+	defer close(jobs)
+
+	queued, index := 0, 0
+	for limit < 0 || queued < limit {
+		if err := p.wait(ctx); err != nil {
+			return queued
+		}
+
+		t := m.next()
+		j, next := in.inject(&job{target: t, item: t.items[t.queued%len(t.items)], index: index}, index+1)
+		select {
+		case jobs <- *j:
+			t.queued++
+			queued++
+			index = next
+		case <-ctx.Done():
+			return queued
+		}
+	}
+	return queued
+}
+
+// runWorkers sends the queued jobs with a bounded number of workers, returning when all the jobs have been
+// acknowledged by kafka or have failed
+func runWorkers(ctx context.Context, workers int, s *sender, jobs <-chan job) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				s.send(ctx, j)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// send sends the message of a job after its delay, followed by any message sent with it, recording the outcome of each
+func (s *sender) send(ctx context.Context, j job) {
+	if j.delay > 0 {
+		s.injector.wait(ctx, j.delay)
+	}
+
+	delivered := true
+	for m := &j; m != nil; m = m.then {
+		kind := s.poison.kindFor(m.index)
+		latency, err := s.sendMessageToKafka(m.target.topic, m.item, m.index, kind, m.duplicate)
+		if err != nil {
+			log.Error(ctx, "failed to send message", err, log.Data{"topic": m.target.topic, "malformed": kind})
+			m.target.stats.failure(err)
+			delivered = false
+			continue
+		}
+		m.target.stats.delivered(latency, kind)
+		if m.duplicate {
+			s.injector.duplicated()
+		}
+	}
+
+	if j.then != nil && delivered {
+		s.injector.paired(&j)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/partitioning"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/sink"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// sendAll dispatches limit jobs for the targets to the workers of a sender, returning the number of jobs queued
+func sendAll(s *sender, targets []*target, in *injector, limit int) int {
+	p, err := newPacer(profileConstant, 0, 0, defaultSteps)
+	So(err, ShouldBeNil)
+
+	jobs := make(chan job, defaultWorkers)
+	queued := make(chan int, 1)
+	go func() { queued <- dispatch(context.Background(), newMix(targets), p, in, limit, jobs) }()
+	runWorkers(context.Background(), defaultWorkers, s, jobs)
+	return <-queued
+}
+
+func countByTopic(messages []sink.Message) map[string]int {
+	counts := map[string]int{}
+	for _, m := range messages {
+		counts[m.Topic]++
+	}
+	return counts
+}
+
+func TestMix(t *testing.T) {
+	Convey("Given targets weighted 3 to 1", t, func() {
+		heavy := &target{topic: "heavy", weight: 3}
+		light := &target{topic: "light", weight: 1}
+		m := newMix([]*target{heavy, light, {topic: "unused"}})
+
+		Convey("When the mix is cycled through", func() {
+			var topics []string
+			for i := 0; i < 8; i++ {
+				topics = append(topics, m.next().topic)
+			}
+
+			Convey("Then the topics are interleaved in proportion to their weights", func() {
+				So(m.total, ShouldEqual, 4)
+				So(topics, ShouldResemble, []string{"heavy", "heavy", "light", "heavy", "heavy", "heavy", "light", "heavy"})
+			})
+		})
+	})
+}
+
+func TestFanOut(t *testing.T) {
+	Convey("Given a sender that captures its messages and targets for each topic", t, func() {
+		s, capture := newTestSender(partitioning.KeyNone)
+		targets := []*target{
+			{topic: contentUpdatedTopic, items: []models.Resource{contentUpdated}, weight: 20},
+			{topic: searchContentUpdatedTopic, items: []models.Resource{searchContentUpdated}, weight: 10},
+			{topic: searchContentDeletedTopic, items: []models.Resource{models.SearchContentDeletedResource{URI: "/a"}}},
+		}
+
+		Convey("When each message of the mix is sent once", func() {
+			queued := sendAll(s, targets, nil, 30)
+
+			Convey("Then every message is sent to its topic and recorded", func() {
+				So(queued, ShouldEqual, 30)
+				So(countByTopic(capture.Messages()), ShouldResemble, map[string]int{contentUpdatedTopic: 20, searchContentUpdatedTopic: 10})
+
+				r := newReport(targets, 0, capture.Messages()[0].Timestamp, 1)
+				So(r.Sent, ShouldEqual, 30)
+				So(r.Failed, ShouldEqual, 0)
+				So(r.Topics[0].Sent, ShouldEqual, 20)
+				So(r.Topics[2].Sent, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When duplicates and in order pairs are injected", func() {
+			in := &injector{duplicateRatio: 0.1, pairRatio: 0.5, deleted: targets[2]}
+			s.injector = in
+			sendAll(s, targets, in, 30)
+
+			Convey("Then the duplicates are sent again and each pair's delete follows its update", func() {
+				r := in.report()
+				So(r.Duplicates, ShouldBeGreaterThan, 0)
+				So(r.Pairs, ShouldEqual, 5)
+				So(r.ReversedPairs, ShouldEqual, 0)
+
+				messages := capture.Messages()
+				So(messages, ShouldHaveLength, 30+r.Duplicates+r.Pairs)
+				So(countByTopic(messages)[searchContentDeletedTopic], ShouldEqual, 5)
+				So(targets[2].stats.latencies, ShouldHaveLength, 5)
+
+				updates, deletes := 0, 0
+				for _, m := range messages {
+					switch m.Topic {
+					case searchContentUpdatedTopic:
+						updates++
+					case searchContentDeletedTopic:
+						deletes++
+						So(updates, ShouldBeGreaterThanOrEqualTo, deletes)
+					}
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/malformed"
+)
+
+// deliveryStats records the outcome of each message sent to a topic
+type deliveryStats struct {
+	mutex     sync.Mutex
+	latencies []time.Duration
+	failed    int
+	errors    map[string]int
+	malformed map[string]int
+}
+
+// delivered records a message acknowledged by kafka after latency, which was a malformed event if kind is set
+func (s *deliveryStats) delivered(latency time.Duration, kind string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.latencies = append(s.latencies, latency)
+	if kind != "" {
+		if s.malformed == nil {
+			s.malformed = map[string]int{}
+		}
+		s.malformed[kind]++
+	}
+}
+
+// failure records a message that was not acknowledged by kafka
+func (s *deliveryStats) failure(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failed++
+	if s.errors == nil {
+		s.errors = map[string]int{}
+	}
+	s.errors[err.Error()]++
+}
+
+// report describes the outcome of a load test run
+type report struct {
+	Started        time.Time           `json:"started"`
+	ElapsedSeconds float64             `json:"elapsed_seconds"`
+	TargetRate     float64             `json:"target_rate"`
+	Throughput     float64             `json:"throughput"`
+	Sent           int                 `json:"sent"`
+	Failed         int                 `json:"failed"`
+	Malformed      int                 `json:"malformed"`
+	Latency        latencySummary      `json:"latency"`
+	Topics         []topicReport       `json:"topics"`
+	Injected       *injectionReport    `json:"injected,omitempty"`
+	Verification   *verificationReport `json:"verification,omitempty"`
+}
+
+// topicReport describes the messages sent to one topic
+type topicReport struct {
+	Topic     string         `json:"topic"`
+	Sent      int            `json:"sent"`
+	Failed    int            `json:"failed"`
+	Latency   latencySummary `json:"latency"`
+	Errors    map[string]int `json:"errors,omitempty"`
+	Malformed map[string]int `json:"malformed,omitempty"`
+}
+
+// latencySummary holds percentiles of the time taken for kafka to acknowledge messages, in milliseconds
+type latencySummary struct {
+	P50 float64 `json:"p50_ms"`
+	P95 float64 `json:"p95_ms"`
+	P99 float64 `json:"p99_ms"`
+	Max float64 `json:"max_ms"`
+}
+
+// newReport summarises the messages sent to the targets by a run that started at started and took elapsed
+func newReport(targets []*target, targetRate float64, started time.Time, elapsed time.Duration) report {
+	r := report{
+		Started:        started.UTC(),
+		ElapsedSeconds: round(elapsed.Seconds()),
+		TargetRate:     targetRate,
+	}
+
+	var all []time.Duration
+	for _, t := range targets {
+		t.stats.mutex.Lock()
+		latencies := append([]time.Duration(nil), t.stats.latencies...)
+		tr := topicReport{
+			Topic:   t.topic,
+			Sent:    len(latencies),
+			Failed:  t.stats.failed,
+			Latency: summarise(latencies),
+			Errors:  t.stats.errors,
+		}
+		if len(t.stats.malformed) > 0 {
+			tr.Malformed = map[string]int{}
+			for kind, count := range t.stats.malformed {
+				tr.Malformed[kind] = count
+				r.Malformed += count
+			}
+		}
+		t.stats.mutex.Unlock()
+
+		r.Sent += tr.Sent
+		r.Failed += tr.Failed
+		r.Topics = append(r.Topics, tr)
+		all = append(all, latencies...)
+	}
+
+	r.Latency = summarise(all)
+	if elapsed > 0 {
+		r.Throughput = round(float64(r.Sent) / elapsed.Seconds())
+	}
+	return r
+}
+
+// summarise returns the percentiles of the latencies, using the nearest rank
+func summarise(latencies []time.Duration) latencySummary {
+	if len(latencies) == 0 {
+		return latencySummary{}
+	}
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		return milliseconds(sorted[max(rank, 0)])
+	}
+	return latencySummary{
+		P50: percentile(50),
+		P95: percentile(95),
+		P99: percentile(99),
+		Max: milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return round(float64(d) / float64(time.Millisecond))
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// writeJSON writes the report as JSON to the file at path
+func (r report) writeJSON(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+// writeText writes the report as human-readable text
+func (r report) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Load test report\n\n")
+	fmt.Fprintf(tw, "Started:\t%s\n", r.Started.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Elapsed:\t%.2fs\n", r.ElapsedSeconds)
+	if r.TargetRate > 0 {
+		fmt.Fprintf(tw, "Target rate:\t%.2f msg/s\n", r.TargetRate)
+	} else {
+		fmt.Fprintf(tw, "Target rate:\tunlimited\n")
+	}
+	fmt.Fprintf(tw, "Throughput:\t%.2f msg/s\n", r.Throughput)
+	fmt.Fprintf(tw, "Sent:\t%d\n", r.Sent)
+	fmt.Fprintf(tw, "Failed:\t%d\n", r.Failed)
+	fmt.Fprintf(tw, "Malformed:\t%d\n\n", r.Malformed)
+
+	fmt.Fprintf(tw, "Topic\tSent\tFailed\tp50 (ms)\tp95 (ms)\tp99 (ms)\tmax (ms)\n")
+	row := func(topic string, sent, failed int, l latencySummary) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f\t%.2f\t%.2f\t%.2f\n", topic, sent, failed, l.P50, l.P95, l.P99, l.Max)
+	}
+	for _, t := range r.Topics {
+		row(t.Topic, t.Sent, t.Failed, t.Latency)
+	}
+	row("all", r.Sent, r.Failed, r.Latency)
+
+	if in := r.Injected; in != nil {
+		fmt.Fprintf(tw, "\nInjected\n\n")
+		fmt.Fprintf(tw, "Duplicates:\t%d\n", in.Duplicates)
+		fmt.Fprintf(tw, "Delayed:\t%d (max %.2f ms)\n", in.Delayed, in.MaxDelay)
+		fmt.Fprintf(tw, "Pairs:\t%d (%d reversed)\n", in.Pairs, in.ReversedPairs)
+	}
+
+	if v := r.Verification; v != nil {
+		fmt.Fprintf(tw, "\nVerification (%s)\n\n", strings.Join(v.Topics, ", "))
+		fmt.Fprintf(tw, "Expected:\t%d\n", v.Expected)
+		fmt.Fprintf(tw, "Received:\t%d\n", v.Received)
+		fmt.Fprintf(tw, "Missing:\t%d\n", v.Missing)
+		fmt.Fprintf(tw, "Expected duplicates:\t%d\n", v.ExpectedDuplicates)
+		fmt.Fprintf(tw, "Unexpected duplicates:\t%d\n\n", v.UnexpectedDuplicates)
+
+		fmt.Fprintf(tw, "Topic\tReceived\tlag p50 (ms)\tlag p95 (ms)\tlag p99 (ms)\tlag max (ms)\n")
+		lagRow := func(topic string, received int, l latencySummary) {
+			fmt.Fprintf(tw, "%s\t%d\t%.2f\t%.2f\t%.2f\t%.2f\n", topic, received, l.P50, l.P95, l.P99, l.Max)
+		}
+		for _, t := range v.TopicLags {
+			lagRow(t.Topic, t.Received, t.Lag)
+		}
+		lagRow("all", v.Received, v.Lag)
+
+		if len(v.MissingTraceIDs) > 0 {
+			fmt.Fprintf(tw, "\nMissing trace IDs (first %d):\n", len(v.MissingTraceIDs))
+			for _, traceID := range v.MissingTraceIDs {
+				fmt.Fprintf(tw, "  %s\n", traceID)
+			}
+		}
+	}
+
+	for _, t := range r.Topics {
+		if len(t.Malformed) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\nMalformed events sent to %s:\n", t.Topic)
+		for _, kind := range malformed.Kinds {
+			if t.Malformed[kind] > 0 {
+				fmt.Fprintf(tw, "  %d x %s\n", t.Malformed[kind], kind)
+			}
+		}
+	}
+
+	for _, t := range r.Topics {
+		if len(t.Errors) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\nErrors sending to %s:\n", t.Topic)
+		messages := make([]string, 0, len(t.Errors))
+		for message := range t.Errors {
+			messages = append(messages, message)
+		}
+		sort.Strings(messages)
+		for _, message := range messages {
+			fmt.Fprintf(tw, "  %d x %s\n", t.Errors[message], message)
+		}
+	}
+
+	return tw.Flush()
+}
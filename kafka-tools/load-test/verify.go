@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	kafka "github.com/ONSdigital/dp-kafka/v4"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/Shopify/sarama"
+)
+
+// maxListedMissing limits the trace IDs of missing messages listed in the report
+const maxListedMissing = 20
+
+// tracker correlates the messages sent by the load test with the messages consumed from the verification topics by
+// their trace ID
+type tracker struct {
+	mutex    sync.Mutex
+	sent     map[string]time.Time
+	resent   map[string]int
+	received map[string]int
+	lags     map[string][]time.Duration
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		sent:     map[string]time.Time{},
+		resent:   map[string]int{},
+		received: map[string]int{},
+		lags:     map[string][]time.Duration{},
+	}
+}
+
+// expect records a message that is about to be sent
+func (t *tracker) expect(traceID string, at time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.sent[traceID] = at
+}
+
+// resend records that a message was deliberately sent again, so that it is expected to be consumed once more
+func (t *tracker) resend(traceID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.resent[traceID]++
+}
+
+// forget stops expecting a message that kafka did not acknowledge
+func (t *tracker) forget(traceID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.sent, traceID)
+}
+
+// consumed records a message consumed from topic, ignoring messages that were not sent by this run
+func (t *tracker) consumed(topic string, value []byte, at time.Time) {
+	traceID := traceIDPattern.Find(value)
+	if traceID == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	sentAt, ok := t.sent[string(traceID)]
+	if !ok {
+		return
+	}
+	t.received[string(traceID)]++
+	if t.received[string(traceID)] == 1 {
+		t.lags[topic] = append(t.lags[topic], at.Sub(sentAt))
+	}
+}
+
+// outstanding returns the number of messages sent that have not been consumed yet
+func (t *tracker) outstanding() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	count := 0
+	for traceID := range t.sent {
+		if t.received[traceID] == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// wait blocks until every message sent has been consumed, the timeout has elapsed or the context is done
+func (t *tracker) wait(ctx context.Context, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for t.outstanding() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// verificationReport describes how the messages sent by the load test were consumed from the verification topics.
+// Receipts of a message beyond the first are expected duplicates while the message was deliberately sent that many
+// times again, and unexpected duplicates after that, such as from kafka redelivering it.
+type verificationReport struct {
+	Topics               []string         `json:"topics"`
+	Expected             int              `json:"expected"`
+	Received             int              `json:"received"`
+	Missing              int              `json:"missing"`
+	ExpectedDuplicates   int              `json:"expected_duplicates"`
+	UnexpectedDuplicates int              `json:"unexpected_duplicates"`
+	Lag                  latencySummary   `json:"lag"`
+	TopicLags            []topicLagReport `json:"topic_lags"`
+	MissingTraceIDs      []string         `json:"missing_trace_ids,omitempty"`
+}
+
+// topicLagReport describes the messages first consumed from one of the verification topics
+type topicLagReport struct {
+	Topic    string         `json:"topic"`
+	Received int            `json:"received"`
+	Lag      latencySummary `json:"lag"`
+}
+
+// report summarises the messages consumed from the topics
+func (t *tracker) report(topics []string) *verificationReport {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	r := &verificationReport{Topics: topics, Expected: len(t.sent)}
+
+	var missing []string
+	for traceID := range t.sent {
+		count := t.received[traceID]
+		if count == 0 {
+			missing = append(missing, traceID)
+			continue
+		}
+		r.Received++
+
+		extra := count - 1
+		expected := min(extra, t.resent[traceID])
+		r.ExpectedDuplicates += expected
+		r.UnexpectedDuplicates += extra - expected
+	}
+	r.Missing = len(missing)
+	sort.Strings(missing)
+	if len(missing) > maxListedMissing {
+		missing = missing[:maxListedMissing]
+	}
+	r.MissingTraceIDs = missing
+
+	var all []time.Duration
+	for _, topic := range topics {
+		lags := t.lags[topic]
+		r.TopicLags = append(r.TopicLags, topicLagReport{Topic: topic, Received: len(lags), Lag: summarise(lags)})
+		all = append(all, lags...)
+	}
+	r.Lag = summarise(all)
+	return r
+}
+
+// verifier consumes the verification topics with a consumer group, passing each message to the tracker
+type verifier struct {
+	group     sarama.ConsumerGroup
+	topics    []string
+	tracker   *tracker
+	ready     chan struct{}
+	readyOnce sync.Once
+	pending   atomic.Int64
+	done      chan struct{}
+}
+
+func newVerifier(cfg *config.Config, groupName string, topics []string, t *tracker) (*verifier, error) {
+	offset := kafka.OffsetNewest
+	groupConfig := &kafka.ConsumerGroupConfig{
+		BrokerAddrs:    cfg.Kafka.Addr,
+		Topic:          strings.Join(topics, ","),
+		GroupName:      groupName,
+		KafkaVersion:   &cfg.Kafka.Version,
+		Offset:         &offset,
+		SecurityConfig: securityConfig(cfg),
+	}
+
+	saramaConfig, err := groupConfig.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := sarama.NewConsumerGroup(cfg.Kafka.Addr, groupName, saramaConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &verifier{
+		group:   group,
+		topics:  topics,
+		tracker: t,
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// start consumes the topics until the context is done, returning once the consumer is ready to receive messages
+// sent from now on
+func (v *verifier) start(ctx context.Context, timeout time.Duration) error {
+	go func() {
+		for err := range v.group.Errors() {
+			log.Error(ctx, "verification consumer error", err)
+		}
+	}()
+
+	go func() {
+		defer close(v.done)
+		for ctx.Err() == nil {
+			if err := v.group.Consume(ctx, v.topics, v); err != nil {
+				if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+					return
+				}
+				log.Error(ctx, "verification consumer failed to consume", err)
+				time.Sleep(time.Second)
+			}
+		}
+	}()
+
+	select {
+	case <-v.ready:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("timed out waiting for the verification consumer to join its group")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops consuming and leaves the consumer group
+func (v *verifier) close() error {
+	err := v.group.Close()
+	<-v.done
+	return err
+}
+
+// Setup is called when the consumer is assigned its partitions, which it is ready to consume once all their
+// claims have started
+func (v *verifier) Setup(session sarama.ConsumerGroupSession) error {
+	claims := 0
+	for _, partitions := range session.Claims() {
+		claims += len(partitions)
+	}
+	if v.pending.Add(int64(claims)) == 0 {
+		v.readyOnce.Do(func() { close(v.ready) })
+	}
+	return nil
+}
+
+// Cleanup is called at the end of a session
+func (v *verifier) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim passes the messages of a partition to the tracker
+func (v *verifier) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	if v.pending.Add(-1) == 0 {
+		v.readyOnce.Do(func() { close(v.ready) })
+	}
+
+	for message := range claim.Messages() {
+		v.tracker.consumed(message.Topic, message.Value, time.Now())
+		session.MarkMessage(message, "")
+	}
+	return nil
+}
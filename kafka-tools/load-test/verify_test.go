@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTrackerReport(t *testing.T) {
+	Convey("Given a tracker of messages, one of which was deliberately sent twice", t, func() {
+		tr := newTracker()
+		sent := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		for _, traceID := range []string{"stub-1-1", "stub-1-2", "stub-1-3"} {
+			tr.expect(traceID, sent)
+		}
+		tr.resend("stub-1-1")
+
+		Convey("When the re-sent message is consumed twice, another message three times and the last not at all", func() {
+			for _, traceID := range []string{"stub-1-1", "stub-1-1", "stub-1-2", "stub-1-2", "stub-1-2"} {
+				tr.consumed(contentUpdatedTopic, []byte(`{"trace_id":"`+traceID+`"}`), sent.Add(time.Second))
+			}
+			r := tr.report([]string{contentUpdatedTopic})
+
+			Convey("Then the deliberate re-send is an expected duplicate, and the redeliveries unexpected", func() {
+				So(r.Expected, ShouldEqual, 3)
+				So(r.Received, ShouldEqual, 2)
+				So(r.Missing, ShouldEqual, 1)
+				So(r.MissingTraceIDs, ShouldResemble, []string{"stub-1-3"})
+				So(r.ExpectedDuplicates, ShouldEqual, 1)
+				So(r.UnexpectedDuplicates, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the re-sent message is only consumed once", func() {
+			tr.consumed(contentUpdatedTopic, []byte(`{"trace_id":"stub-1-1"}`), sent.Add(time.Second))
+			r := tr.report([]string{contentUpdatedTopic})
+
+			Convey("Then no duplicates are reported", func() {
+				So(r.ExpectedDuplicates, ShouldEqual, 0)
+				So(r.UnexpectedDuplicates, ShouldEqual, 0)
+			})
+		})
+	})
+}
@@ -0,0 +1,210 @@
+package malformed
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/Shopify/sarama"
+)
+
+// Kinds of malformed event, each of which should be rejected or dead-lettered by downstream consumers
+const (
+	TruncatedAvro   = "truncated-avro"
+	WrongSchemaAvro = "wrong-schema-avro"
+	InvalidJSON     = "invalid-json"
+	Oversized       = "oversized"
+	Empty           = "empty"
+	UnknownFields   = "unknown-fields"
+)
+
+// Kinds lists every kind of malformed event
+var Kinds = []string{TruncatedAvro, WrongSchemaAvro, InvalidJSON, Oversized, Empty, UnknownFields}
+
+// ErrUnknownKind is returned for a kind of malformed event that is not in Kinds
+var ErrUnknownKind = errors.New("unknown kind of malformed event: must be one of " + strings.Join(Kinds, ", "))
+
+// unknownField is the field added to unknown-fields events
+const unknownField = "unexpected_field"
+
+// Generator makes malformed payloads from the resources the kafka tools would otherwise send well-formed
+type Generator struct {
+	Encoder *events.Encoder
+	// MaxBytes is the size oversized payloads exceed, which is the configured KAFKA_MAX_BYTES
+	MaxBytes int
+}
+
+// ParseKinds parses a comma separated list of kinds, where "all" is every kind
+func ParseKinds(list string) ([]string, error) {
+	if list == "all" {
+		return Kinds, nil
+	}
+
+	var kinds []string
+	for _, kind := range strings.Split(list, ",") {
+		kind = strings.TrimSpace(kind)
+		if !isKind(kind) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKind, kind)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+func isKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Configure raises the maximum message size of a producer config so that oversized payloads are sent to the
+// broker, which decides whether to accept them, rather than being rejected by the producer
+func (g *Generator) Configure(cfg *sarama.Config, kinds []string) {
+	for _, kind := range kinds {
+		if kind == Oversized && cfg.Producer.MaxMessageBytes <= 2*g.MaxBytes {
+			cfg.Producer.MaxMessageBytes = 2*g.MaxBytes + 1
+		}
+	}
+}
+
+// Payload returns a payload of the kind for a message sending the resource to the topic:
+//   - truncated-avro is the topic's Avro payload cut in half, after the schema ID if it is framed
+//   - wrong-schema-avro is another type of event marshalled with its own schema, framed with the topic's schema ID
+//   - invalid-json is the resource's JSON with a trailing comma and no closing brace
+//   - oversized is a well-formed payload in the topic's encoding, with a URI padded beyond MaxBytes
+//   - empty has no bytes
+//   - unknown-fields is the topic's payload with an extra field, written in Avro as if with an extended schema
+func (g *Generator) Payload(kind, topic string, resource models.Resource) ([]byte, error) {
+	switch kind {
+	case TruncatedAvro:
+		payload, err := g.avro(topic, resource)
+		if err != nil {
+			return nil, err
+		}
+		return truncate(payload, g.framed()), nil
+	case WrongSchemaAvro:
+		payload, err := events.Marshal(otherResource(resource), events.EncodingAvro)
+		if err != nil {
+			return nil, err
+		}
+		return g.frame(topic, payload)
+	case InvalidJSON:
+		payload, err := events.Marshal(resource, events.EncodingJSON)
+		if err != nil {
+			return nil, err
+		}
+		return append(payload[:len(payload)-1], ','), nil
+	case Oversized:
+		return g.Encoder.Encode(topic, pad(resource, g.MaxBytes+1))
+	case Empty:
+		return []byte{}, nil
+	case UnknownFields:
+		return g.withUnknownField(topic, resource)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKind, kind)
+	}
+}
+
+// framed reports whether malformed Avro payloads are framed with a schema ID, whatever the encoding of their topic
+func (g *Generator) framed() bool {
+	return g.Encoder.Kafka.SchemaRegistryFraming
+}
+
+// avro marshals the resource with its Avro schema, framed with the topic's schema ID if framing is enabled
+func (g *Generator) avro(topic string, resource models.Resource) ([]byte, error) {
+	payload, err := events.Marshal(resource, events.EncodingAvro)
+	if err != nil {
+		return nil, err
+	}
+	return g.frame(topic, payload)
+}
+
+// frame frames an Avro payload with the ID of the topic's latest schema if framing is enabled
+func (g *Generator) frame(topic string, payload []byte) ([]byte, error) {
+	if !g.framed() {
+		return payload, nil
+	}
+
+	schemaID, err := g.Encoder.Registry.LatestID(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema ID for topic %q: %w", topic, err)
+	}
+	return events.Frame(schemaID, payload), nil
+}
+
+func (g *Generator) withUnknownField(topic string, resource models.Resource) ([]byte, error) {
+	if events.TopicEncoding(g.Encoder.Kafka, topic) == events.EncodingAvro {
+		payload, err := events.Marshal(resource, events.EncodingAvro)
+		if err != nil {
+			return nil, err
+		}
+		return g.frame(topic, appendAvroString(payload, unknownField))
+	}
+
+	payload, err := events.Marshal(resource, events.EncodingJSON)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err = json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	fields[unknownField] = "unexpected"
+	return json.Marshal(fields)
+}
+
+// truncate cuts a payload in half, keeping any framing so that it is the Avro body that is truncated
+func truncate(payload []byte, framed bool) []byte {
+	start := 0
+	if framed {
+		start = len(events.Frame(0, nil))
+	}
+	return payload[:start+(len(payload)-start)/2]
+}
+
+// appendAvroString appends an Avro encoded string, which is its zig-zag varint length followed by its bytes
+func appendAvroString(payload []byte, s string) []byte {
+	payload = binary.AppendVarint(payload, int64(len(s)))
+	return append(payload, s...)
+}
+
+// otherResource returns a resource of a different type to the resource, so that it is marshalled with another
+// schema. Content-updated resources become search-content-deleted resources, and everything else becomes a
+// content-updated resource.
+func otherResource(resource models.Resource) models.Resource {
+	switch r := resource.(type) {
+	case models.ContentUpdatedResource:
+		return models.SearchContentDeletedResource{URI: r.URI, CollectionID: r.CollectionID, TraceID: r.TraceID}
+	case models.SearchContentUpdatedResource:
+		return models.ContentUpdatedResource{URI: r.URI, TraceID: r.TraceID}
+	case models.SearchContentDeletedResource:
+		return models.ContentUpdatedResource{URI: r.URI, CollectionID: r.CollectionID, TraceID: r.TraceID}
+	default:
+		return resource
+	}
+}
+
+// pad returns the resource with its URI padded by size bytes
+func pad(resource models.Resource, size int) models.Resource {
+	padding := "/" + strings.Repeat("x", size)
+	switch r := resource.(type) {
+	case models.ContentUpdatedResource:
+		r.URI += padding
+		return r
+	case models.SearchContentUpdatedResource:
+		r.URI += padding
+		return r
+	case models.SearchContentDeletedResource:
+		r.URI += padding
+		return r
+	default:
+		return resource
+	}
+}
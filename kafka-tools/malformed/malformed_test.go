@@ -0,0 +1,222 @@
+package malformed_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/malformed"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+	"github.com/Shopify/sarama"
+)
+
+const maxBytes = 1000
+
+var contentUpdated = models.ContentUpdatedResource{
+	URI:          "/economy",
+	DataType:     "legacy",
+	CollectionID: "COLLECTIONID",
+	JobID:        "JOBID",
+	SearchIndex:  "ons",
+	TraceID:      "stub-1-1",
+}
+
+func newTestGenerator(framing bool, encoding string) *malformed.Generator {
+	encoder, err := events.NewEncoder(&config.Kafka{
+		ContentUpdatedTopic:       "content-updated",
+		SearchContentUpdatedTopic: "search-content-updated",
+		SearchContentDeletedTopic: "search-content-deleted",
+		ContentUpdatedEncoding:    encoding,
+		SchemaRegistryFraming:     framing,
+	})
+	So(err, ShouldBeNil)
+	return &malformed.Generator{Encoder: encoder, MaxBytes: maxBytes}
+}
+
+func TestParseKinds(t *testing.T) {
+	Convey("Given a list of kinds", t, func() {
+		Convey("When it is parsed", func() {
+			kinds, err := malformed.ParseKinds("empty, invalid-json")
+
+			Convey("Then each kind is returned", func() {
+				So(err, ShouldBeNil)
+				So(kinds, ShouldResemble, []string{malformed.Empty, malformed.InvalidJSON})
+			})
+		})
+	})
+
+	Convey("Given all kinds are asked for", t, func() {
+		Convey("When the list is parsed", func() {
+			kinds, err := malformed.ParseKinds("all")
+
+			Convey("Then every kind is returned", func() {
+				So(err, ShouldBeNil)
+				So(kinds, ShouldResemble, malformed.Kinds)
+			})
+		})
+	})
+
+	Convey("Given a list with an unknown kind", t, func() {
+		Convey("When it is parsed", func() {
+			kinds, err := malformed.ParseKinds("empty,garbled")
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldWrap, malformed.ErrUnknownKind)
+				So(kinds, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestPayload(t *testing.T) {
+	Convey("Given a generator for an Avro topic without framing", t, func() {
+		g := newTestGenerator(false, events.EncodingAvro)
+		valid, err := events.Marshal(contentUpdated, events.EncodingAvro)
+		So(err, ShouldBeNil)
+
+		Convey("When a truncated-avro payload is generated", func() {
+			payload, err := g.Payload(malformed.TruncatedAvro, "content-updated", contentUpdated)
+
+			Convey("Then it is the first half of the valid payload, which cannot be unmarshalled", func() {
+				So(err, ShouldBeNil)
+				So(payload, ShouldResemble, valid[:len(valid)/2])
+				var r models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(payload, &r), ShouldNotBeNil)
+			})
+		})
+
+		Convey("When a wrong-schema-avro payload is generated", func() {
+			payload, err := g.Payload(malformed.WrongSchemaAvro, "content-updated", contentUpdated)
+
+			Convey("Then it is a search-content-deleted event", func() {
+				So(err, ShouldBeNil)
+				var r models.SearchContentDeletedResource
+				So(schema.SearchContentDeletedEvent.Unmarshal(payload, &r), ShouldBeNil)
+				So(r.URI, ShouldEqual, contentUpdated.URI)
+				So(payload, ShouldNotResemble, valid)
+			})
+		})
+
+		Convey("When an invalid-json payload is generated", func() {
+			payload, err := g.Payload(malformed.InvalidJSON, "content-updated", contentUpdated)
+
+			Convey("Then it is not valid JSON", func() {
+				So(err, ShouldBeNil)
+				So(json.Valid(payload), ShouldBeFalse)
+				So(string(payload), ShouldStartWith, `{"uri":"/economy"`)
+			})
+		})
+
+		Convey("When an oversized payload is generated", func() {
+			payload, err := g.Payload(malformed.Oversized, "content-updated", contentUpdated)
+
+			Convey("Then it is a well-formed event larger than the maximum size", func() {
+				So(err, ShouldBeNil)
+				So(len(payload), ShouldBeGreaterThan, maxBytes)
+				var r models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(payload, &r), ShouldBeNil)
+				So(r.URI, ShouldStartWith, contentUpdated.URI+"/xxx")
+			})
+		})
+
+		Convey("When an empty payload is generated", func() {
+			payload, err := g.Payload(malformed.Empty, "content-updated", contentUpdated)
+
+			Convey("Then it has no bytes", func() {
+				So(err, ShouldBeNil)
+				So(payload, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When an unknown-fields payload is generated", func() {
+			payload, err := g.Payload(malformed.UnknownFields, "content-updated", contentUpdated)
+
+			Convey("Then it is the valid payload followed by an extra string field", func() {
+				So(err, ShouldBeNil)
+				So(payload[:len(valid)], ShouldResemble, valid)
+				So(string(payload[len(valid)+1:]), ShouldEqual, "unexpected_field")
+			})
+		})
+
+		Convey("When a payload of an unknown kind is generated", func() {
+			payload, err := g.Payload("garbled", "content-updated", contentUpdated)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldWrap, malformed.ErrUnknownKind)
+				So(payload, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a generator for an Avro topic with framing", t, func() {
+		g := newTestGenerator(true, events.EncodingAvro)
+		valid, err := g.Encoder.Encode("content-updated", contentUpdated)
+		So(err, ShouldBeNil)
+
+		Convey("When a truncated-avro payload is generated", func() {
+			payload, err := g.Payload(malformed.TruncatedAvro, "content-updated", contentUpdated)
+
+			Convey("Then it keeps the schema ID and truncates the body", func() {
+				So(err, ShouldBeNil)
+				So(payload[:5], ShouldResemble, valid[:5])
+				So(len(payload), ShouldEqual, 5+(len(valid)-5)/2)
+			})
+		})
+
+		Convey("When a wrong-schema-avro payload is generated", func() {
+			payload, err := g.Payload(malformed.WrongSchemaAvro, "content-updated", contentUpdated)
+
+			Convey("Then it is framed with the topic's schema ID", func() {
+				So(err, ShouldBeNil)
+				schemaID, _, err := events.Unframe(payload)
+				So(err, ShouldBeNil)
+				validID, _, _ := events.Unframe(valid)
+				So(schemaID, ShouldEqual, validID)
+			})
+		})
+	})
+
+	Convey("Given a generator for a JSON topic", t, func() {
+		g := newTestGenerator(false, events.EncodingJSON)
+
+		Convey("When an unknown-fields payload is generated", func() {
+			payload, err := g.Payload(malformed.UnknownFields, "content-updated", contentUpdated)
+
+			Convey("Then it is the resource's JSON with an extra field", func() {
+				So(err, ShouldBeNil)
+				var fields map[string]interface{}
+				So(json.Unmarshal(payload, &fields), ShouldBeNil)
+				So(fields["uri"], ShouldEqual, contentUpdated.URI)
+				So(fields["unexpected_field"], ShouldEqual, "unexpected")
+			})
+		})
+	})
+}
+
+func TestConfigure(t *testing.T) {
+	Convey("Given a generator and a producer config", t, func() {
+		g := newTestGenerator(false, events.EncodingAvro)
+		cfg := sarama.NewConfig()
+		cfg.Producer.MaxMessageBytes = maxBytes
+
+		Convey("When it is configured for kinds other than oversized", func() {
+			g.Configure(cfg, []string{malformed.Empty, malformed.InvalidJSON})
+
+			Convey("Then the maximum message size is unchanged", func() {
+				So(cfg.Producer.MaxMessageBytes, ShouldEqual, maxBytes)
+			})
+		})
+
+		Convey("When it is configured for oversized events", func() {
+			g.Configure(cfg, []string{malformed.Oversized})
+
+			Convey("Then the maximum message size allows them", func() {
+				So(cfg.Producer.MaxMessageBytes, ShouldBeGreaterThan, 2*maxBytes)
+			})
+		})
+	})
+}
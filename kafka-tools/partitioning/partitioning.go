@@ -0,0 +1,106 @@
+package partitioning
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/Shopify/sarama"
+)
+
+// Key strategies, which decide the key of each message
+const (
+	KeyNone         = "none"
+	KeyURI          = "uri"
+	KeyCollectionID = "collection-id"
+	KeyRandom       = "random"
+)
+
+// AnyPartition leaves the partition of each message to the partitioner, which hashes its key
+const AnyPartition = -1
+
+// ErrUnknownKeyStrategy is returned for a key strategy other than none, uri, collection-id or random
+var ErrUnknownKeyStrategy = errors.New("unknown key strategy: must be none, uri, collection-id or random")
+
+// Strategy decides the key and partition of the messages sent by the kafka tools, so that partition affinity and
+// ordering can be tested
+type Strategy struct {
+	Key       string
+	Partition int32
+}
+
+// New returns a strategy that keys messages with the key strategy, and sends them to partition unless it is
+// AnyPartition
+func New(key string, partition int) (*Strategy, error) {
+	switch key {
+	case "", KeyNone:
+		key = KeyNone
+	case KeyURI, KeyCollectionID, KeyRandom:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyStrategy, key)
+	}
+	if partition < AnyPartition {
+		return nil, fmt.Errorf("invalid partition %d: must not be negative", partition)
+	}
+
+	return &Strategy{Key: key, Partition: int32(partition)}, nil
+}
+
+// Configure sets the partitioner of a producer config, which must be manual to send to an explicit partition
+func (s *Strategy) Configure(cfg *sarama.Config) {
+	if s.Partition != AnyPartition {
+		cfg.Producer.Partitioner = sarama.NewManualPartitioner
+	}
+}
+
+// Apply sets the key and partition of a message sending the resource, returning the key
+func (s *Strategy) Apply(message *sarama.ProducerMessage, resource models.Resource) string {
+	key := s.KeyOf(resource)
+	if key != "" {
+		message.Key = sarama.StringEncoder(key)
+	}
+	if s.Partition != AnyPartition {
+		message.Partition = s.Partition
+	}
+	return key
+}
+
+// KeyOf returns the key of a message sending the resource, which is empty if the message should have no key. Resources
+// without the field the strategy keys by, such as search-content-updated resources with no collection ID, have no key.
+func (s *Strategy) KeyOf(resource models.Resource) string {
+	switch s.Key {
+	case KeyURI:
+		return uriOf(resource)
+	case KeyCollectionID:
+		return collectionIDOf(resource)
+	case KeyRandom:
+		return fmt.Sprintf("%016x", rand.Uint64())
+	default:
+		return ""
+	}
+}
+
+func uriOf(resource models.Resource) string {
+	switch r := resource.(type) {
+	case models.ContentUpdatedResource:
+		return r.URI
+	case models.SearchContentUpdatedResource:
+		return r.URI
+	case models.SearchContentDeletedResource:
+		return r.URI
+	default:
+		return ""
+	}
+}
+
+func collectionIDOf(resource models.Resource) string {
+	switch r := resource.(type) {
+	case models.ContentUpdatedResource:
+		return r.CollectionID
+	case models.SearchContentDeletedResource:
+		return r.CollectionID
+	default:
+		return ""
+	}
+}
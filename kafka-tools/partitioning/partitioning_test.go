@@ -0,0 +1,131 @@
+package partitioning_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/partitioning"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/Shopify/sarama"
+)
+
+var (
+	contentUpdated = models.ContentUpdatedResource{
+		URI:          "/economy",
+		CollectionID: "COLLECTIONID",
+	}
+	searchContentUpdated = models.SearchContentUpdatedResource{
+		URI: "/releases/a-release",
+	}
+)
+
+func TestNew(t *testing.T) {
+	Convey("Given no key strategy", t, func() {
+		Convey("When a strategy is created", func() {
+			strategy, err := partitioning.New("", partitioning.AnyPartition)
+
+			Convey("Then messages have no key", func() {
+				So(err, ShouldBeNil)
+				So(strategy.Key, ShouldEqual, partitioning.KeyNone)
+			})
+		})
+	})
+
+	Convey("Given an unknown key strategy", t, func() {
+		Convey("When a strategy is created", func() {
+			strategy, err := partitioning.New("title", partitioning.AnyPartition)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldWrap, partitioning.ErrUnknownKeyStrategy)
+				So(strategy, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a negative partition", t, func() {
+		Convey("When a strategy is created", func() {
+			strategy, err := partitioning.New(partitioning.KeyURI, -2)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(strategy, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestKeyOf(t *testing.T) {
+	Convey("Given a strategy keying by URI", t, func() {
+		strategy, err := partitioning.New(partitioning.KeyURI, partitioning.AnyPartition)
+		So(err, ShouldBeNil)
+
+		Convey("Then resources are keyed by their URI", func() {
+			So(strategy.KeyOf(contentUpdated), ShouldEqual, "/economy")
+			So(strategy.KeyOf(searchContentUpdated), ShouldEqual, "/releases/a-release")
+		})
+	})
+
+	Convey("Given a strategy keying by collection ID", t, func() {
+		strategy, err := partitioning.New(partitioning.KeyCollectionID, partitioning.AnyPartition)
+		So(err, ShouldBeNil)
+
+		Convey("Then resources are keyed by their collection ID", func() {
+			So(strategy.KeyOf(contentUpdated), ShouldEqual, "COLLECTIONID")
+		})
+
+		Convey("Then resources without a collection ID have no key", func() {
+			So(strategy.KeyOf(searchContentUpdated), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a strategy with random keys", t, func() {
+		strategy, err := partitioning.New(partitioning.KeyRandom, partitioning.AnyPartition)
+		So(err, ShouldBeNil)
+
+		Convey("Then each message of a resource has a different key", func() {
+			So(strategy.KeyOf(contentUpdated), ShouldNotBeEmpty)
+			So(strategy.KeyOf(contentUpdated), ShouldNotEqual, strategy.KeyOf(contentUpdated))
+		})
+	})
+}
+
+func TestApply(t *testing.T) {
+	Convey("Given a strategy keying by URI without a partition", t, func() {
+		strategy, err := partitioning.New(partitioning.KeyURI, partitioning.AnyPartition)
+		So(err, ShouldBeNil)
+
+		Convey("When it is applied to a message and a producer config", func() {
+			message := &sarama.ProducerMessage{}
+			key := strategy.Apply(message, contentUpdated)
+			cfg := sarama.NewConfig()
+			strategy.Configure(cfg)
+
+			Convey("Then the message is keyed and left to the hash partitioner", func() {
+				So(key, ShouldEqual, "/economy")
+				So(message.Key, ShouldEqual, sarama.StringEncoder("/economy"))
+				So(message.Partition, ShouldEqual, 0)
+				So(cfg.Producer.Partitioner(contentUpdated.URI), ShouldHaveSameTypeAs, sarama.NewHashPartitioner(""))
+			})
+		})
+	})
+
+	Convey("Given a strategy without keys sending to a partition", t, func() {
+		strategy, err := partitioning.New(partitioning.KeyNone, 2)
+		So(err, ShouldBeNil)
+
+		Convey("When it is applied to a message and a producer config", func() {
+			message := &sarama.ProducerMessage{}
+			key := strategy.Apply(message, contentUpdated)
+			cfg := sarama.NewConfig()
+			strategy.Configure(cfg)
+
+			Convey("Then the message has no key and is sent to the partition", func() {
+				So(key, ShouldBeEmpty)
+				So(message.Key, ShouldBeNil)
+				So(message.Partition, ShouldEqual, 2)
+				So(cfg.Producer.Partitioner(contentUpdated.URI), ShouldHaveSameTypeAs, sarama.NewManualPartitioner(""))
+			})
+		})
+	})
+}
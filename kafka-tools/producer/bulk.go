@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/headers"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/partitioning"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/sink"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/Shopify/sarama"
+)
+
+// bulkEnvelope declares the topic of the resource on a line of JSONL, in place of detecting it
+type bulkEnvelope struct {
+	Topic   string          `json:"topic"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// bulkLine is a resource read from a line of JSONL, with the topic it is published to
+type bulkLine struct {
+	number   int
+	topic    string
+	resource models.Resource
+}
+
+// bulkFailure is a line of JSONL that could not be published
+type bulkFailure struct {
+	Line  int
+	Topic string
+	Error string
+}
+
+// bulkReport summarises the publishing of a JSONL file
+type bulkReport struct {
+	Lines    int
+	Sent     int
+	Failures []bulkFailure
+}
+
+// bulkPublisher publishes the resources on each line of JSONL to their topics
+type bulkPublisher struct {
+	kcfg        *config.Kafka
+	producer    sink.Producer
+	encoder     *events.Encoder
+	strategy    *partitioning.Strategy
+	key         string
+	topic       string
+	concurrency int
+
+	mutex  sync.Mutex
+	report bulkReport
+}
+
+// uriKey keys lines by the URI of their resource, so that the lines for a URI are published by the same worker
+var uriKey = &partitioning.Strategy{Key: partitioning.KeyURI, Partition: partitioning.AnyPartition}
+
+// runBulk publishes the resources in the JSONL file given by the flags, reporting the lines that failed
+func runBulk(ctx context.Context, cfg *config.Config, opts options) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if opts.concurrency < 1 {
+		return errors.New("-concurrency must be at least 1")
+	}
+	if opts.topic != "" {
+		if _, ok := topicResourcesFor(cfg.Kafka)[opts.topic]; !ok {
+			return fmt.Errorf("unknown topic %q: -topic must be one of %s, %s or %s", opts.topic,
+				cfg.Kafka.ContentUpdatedTopic, cfg.Kafka.SearchContentUpdatedTopic, cfg.Kafka.SearchContentDeletedTopic)
+		}
+	}
+
+	in := io.Reader(os.Stdin)
+	if opts.jsonl != "-" {
+		f, err := os.Open(opts.jsonl)
+		if err != nil {
+			log.Error(ctx, "failed to open JSONL file", err)
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	otelShutdown, err := headers.SetupOTel(ctx, cfg)
+	if err != nil {
+		log.Error(ctx, "error setting up OpenTelemetry - hint: ensure OTEL_EXPORTER_OTLP_ENDPOINT is set", err)
+		return err
+	}
+	defer func() {
+		if err := otelShutdown(context.Background()); err != nil {
+			log.Error(ctx, "failed to shut down OpenTelemetry", err)
+		}
+	}()
+
+	encoder, err := events.NewEncoder(cfg.Kafka)
+	if err != nil {
+		log.Error(ctx, "failed to create event encoder", err)
+		return err
+	}
+	strategy, err := partitioning.New(opts.keyBy, opts.partition)
+	if err != nil {
+		log.Error(ctx, "invalid partitioning", err)
+		return err
+	}
+
+	var producer sink.Producer
+	if opts.capture != "" {
+		producer, err = sink.OpenCapture(opts.capture)
+	} else {
+		// the lines may be for any topic, whether or not -topic is given
+		producer, err = newProducer(cfg.Kafka, strategy, func(*sarama.Config) {})
+	}
+	if err != nil {
+		log.Error(ctx, "fatal error creating kafka producer", err)
+		return err
+	}
+	defer func() {
+		if cerr := producer.Close(); cerr != nil {
+			log.Error(ctx, "failed to close producer", cerr)
+		}
+	}()
+
+	b := &bulkPublisher{
+		kcfg:        cfg.Kafka,
+		producer:    producer,
+		encoder:     encoder,
+		strategy:    strategy,
+		key:         opts.key,
+		topic:       opts.topic,
+		concurrency: opts.concurrency,
+	}
+	start := time.Now()
+	report, err := b.publish(ctx, in)
+	log.Info(ctx, "published JSONL file", log.Data{
+		"file":    opts.jsonl,
+		"lines":   report.Lines,
+		"sent":    report.Sent,
+		"failed":  len(report.Failures),
+		"elapsed": time.Since(start).String(),
+	})
+	for _, f := range report.Failures {
+		fmt.Fprintf(os.Stderr, "line %d: %s\n", f.Line, f.Error)
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		log.Error(ctx, "publishing stopped before the end of the JSONL file", err, log.Data{"lines": report.Lines})
+		return fmt.Errorf("run incomplete: stopped after reading %d lines: %w", report.Lines, err)
+	case err != nil:
+		log.Error(ctx, "failed to read JSONL file", err)
+		return err
+	case len(report.Failures) > 0:
+		return fmt.Errorf("%d of %d lines failed", len(report.Failures), report.Lines)
+	}
+	return nil
+}
+
+// publish reads each line of JSONL and publishes its resource, returning a report of the lines sent and failed. Lines
+// are handed out in order to concurrency workers, and the lines for a URI always go to the same worker, so that they
+// are published in the order of the file. Lines that fail are reported and do not stop the others; an error is only
+// returned if the JSONL cannot be read, or the context is done before all of it has been read.
+func (b *bulkPublisher) publish(ctx context.Context, r io.Reader) (bulkReport, error) {
+	queues := make([]chan bulkLine, b.concurrency)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan bulkLine, b.concurrency)
+		wg.Add(1)
+		go func(queue <-chan bulkLine) {
+			defer wg.Done()
+			for line := range queue {
+				b.send(ctx, line)
+			}
+		}(queues[i])
+	}
+
+	err := b.read(ctx, r, func(line bulkLine) {
+		h := fnv.New32a()
+		h.Write([]byte(uriKey.KeyOf(line.resource)))
+		queues[h.Sum32()%uint32(len(queues))] <- line
+	})
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	sort.Slice(b.report.Failures, func(i, j int) bool { return b.report.Failures[i].Line < b.report.Failures[j].Line })
+	return b.report, err
+}
+
+// read parses each non-blank line of JSONL into a resource and hands it to dispatch, until the JSONL ends or the
+// context is done, in which case the context's error is returned. Lines that cannot be parsed are reported as failures.
+func (b *bulkPublisher) read(ctx context.Context, r io.Reader, dispatch func(bulkLine)) error {
+	reader := bufio.NewReader(r)
+	for number := 1; ; number++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		raw, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 {
+			b.mutex.Lock()
+			b.report.Lines++
+			b.mutex.Unlock()
+
+			topic, resource, perr := parseLine(b.kcfg, b.topic, raw)
+			if perr != nil {
+				b.fail(ctx, number, topic, perr)
+			} else {
+				dispatch(bulkLine{number: number, topic: topic, resource: resource})
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
+
+// send publishes the resource on a line to its topic, recording whether it was sent
+func (b *bulkPublisher) send(ctx context.Context, line bulkLine) {
+	if ctx.Err() != nil {
+		b.fail(ctx, line.number, line.topic, ctx.Err())
+		return
+	}
+
+	payload, eventType, err := marshalPayload(b.encoder, line.topic, line.resource)
+	if err != nil {
+		b.fail(ctx, line.number, line.topic, fmt.Errorf("failed to marshal event: %w", err))
+		return
+	}
+
+	message := &sarama.ProducerMessage{Topic: line.topic, Value: sarama.ByteEncoder(payload)}
+	key := b.strategy.Apply(message, line.resource)
+	if b.key != "" {
+		key = b.key
+		message.Key = sarama.StringEncoder(key)
+	}
+	partition, offset, err := sendMessage(ctx, b.producer, b.encoder, message)
+	if err != nil {
+		b.fail(ctx, line.number, line.topic, err)
+		return
+	}
+
+	b.mutex.Lock()
+	b.report.Sent++
+	b.mutex.Unlock()
+	log.Info(ctx, "resource sent to Kafka", log.Data{
+		"event_type":  eventType,
+		"topic":       line.topic,
+		"key":         key,
+		"partition":   partition,
+		"offset":      offset,
+		"line":        line.number,
+		"traceparent": traceParent(message),
+		"resource":    fmt.Sprintf("%T", line.resource),
+	})
+}
+
+// fail records that a line could not be published
+func (b *bulkPublisher) fail(ctx context.Context, number int, topic string, err error) {
+	log.Error(ctx, "failed to publish line", err, log.Data{"line": number, "topic": topic})
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.report.Failures = append(b.report.Failures, bulkFailure{Line: number, Topic: topic, Error: err.Error()})
+}
+
+// parseLine decodes a line of JSONL into the topic and resource to publish. A line's topic is declared by wrapping its
+// resource in a {"topic": ..., "payload": ...} envelope, or for every line without one by the -topic flag, and is
+// otherwise detected from the resource's fields.
+func parseLine(kcfg *config.Kafka, topic string, raw []byte) (string, models.Resource, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return topic, nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	payload := raw
+	if _, ok := fields["payload"]; ok {
+		var envelope bulkEnvelope
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			return topic, nil, fmt.Errorf("invalid envelope: %w", err)
+		}
+		if _, ok := topicResourcesFor(kcfg)[envelope.Topic]; !ok {
+			return envelope.Topic, nil, fmt.Errorf("unknown topic %q in envelope", envelope.Topic)
+		}
+		topic, payload = envelope.Topic, envelope.Payload
+	} else if topic == "" {
+		var err error
+		if topic, err = detectTopic(kcfg, fields); err != nil {
+			return "", nil, err
+		}
+	}
+
+	resource, err := events.DecodeResource(kcfg, topic, payload)
+	if err != nil {
+		return topic, nil, fmt.Errorf("invalid JSON resource for topic %s: %w", topic, err)
+	}
+	return topic, resource, nil
+}
+
+// detectTopic returns the topic of the first resource type that has all of the fields. The types are tried from the
+// fewest fields to the most, so fields that more than one type has, such as just a URI, are taken to be those of a
+// search-content-deleted resource.
+func detectTopic(kcfg *config.Kafka, fields map[string]json.RawMessage) (string, error) {
+	candidates := []struct {
+		topic    string
+		resource models.Resource
+	}{
+		{kcfg.SearchContentDeletedTopic, models.SearchContentDeletedResource{}},
+		{kcfg.ContentUpdatedTopic, models.ContentUpdatedResource{}},
+		{kcfg.SearchContentUpdatedTopic, models.SearchContentUpdatedResource{}},
+	}
+	for _, c := range candidates {
+		known := jsonFields(c.resource)
+		matches := true
+		for field := range fields {
+			if !known[field] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return c.topic, nil
+		}
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("cannot detect the resource type of fields %s: declare it with -topic or a topic envelope",
+		strings.Join(names, ", "))
+}
+
+// jsonFields returns the names of the JSON fields of a resource
+func jsonFields(resource models.Resource) map[string]bool {
+	t := reflect.TypeOf(resource)
+	fields := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[name] = true
+	}
+	return fields
+}
@@ -2,21 +2,57 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/config"
 	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/headers"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/malformed"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/partitioning"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/sink"
 	"github.com/ONSdigital/dis-search-upstream-stub/models"
-	"github.com/ONSdigital/dis-search-upstream-stub/schema"
 	kafka "github.com/ONSdigital/dp-kafka/v4"
 	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/Shopify/sarama"
+	"go.opentelemetry.io/otel/codes"
 )
 
 const serviceName = "dis-search-upstream-stub"
 
+// options are the flags that select what to send without prompting, so that the producer can be run from scripts and CI
+type options struct {
+	topic       string
+	fixture     string
+	index       int
+	json        string
+	count       int
+	key         string
+	keyBy       string
+	partition   int
+	malformed   string
+	capture     string
+	replay      string
+	speed       float64
+	record      string
+	recordFor   time.Duration
+	jsonl       string
+	concurrency int
+}
+
+// topicResources describes the resources sent to one of the configured topics
+type topicResources struct {
+	resourceType string
+	fixtureDir   string
+	label        string
+}
+
 func main() {
 	// keep main tiny to satisfy gocyclo
 	if err := run(); err != nil {
@@ -29,6 +65,11 @@ func run() error {
 	log.Namespace = serviceName
 	ctx := context.Background()
 
+	opts, interactive, err := parseFlags(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		return err
+	}
+
 	// Get Config
 	cfg, err := config.Get()
 	if err != nil {
@@ -36,85 +77,285 @@ func run() error {
 		return err
 	}
 
-	// Prompt and map to resource type + topic + label
-	topic, resourceType, eventLabel, err := promptTopicAndMap(cfg)
-	if err != nil {
+	// Replay or record a capture, or publish a JSONL file, instead of sending a resource, if asked to
+	switch {
+	case opts.replay != "":
+		return runReplay(ctx, cfg, opts)
+	case opts.record != "":
+		return runRecord(ctx, cfg, opts)
+	case opts.jsonl != "":
+		return runBulk(ctx, cfg, opts)
+	}
+
+	// Select the topic and resource, prompting for them if no flags were given
+	var topic string
+	var selected models.Resource
+	if interactive {
+		topic, selected, err = promptTopicAndResource(ctx, cfg)
+	} else {
+		topic, selected, err = selectTopicAndResource(ctx, cfg, opts)
+	}
+	if err != nil || selected == nil {
 		return err
 	}
-	log.Info(ctx, "Selected topic", log.Data{
-		"topic":         topic,
-		"resource_type": resourceType,
-		"event_type":    eventLabel,
-	})
 
-	// Fetch resources
-	resourceStore := &data.ResourceStore{}
-	resources, err := resourceStore.GetResourcesWithType(ctx, resourceType, data.Options{Offset: 0, Limit: 100})
+	// Set up OpenTelemetry, if enabled, to export the spans of the messages sent
+	otelShutdown, err := headers.SetupOTel(ctx, cfg)
 	if err != nil {
-		log.Error(ctx, "failed to retrieve resources", err)
+		log.Error(ctx, "error setting up OpenTelemetry - hint: ensure OTEL_EXPORTER_OTLP_ENDPOINT is set", err)
 		return err
 	}
-	if len(resources.Items) == 0 {
-		fmt.Println("No resources available for this type.")
-		return nil
-	}
+	defer func() {
+		if err := otelShutdown(context.Background()); err != nil {
+			log.Error(ctx, "failed to shut down OpenTelemetry", err)
+		}
+	}()
 
-	// Show and select one
-	printResources(resources.Items)
-	idx, err := promptSelection(len(resources.Items))
+	// Marshal payload with the encoding configured for the topic
+	encoder, err := events.NewEncoder(cfg.Kafka)
 	if err != nil {
+		log.Error(ctx, "failed to create event encoder", err)
 		return err
 	}
-	selected := resources.Items[idx]
+	encoding := events.TopicEncoding(cfg.Kafka, topic)
+	payload, eventType, err := marshalPayload(encoder, topic, selected)
+	if err != nil {
+		log.Error(ctx, "failed to marshal event", err, log.Data{"event_type": eventType, "encoding": encoding})
+		return err
+	}
+
+	// Send malformed events instead, cycling through the requested kinds
+	var kinds []string
+	if opts.malformed != "" {
+		if kinds, err = malformed.ParseKinds(opts.malformed); err != nil {
+			log.Error(ctx, "invalid malformed events", err)
+			return err
+		}
+	}
+	generator := &malformed.Generator{Encoder: encoder, MaxBytes: cfg.Kafka.MaxBytes}
 
-	// Marshal payload (legacy Avro, new JSON)
-	payload, eventType, err := marshalPayload(selected)
+	// Key and partition messages as requested
+	strategy, err := partitioning.New(opts.keyBy, opts.partition)
 	if err != nil {
-		log.Error(ctx, "failed to marshal event", err, log.Data{"event_type": eventType})
+		log.Error(ctx, "invalid partitioning", err)
 		return err
 	}
 
-	// Producer setup
-	producer, err := newProducerForTopic(ctx, cfg.Kafka, topic)
+	// Producer setup, capturing messages to a file instead of sending them to kafka if asked to
+	var producer sink.Producer
+	if opts.capture != "" {
+		producer, err = sink.OpenCapture(opts.capture)
+	} else {
+		producer, err = newProducerForTopic(cfg.Kafka, topic, strategy, func(saramaConfig *sarama.Config) {
+			generator.Configure(saramaConfig, kinds)
+		})
+	}
 	if err != nil {
 		log.Error(ctx, "fatal error creating kafka producer", err, log.Data{"topic": topic})
 		return err
 	}
 	defer func() {
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		if cerr := producer.Close(shutdownCtx); cerr != nil {
-			log.Error(ctx, "failed to close kafka producer", cerr)
+		if cerr := producer.Close(); cerr != nil {
+			log.Error(ctx, "failed to close producer", cerr)
 		}
 	}()
 
-	producer.LogErrors(ctx)
-	if err := producer.Initialise(ctx); err != nil {
-		log.Error(ctx, "failed to initialise kafka producer", err)
-		return err
+	// Send, waiting for each message to be acknowledged so that failures are reported
+	for i := 1; i <= opts.count; i++ {
+		value, kind := payload, ""
+		if len(kinds) > 0 {
+			kind = kinds[(i-1)%len(kinds)]
+			if value, err = generator.Payload(kind, topic, selected); err != nil {
+				log.Error(ctx, "failed to generate malformed event", err, log.Data{"kind": kind, "topic": topic})
+				return err
+			}
+		}
+
+		message := &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(value)}
+		key := strategy.Apply(message, selected)
+		if opts.key != "" {
+			key = opts.key
+			message.Key = sarama.StringEncoder(key)
+		}
+		partition, offset, err := sendMessage(ctx, producer, encoder, message)
+		if err != nil {
+			log.Error(ctx, "failed to send resource to Kafka", err, log.Data{"topic": topic, "message": i})
+			return err
+		}
+		log.Info(ctx, "resource sent to Kafka", log.Data{
+			"event_type":  eventType,
+			"encoding":    encoding,
+			"framed":      encoder.Framed(topic),
+			"topic":       topic,
+			"key":         key,
+			"partition":   partition,
+			"offset":      offset,
+			"message":     i,
+			"malformed":   kind,
+			"bytes":       len(value),
+			"traceparent": traceParent(message),
+			"resource":    fmt.Sprintf("%T", selected),
+		})
+	}
+
+	return nil
+}
+
+// parseFlags parses the command line arguments into the flag set, reporting the producer as interactive if no flags
+// were given
+func parseFlags(flags *flag.FlagSet, args []string) (opts options, interactive bool, err error) {
+	flags.StringVar(&opts.topic, "topic", "", "topic to send to, as configured (e.g. search-content-updated)")
+	flags.StringVar(&opts.fixture, "fixture", "", "fixture to send, as a path relative to data/json_files or a file name in the topic's directory")
+	flags.IntVar(&opts.index, "index", 0, "number of the resource to send, as listed by the interactive producer")
+	flags.StringVar(&opts.json, "json", "", "inline JSON resource to send, or - to read it from stdin")
+	flags.IntVar(&opts.count, "count", 1, "number of times to send the resource")
+	flags.StringVar(&opts.key, "key", "", "key of the sent messages, overriding -key-by")
+	flags.StringVar(&opts.keyBy, "key-by", partitioning.KeyNone, "key strategy of the sent messages: none, uri, collection-id or random")
+	flags.IntVar(&opts.partition, "partition", partitioning.AnyPartition, "partition to send to (chosen by the partitioner from the key if -1)")
+	flags.StringVar(&opts.capture, "capture", "", "file to capture the messages to as NDJSON, instead of sending them to kafka")
+	flags.StringVar(&opts.replay, "replay", "", "capture file of messages to replay, instead of sending a resource")
+	flags.Float64Var(&opts.speed, "speed", 0, "replay messages at this multiple of their original timing (as fast as possible if 0)")
+	flags.StringVar(&opts.record, "record", "", "comma separated topics to record the messages of to the -capture file, instead of sending a resource")
+	flags.DurationVar(&opts.recordFor, "record-for", 0, "time to record messages for (until interrupted if 0)")
+	flags.StringVar(&opts.jsonl, "jsonl", "", "JSONL file of resources to publish, one per line, or - to read it from stdin")
+	flags.IntVar(&opts.concurrency, "concurrency", 1, "number of workers publishing the lines of the -jsonl file")
+	flags.StringVar(&opts.malformed, "malformed", "", "comma separated kinds of malformed event to send instead, cycled through each message, or all")
+	if err := flags.Parse(args); err != nil {
+		return opts, false, err
+	}
+
+	return opts, flags.NFlag() == 0, nil
+}
+
+// selectTopicAndResource selects the topic and the resource to send to it from the flags
+func selectTopicAndResource(ctx context.Context, cfg *config.Config, opts options) (topic string, selected models.Resource, err error) {
+	resources, ok := topicResourcesFor(cfg.Kafka)[opts.topic]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown topic %q: -topic must be one of %s, %s or %s", opts.topic,
+			cfg.Kafka.ContentUpdatedTopic, cfg.Kafka.SearchContentUpdatedTopic, cfg.Kafka.SearchContentDeletedTopic)
 	}
 
-	// Send
-	producer.Channels().Output <- kafka.BytesMessage{Value: payload}
-	log.Info(ctx, "resource sent to Kafka", log.Data{
-		"event_type": eventType,
-		"topic":      topic,
-		"resource":   fmt.Sprintf("%T", selected),
+	sources := 0
+	for _, given := range []bool{opts.fixture != "", opts.index != 0, opts.json != ""} {
+		if given {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return "", nil, errors.New("exactly one of -fixture, -index or -json must be given")
+	}
+	if opts.count < 1 {
+		return "", nil, errors.New("-count must be at least 1")
+	}
+
+	resourceStore := &data.ResourceStore{}
+
+	switch {
+	case opts.fixture != "":
+		selected, err = resourceStore.GetFixture(ctx, fixturePath(resources.fixtureDir, opts.fixture))
+		if err != nil {
+			return "", nil, fmt.Errorf("fixture %q: %w", opts.fixture, err)
+		}
+	case opts.index != 0:
+		list, err := resourceStore.GetResourcesWithType(ctx, resources.resourceType, data.Options{Offset: 0, Limit: 100})
+		if err != nil {
+			log.Error(ctx, "failed to retrieve resources", err)
+			return "", nil, err
+		}
+		if opts.index < 1 || opts.index > len(list.Items) {
+			return "", nil, fmt.Errorf("-index must be between 1 and %d for topic %s", len(list.Items), opts.topic)
+		}
+		selected = list.Items[opts.index-1]
+	default:
+		selected, err = decodeJSON(cfg.Kafka, opts.topic, opts.json)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	log.Info(ctx, "Selected topic", log.Data{
+		"topic":         opts.topic,
+		"resource_type": resources.resourceType,
+		"count":         opts.count,
 	})
+	return opts.topic, selected, nil
+}
 
-	return nil
+// fixturePath returns the path of a fixture relative to data/json_files, given either that path or the name of a
+// file in the topic's fixture directory, with or without its extension
+func fixturePath(dir, fixture string) string {
+	if strings.Contains(fixture, "/") {
+		return fixture
+	}
+	if !strings.HasSuffix(fixture, ".json") {
+		fixture += ".json"
+	}
+	return dir + "/" + fixture
+}
+
+// decodeJSON decodes an inline JSON resource for the topic, reading it from stdin if value is -
+func decodeJSON(kcfg *config.Kafka, topic, value string) (models.Resource, error) {
+	payload := []byte(value)
+	if value == "-" {
+		var err error
+		if payload, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, fmt.Errorf("failed to read resource from stdin: %w", err)
+		}
+	}
+
+	resource, err := events.DecodeResource(kcfg, topic, payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON resource for topic %s: %w", topic, err)
+	}
+	return resource, nil
+}
+
+func topicResourcesFor(kcfg *config.Kafka) map[string]topicResources {
+	return map[string]topicResources{
+		kcfg.ContentUpdatedTopic:       {resourceType: "ContentUpdatedResource", fixtureDir: "content_updated", label: "ContentUpdated"},
+		kcfg.SearchContentUpdatedTopic: {resourceType: "SearchContentUpdatedResource", fixtureDir: "search_content_updated", label: "SearchContentUpdated"},
+		kcfg.SearchContentDeletedTopic: {resourceType: "SearchContentDeletedResource", fixtureDir: "search_content_deleted", label: "SearchContentDeleted"},
+	}
 }
 
-func promptTopicAndMap(cfg *config.Config) (topic, resourceType, eventLabel string, err error) {
+// promptTopicAndResource prompts for the topic and then for the resource to send to it
+func promptTopicAndResource(ctx context.Context, cfg *config.Config) (topic string, selected models.Resource, err error) {
+	// Prompt and map to resource type + topic + label
+	topic, resourceType, eventLabel := promptTopicAndMap(cfg)
+	log.Info(ctx, "Selected topic", log.Data{
+		"topic":         topic,
+		"resource_type": resourceType,
+		"event_type":    eventLabel,
+	})
+
+	// Fetch resources
+	resourceStore := &data.ResourceStore{}
+	resources, err := resourceStore.GetResourcesWithType(ctx, resourceType, data.Options{Offset: 0, Limit: 100})
+	if err != nil {
+		log.Error(ctx, "failed to retrieve resources", err)
+		return "", nil, err
+	}
+	if len(resources.Items) == 0 {
+		fmt.Println("No resources available for this type.")
+		return "", nil, nil
+	}
+
+	// Show and select one
+	printResources(resources.Items)
+	idx := promptSelection(len(resources.Items))
+	return topic, resources.Items[idx], nil
+}
+
+func promptTopicAndMap(cfg *config.Config) (topic, resourceType, eventLabel string) {
 	fmt.Println("Select the Kafka topic to send messages to:")
-	fmt.Println("1) content-updated (legacy)")
-	fmt.Println("2) search-content-updated (new)")
-	fmt.Println("3) search-content-deleted (new)")
+	fmt.Printf("1) content-updated (legacy, %s)\n", events.TopicEncoding(cfg.Kafka, cfg.Kafka.ContentUpdatedTopic))
+	fmt.Printf("2) search-content-updated (new, %s)\n", events.TopicEncoding(cfg.Kafka, cfg.Kafka.SearchContentUpdatedTopic))
+	fmt.Printf("3) search-content-deleted (new, %s)\n", events.TopicEncoding(cfg.Kafka, cfg.Kafka.SearchContentDeletedTopic))
 
 	var choice int
 	for {
 		fmt.Print("Enter choice (1-3): ")
-		if _, err = fmt.Scanln(&choice); err != nil || choice < 1 || choice > 3 {
+		if _, err := fmt.Scanln(&choice); err != nil || choice < 1 || choice > 3 {
 			fmt.Println("Invalid selection. Please try again.")
 			continue
 		}
@@ -123,12 +364,14 @@ func promptTopicAndMap(cfg *config.Config) (topic, resourceType, eventLabel stri
 
 	switch choice {
 	case 1:
-		return cfg.Kafka.ContentUpdatedTopic, "ContentUpdatedResource", "ContentUpdated(AVRO)", nil
+		topic = cfg.Kafka.ContentUpdatedTopic
 	case 2:
-		return cfg.Kafka.SearchContentUpdatedTopic, "SearchContentUpdatedResource", "SearchContentUpdated(JSON)", nil
+		topic = cfg.Kafka.SearchContentUpdatedTopic
 	default:
-		return cfg.Kafka.SearchContentDeletedTopic, "SearchContentDeletedResource", "SearchContentDeleted(JSON)", nil
+		topic = cfg.Kafka.SearchContentDeletedTopic
 	}
+	resources := topicResourcesFor(cfg.Kafka)[topic]
+	return topic, resources.resourceType, fmt.Sprintf("%s(%s)", resources.label, strings.ToUpper(events.TopicEncoding(cfg.Kafka, topic)))
 }
 
 func printResources(items []models.Resource) {
@@ -147,7 +390,7 @@ func printResources(items []models.Resource) {
 	}
 }
 
-func promptSelection(count int) (int, error) {
+func promptSelection(count int) int {
 	var selection int
 	for {
 		fmt.Print("Enter the number of the resource to send: ")
@@ -155,32 +398,62 @@ func promptSelection(count int) (int, error) {
 			fmt.Println("Invalid selection. Please try again.")
 			continue
 		}
-		return selection - 1, nil
+		return selection - 1
 	}
 }
 
-func marshalPayload(item models.Resource) (payload []byte, eventType string, err error) {
-	switch r := item.(type) {
-	case models.ContentUpdatedResource:
-		b, err := schema.ContentPublishedEvent.Marshal(r) // legacy Avro
-		return b, "ContentPublishedEvent", err
-	case models.SearchContentUpdatedResource:
-		b, err := json.Marshal(r) // JSON
-		return b, "SearchContentUpdatedEvent", err
-	case models.SearchContentDeletedResource:
-		b, err := json.Marshal(r) // JSON
-		return b, "SearchContentDeletedEvent", err
-	default:
-		return nil, "UnknownEvent", fmt.Errorf("unsupported resource type: %T", item)
+func marshalPayload(encoder *events.Encoder, topic string, item models.Resource) (payload []byte, eventType string, err error) {
+	eventType, err = events.EventType(item)
+	if err != nil {
+		return nil, "UnknownEvent", err
 	}
+	payload, err = encoder.Encode(topic, item)
+	return payload, eventType, err
 }
 
-func newProducerForTopic(ctx context.Context, kcfg *config.Kafka, topic string) (kafka.IProducer, error) {
+// sendMessage sends a message in a producer span, with headers describing its event and carrying its trace context
+func sendMessage(ctx context.Context, producer sink.Producer, encoder *events.Encoder, message *sarama.ProducerMessage) (partition int32, offset int64, err error) {
+	ctx, span := headers.StartSpan(ctx, message.Topic)
+	defer span.End()
+
+	if err := headers.Set(ctx, message, encoder, message.Topic); err != nil {
+		return 0, 0, err
+	}
+	partition, offset, err = producer.SendMessage(message)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return partition, offset, err
+}
+
+// traceParent returns the traceparent header of a message
+func traceParent(message *sarama.ProducerMessage) string {
+	for _, h := range message.Headers {
+		if string(h.Key) == events.HeaderTraceParent {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// newProducer creates a producer like newProducerForTopic for messages to any of the configured topics. dp-kafka
+// requires its config to name a topic, but a sarama SyncProducer sends each message to its own topic, so the
+// content-updated topic is only named to satisfy that validation.
+func newProducer(kcfg *config.Kafka, strategy *partitioning.Strategy, configure func(*sarama.Config)) (sarama.SyncProducer, error) {
+	return newProducerForTopic(kcfg, kcfg.ContentUpdatedTopic, strategy, configure)
+}
+
+// newProducerForTopic creates a synchronous sarama producer with the config dp-kafka would use for the topic.
+// dp-kafka producers cannot set message keys or report when a message is acknowledged, which the producer needs. The
+// config is then adjusted by configure, such as to allow oversized messages.
+func newProducerForTopic(kcfg *config.Kafka, topic string, strategy *partitioning.Strategy, configure func(*sarama.Config)) (sarama.SyncProducer, error) {
 	pcfg := &kafka.ProducerConfig{
-		BrokerAddrs:     kcfg.Addr,
-		Topic:           topic,
-		KafkaVersion:    &kcfg.Version,
-		MaxMessageBytes: &kcfg.MaxBytes,
+		BrokerAddrs:       kcfg.Addr,
+		Topic:             topic,
+		KafkaVersion:      &kcfg.Version,
+		MaxMessageBytes:   &kcfg.MaxBytes,
+		MinBrokersHealthy: &kcfg.ProducerMinBrokersHealthy,
 	}
 	if kcfg.SecProtocol == config.KafkaTLSProtocol {
 		pcfg.SecurityConfig = kafka.GetSecurityConfig(
@@ -190,5 +463,14 @@ func newProducerForTopic(ctx context.Context, kcfg *config.Kafka, topic string)
 			kcfg.SecSkipVerify,
 		)
 	}
-	return kafka.NewProducer(ctx, pcfg)
+
+	saramaConfig, err := pcfg.Get()
+	if err != nil {
+		return nil, err
+	}
+	saramaConfig.Producer.Return.Successes = true
+	strategy.Configure(saramaConfig)
+	configure(saramaConfig)
+
+	return sarama.NewSyncProducer(kcfg.Addr, saramaConfig)
 }
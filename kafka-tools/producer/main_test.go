@@ -0,0 +1,520 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/partitioning"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/sink"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+	"github.com/Shopify/sarama"
+)
+
+var testKafka = &config.Kafka{
+	ContentUpdatedTopic:       "content-updated",
+	SearchContentUpdatedTopic: "search-content-updated",
+	SearchContentDeletedTopic: "search-content-deleted",
+	ContentUpdatedEncoding:    events.EncodingAvro,
+}
+
+func TestMarshalPayload(t *testing.T) {
+	Convey("Given an encoder with Avro content-updated events and JSON search events", t, func() {
+		encoder, err := events.NewEncoder(testKafka)
+		So(err, ShouldBeNil)
+
+		Convey("When a content-updated resource is marshalled", func() {
+			resource := models.ContentUpdatedResource{URI: "/economy", CollectionID: "COLLECTIONID"}
+			payload, eventType, err := marshalPayload(encoder, "content-updated", resource)
+
+			Convey("Then it is an Avro content published event", func() {
+				So(err, ShouldBeNil)
+				So(eventType, ShouldEqual, events.ContentPublishedEvent)
+				var r models.ContentUpdatedResource
+				So(schema.ContentPublishedEvent.Unmarshal(payload, &r), ShouldBeNil)
+				So(r, ShouldResemble, resource)
+			})
+		})
+
+		Convey("When a search-content-deleted resource is marshalled", func() {
+			resource := models.SearchContentDeletedResource{URI: "/economy"}
+			payload, eventType, err := marshalPayload(encoder, "search-content-deleted", resource)
+
+			Convey("Then it is a JSON search content deleted event", func() {
+				So(err, ShouldBeNil)
+				So(eventType, ShouldEqual, events.SearchContentDeletedEvent)
+				var r models.SearchContentDeletedResource
+				So(json.Unmarshal(payload, &r), ShouldBeNil)
+				So(r, ShouldResemble, resource)
+			})
+		})
+	})
+}
+
+func TestSendMessage(t *testing.T) {
+	Convey("Given a producer that captures its messages", t, func() {
+		encoder, err := events.NewEncoder(testKafka)
+		So(err, ShouldBeNil)
+		capture := sink.NewCapture(nil)
+
+		Convey("When messages are sent", func() {
+			for i := 0; i < 2; i++ {
+				message := &sarama.ProducerMessage{Topic: "search-content-deleted", Value: sarama.ByteEncoder(`{"uri":"/economy"}`)}
+				_, offset, err := sendMessage(context.Background(), capture, encoder, message)
+				So(err, ShouldBeNil)
+				So(offset, ShouldEqual, i)
+				So(traceParent(message), ShouldStartWith, "00-")
+			}
+
+			Convey("Then each is captured with the headers of its event and its own trace", func() {
+				messages := capture.Messages()
+				So(messages, ShouldHaveLength, 2)
+				So(messages[0].Headers[events.HeaderEventType], ShouldEqual, events.SearchContentDeletedEvent)
+				So(messages[0].Headers[events.HeaderContentType], ShouldEqual, events.ContentTypeJSON)
+				So(messages[0].Headers[events.HeaderTraceParent], ShouldNotEqual, messages[1].Headers[events.HeaderTraceParent])
+				So(string(messages[1].Value), ShouldEqual, `{"uri":"/economy"}`)
+			})
+		})
+	})
+}
+
+func TestReplay(t *testing.T) {
+	Convey("Given a capture of messages sent 100ms apart", t, func() {
+		started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		var buf bytes.Buffer
+		captured := sink.NewCapture(&buf)
+		for i, topic := range []string{"content-updated", "search-content-deleted", "content-updated"} {
+			So(captured.Record(sink.Message{
+				Topic:     topic,
+				Partition: 2,
+				Offset:    int64(10 + i),
+				Key:       fmt.Sprintf("/key/%d", i),
+				Headers:   map[string]string{events.HeaderTraceParent: fmt.Sprintf("00-trace-%d-01", i)},
+				Value:     []byte{byte(i)},
+				Timestamp: started.Add(time.Duration(i) * 100 * time.Millisecond),
+			}), ShouldBeNil)
+		}
+		reader := sink.NewReader(&buf)
+		first, err := reader.Next()
+		So(err, ShouldBeNil)
+		replayed := sink.NewCapture(nil)
+
+		Convey("When it is replayed at ten times the original speed", func() {
+			r := &replayer{producer: replayed, speed: 10, partition: partitioning.AnyPartition}
+			start := time.Now()
+			sent, err := r.replay(context.Background(), first, reader)
+			elapsed := time.Since(start)
+
+			Convey("Then every message is sent with its topic, key, headers and value, at the accelerated timing", func() {
+				So(err, ShouldBeNil)
+				So(sent, ShouldEqual, 3)
+				So(elapsed, ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+				So(elapsed, ShouldBeLessThan, 200*time.Millisecond)
+
+				messages := replayed.Messages()
+				So(messages, ShouldHaveLength, 3)
+				for i, m := range messages {
+					original := captured.Messages()[i]
+					So(m.Topic, ShouldEqual, original.Topic)
+					So(m.Key, ShouldEqual, original.Key)
+					So(m.Headers, ShouldResemble, original.Headers)
+					So(m.Value, ShouldResemble, original.Value)
+					So(m.Partition, ShouldEqual, 0)
+				}
+			})
+		})
+
+		Convey("When it is replayed to a partition as fast as possible", func() {
+			r := &replayer{producer: replayed, partition: 1}
+			start := time.Now()
+			sent, err := r.replay(context.Background(), first, reader)
+
+			Convey("Then every message is sent to the partition straight away", func() {
+				So(err, ShouldBeNil)
+				So(sent, ShouldEqual, 3)
+				So(time.Since(start), ShouldBeLessThan, 100*time.Millisecond)
+				for _, m := range replayed.Messages() {
+					So(m.Partition, ShouldEqual, 1)
+				}
+			})
+		})
+
+		Convey("When it is replayed at the original speed but cancelled", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			r := &replayer{producer: replayed, speed: 1, partition: partitioning.AnyPartition}
+			sent, err := r.replay(ctx, first, reader)
+
+			Convey("Then it stops before the messages that are not due yet", func() {
+				So(err, ShouldEqual, context.DeadlineExceeded)
+				So(sent, ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestParseLine(t *testing.T) {
+	Convey("Given lines of JSONL without a declared topic", t, func() {
+		Convey("When each type of resource is parsed, the topic is detected from its fields", func() {
+			for line, expected := range map[string]models.Resource{
+				`{"uri":"/economy","collection_id":"COLLECTIONID"}`:                  models.SearchContentDeletedResource{URI: "/economy", CollectionID: "COLLECTIONID"},
+				`{"uri":"/economy","data_type":"legacy","job_id":""}`:                models.ContentUpdatedResource{URI: "/economy", DataType: "legacy"},
+				`{"uri":"/releases/a-release","title":"A release","cancelled":true}`: models.SearchContentUpdatedResource{URI: "/releases/a-release", Title: "A release", Cancelled: true},
+			} {
+				topic, resource, err := parseLine(testKafka, "", []byte(line))
+				So(err, ShouldBeNil)
+				So(resource, ShouldResemble, expected)
+				expectedTopic, err := events.Topic(testKafka, expected)
+				So(err, ShouldBeNil)
+				So(topic, ShouldEqual, expectedTopic)
+			}
+		})
+
+		Convey("When a resource is wrapped in an envelope, its topic is the declared one", func() {
+			topic, resource, err := parseLine(testKafka, "", []byte(`{"topic":"content-updated","payload":{"uri":"/economy"}}`))
+			So(err, ShouldBeNil)
+			So(topic, ShouldEqual, "content-updated")
+			So(resource, ShouldResemble, models.ContentUpdatedResource{URI: "/economy"})
+		})
+
+		Convey("When lines cannot be parsed, the reason is returned", func() {
+			_, _, err := parseLine(testKafka, "", []byte(`{"uri":`))
+			So(err.Error(), ShouldStartWith, "invalid JSON")
+
+			_, _, err = parseLine(testKafka, "", []byte(`{"uri":"/economy","colour":"red"}`))
+			So(err.Error(), ShouldStartWith, "cannot detect the resource type of fields colour, uri")
+
+			_, _, err = parseLine(testKafka, "", []byte(`{"topic":"unknown","payload":{}}`))
+			So(err.Error(), ShouldEqual, `unknown topic "unknown" in envelope`)
+		})
+	})
+
+	Convey("Given a topic declared for every line", t, func() {
+		Convey("When a line that would be detected as another type is parsed, it is decoded for the declared topic", func() {
+			topic, resource, err := parseLine(testKafka, "search-content-updated", []byte(`{"uri":"/economy"}`))
+			So(err, ShouldBeNil)
+			So(topic, ShouldEqual, "search-content-updated")
+			So(resource, ShouldResemble, models.SearchContentUpdatedResource{URI: "/economy"})
+		})
+	})
+}
+
+func TestBulkPublish(t *testing.T) {
+	Convey("Given a bulk publisher with several workers that captures its messages", t, func() {
+		encoder, err := events.NewEncoder(testKafka)
+		So(err, ShouldBeNil)
+		strategy, err := partitioning.New(partitioning.KeyURI, partitioning.AnyPartition)
+		So(err, ShouldBeNil)
+		capture := sink.NewCapture(nil)
+		b := &bulkPublisher{kcfg: testKafka, producer: capture, encoder: encoder, strategy: strategy, concurrency: 4}
+
+		Convey("When a JSONL file with some invalid lines is published", func() {
+			var lines []string
+			for i := 0; i < 20; i++ {
+				lines = append(lines, fmt.Sprintf(`{"uri":"/uri/%d","title":"update %d"}`, i%3, i))
+			}
+			lines[4] = `{"uri":`
+			lines[9] = ""
+			lines[12] = `{"uri":"/uri/0","colour":"red"}`
+			lines = append(lines, `{"topic":"search-content-deleted","payload":{"uri":"/uri/1"}}`)
+			report, err := b.publish(context.Background(), strings.NewReader(strings.Join(lines, "\n")))
+
+			Convey("Then the valid lines are sent and each invalid line is reported by number", func() {
+				So(err, ShouldBeNil)
+				So(report.Lines, ShouldEqual, 20)
+				So(report.Sent, ShouldEqual, 18)
+				So(report.Failures, ShouldHaveLength, 2)
+				So(report.Failures[0].Line, ShouldEqual, 5)
+				So(report.Failures[1].Line, ShouldEqual, 13)
+				So(report.Failures[1].Error, ShouldContainSubstring, "colour")
+			})
+
+			Convey("Then the messages for each URI are sent in the order of the file, keyed by the URI", func() {
+				messages := capture.Messages()
+				So(messages, ShouldHaveLength, 18)
+				last := map[string]int{"/uri/0": -1, "/uri/1": -1, "/uri/2": -1}
+				for _, m := range messages {
+					if m.Topic == "search-content-deleted" {
+						So(last[m.Key], ShouldEqual, 19)
+						continue
+					}
+					var r models.SearchContentUpdatedResource
+					So(json.Unmarshal(m.Value, &r), ShouldBeNil)
+					So(m.Key, ShouldEqual, r.URI)
+					var n int
+					_, err := fmt.Sscanf(r.Title, "update %d", &n)
+					So(err, ShouldBeNil)
+					So(n, ShouldBeGreaterThan, last[m.Key])
+					last[m.Key] = n
+				}
+			})
+		})
+
+		Convey("When publishing is cancelled before the JSONL file has been read", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			report, err := b.publish(ctx, strings.NewReader(`{"uri":"/uri/0","title":"update 0"}`))
+
+			Convey("Then the cancellation is returned, so that the run is not reported as complete", func() {
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+				So(report.Lines, ShouldEqual, 0)
+				So(capture.Messages(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+// mockKafka starts a broker that leads a partition of each configured topic and acknowledges every message it is
+// sent, returning it with a config to produce to it
+func mockKafka(t *testing.T) (*sarama.MockBroker, *config.Config) {
+	broker := sarama.NewMockBroker(t, 1)
+	metadata := sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID())
+	for _, topic := range []string{testKafka.ContentUpdatedTopic, testKafka.SearchContentUpdatedTopic, testKafka.SearchContentDeletedTopic} {
+		metadata.SetLeader(topic, 0, broker.BrokerID())
+	}
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": metadata,
+		"ProduceRequest":  sarama.NewMockProduceResponse(t).SetVersion(3), // the version sent to kafka 1.0
+	})
+
+	kcfg := *testKafka
+	kcfg.Addr = []string{broker.Addr()}
+	kcfg.Version = "1.0.2"
+	kcfg.MaxBytes = 2000000
+	kcfg.ProducerMinBrokersHealthy = 1
+	return broker, &config.Config{Kafka: &kcfg}
+}
+
+// producedTopics returns the topic of each message acknowledged by the broker, in the order they were produced
+func producedTopics(broker *sarama.MockBroker) []string {
+	var topics []string
+	for _, rr := range broker.History() {
+		if response, ok := rr.Response.(*sarama.ProduceResponse); ok {
+			for topic := range response.Blocks {
+				topics = append(topics, topic)
+			}
+		}
+	}
+	return topics
+}
+
+func TestRunBulkToKafka(t *testing.T) {
+	Convey("Given a kafka broker and a JSONL file of resources for several topics, without -topic", t, func() {
+		broker, cfg := mockKafka(t)
+		defer broker.Close()
+
+		jsonl := filepath.Join(t.TempDir(), "resources.jsonl")
+		So(os.WriteFile(jsonl, []byte(`{"uri":"/economy","title":"Economy"}`+"\n"+
+			`{"topic":"search-content-deleted","payload":{"uri":"/economy"}}`+"\n"), 0o600), ShouldBeNil)
+
+		Convey("When the file is published to kafka", func() {
+			err := runBulk(context.Background(), cfg, options{
+				jsonl:       jsonl,
+				keyBy:       partitioning.KeyNone,
+				partition:   partitioning.AnyPartition,
+				concurrency: 1,
+			})
+
+			Convey("Then each line is produced to its own topic", func() {
+				So(err, ShouldBeNil)
+				So(producedTopics(broker), ShouldResemble, []string{testKafka.SearchContentUpdatedTopic, testKafka.SearchContentDeletedTopic})
+			})
+		})
+	})
+}
+
+func TestRunReplayToKafka(t *testing.T) {
+	Convey("Given a kafka broker and a capture of messages for several topics", t, func() {
+		broker, cfg := mockKafka(t)
+		defer broker.Close()
+
+		file := filepath.Join(t.TempDir(), "capture.ndjson")
+		captured, err := sink.OpenCapture(file)
+		So(err, ShouldBeNil)
+		for _, topic := range []string{testKafka.SearchContentDeletedTopic, testKafka.ContentUpdatedTopic} {
+			So(captured.Record(sink.Message{Topic: topic, Value: []byte("{}"), Timestamp: time.Now()}), ShouldBeNil)
+		}
+		So(captured.Close(), ShouldBeNil)
+
+		Convey("When the capture is replayed to kafka", func() {
+			err := runReplay(context.Background(), cfg, options{replay: file, partition: partitioning.AnyPartition})
+
+			Convey("Then each message is produced to its own topic", func() {
+				So(err, ShouldBeNil)
+				So(producedTopics(broker), ShouldResemble, []string{testKafka.SearchContentDeletedTopic, testKafka.ContentUpdatedTopic})
+			})
+		})
+	})
+}
+
+func TestParseFlags(t *testing.T) {
+	Convey("Given the producer's command line flags", t, func() {
+		newFlags := func() *flag.FlagSet {
+			flags := flag.NewFlagSet("producer", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			return flags
+		}
+
+		Convey("When no flags are given, the producer is interactive with the default options", func() {
+			opts, interactive, err := parseFlags(newFlags(), nil)
+			So(err, ShouldBeNil)
+			So(interactive, ShouldBeTrue)
+			So(opts, ShouldResemble, options{
+				count:       1,
+				keyBy:       partitioning.KeyNone,
+				partition:   partitioning.AnyPartition,
+				concurrency: 1,
+			})
+		})
+
+		Convey("When flags are given, the producer is not interactive and they are parsed into the options", func() {
+			opts, interactive, err := parseFlags(newFlags(), []string{
+				"-topic", "content-updated", "-fixture", "bit_content_update", "-count", "3", "-key-by", "uri", "-partition", "2",
+			})
+			So(err, ShouldBeNil)
+			So(interactive, ShouldBeFalse)
+			So(opts.topic, ShouldEqual, "content-updated")
+			So(opts.fixture, ShouldEqual, "bit_content_update")
+			So(opts.count, ShouldEqual, 3)
+			So(opts.keyBy, ShouldEqual, partitioning.KeyURI)
+			So(opts.partition, ShouldEqual, 2)
+		})
+
+		Convey("When flags that are not defined or not valid are given, an error is returned", func() {
+			for _, args := range [][]string{
+				{"-colour", "red"},
+				{"-count", "many"},
+				{"-record-for", "a while"},
+			} {
+				_, _, err := parseFlags(newFlags(), args)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
+
+func TestSelectTopicAndResource(t *testing.T) {
+	Convey("Given the embedded fixtures", t, func() {
+		ctx := context.Background()
+		cfg := &config.Config{Kafka: testKafka}
+		store := &data.ResourceStore{}
+
+		fixture, err := store.GetFixture(ctx, "content_updated/bit_content_update.json")
+		So(err, ShouldBeNil)
+		deleted, err := store.GetResourcesWithType(ctx, "SearchContentDeletedResource", data.Options{Limit: 100})
+		So(err, ShouldBeNil)
+
+		Convey("When the topic and resource are selected by the flags, the selected resource is returned", func() {
+			cases := []struct {
+				description string
+				opts        options
+				expected    models.Resource
+			}{
+				{
+					"a fixture by its path",
+					options{topic: "content-updated", fixture: "content_updated/bit_content_update.json", count: 1},
+					fixture,
+				},
+				{
+					"a fixture by its file name",
+					options{topic: "content-updated", fixture: "bit_content_update.json", count: 1},
+					fixture,
+				},
+				{
+					"a fixture by its file name without the extension",
+					options{topic: "content-updated", fixture: "bit_content_update", count: 1},
+					fixture,
+				},
+				{
+					"a resource by its index",
+					options{topic: "search-content-deleted", index: len(deleted.Items), count: 2},
+					deleted.Items[len(deleted.Items)-1],
+				},
+				{
+					"an inline JSON resource",
+					options{topic: "search-content-deleted", json: `{"uri":"/economy","collection_id":"COLLECTIONID"}`, count: 1},
+					models.SearchContentDeletedResource{URI: "/economy", CollectionID: "COLLECTIONID"},
+				},
+			}
+
+			for _, tc := range cases {
+				topic, selected, err := selectTopicAndResource(ctx, cfg, tc.opts)
+				So(err, ShouldBeNil)
+				So(topic, ShouldEqual, tc.opts.topic)
+				So(selected, ShouldResemble, tc.expected)
+			}
+		})
+
+		Convey("When flags that cannot select a resource are given, the reason is returned", func() {
+			cases := []struct {
+				opts     options
+				expected string
+			}{
+				{options{fixture: "bit_content_update", count: 1}, `unknown topic "": -topic must be one of content-updated, search-content-updated or search-content-deleted`},
+				{options{topic: "colours", fixture: "bit_content_update", count: 1}, `unknown topic "colours": -topic must be one of content-updated, search-content-updated or search-content-deleted`},
+				{options{topic: "content-updated", count: 1}, "exactly one of -fixture, -index or -json must be given"},
+				{options{topic: "content-updated", fixture: "bit_content_update", index: 1, count: 1}, "exactly one of -fixture, -index or -json must be given"},
+				{options{topic: "content-updated", index: 1, json: "{}", count: 1}, "exactly one of -fixture, -index or -json must be given"},
+				{options{topic: "content-updated", fixture: "bit_content_update", count: 0}, "-count must be at least 1"},
+				{options{topic: "content-updated", fixture: "missing", count: 1}, `fixture "missing": fixture not found`},
+				{options{topic: "search-content-deleted", index: len(deleted.Items) + 1, count: 1}, fmt.Sprintf("-index must be between 1 and %d for topic search-content-deleted", len(deleted.Items))},
+				{options{topic: "search-content-deleted", index: -1, count: 1}, fmt.Sprintf("-index must be between 1 and %d for topic search-content-deleted", len(deleted.Items))},
+				{options{topic: "search-content-deleted", json: `{"uri":`, count: 1}, "invalid JSON resource for topic search-content-deleted"},
+			}
+
+			for _, tc := range cases {
+				_, selected, err := selectTopicAndResource(ctx, cfg, tc.opts)
+				So(selected, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, tc.expected)
+			}
+		})
+	})
+}
+
+func TestPromptTopicAndResource(t *testing.T) {
+	Convey("Given answers to the prompts on stdin, including answers that are not valid", t, func() {
+		r, w, err := os.Pipe()
+		So(err, ShouldBeNil)
+		_, err = w.WriteString("9\n3\nfirst\n1\n")
+		So(err, ShouldBeNil)
+		So(w.Close(), ShouldBeNil)
+
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		So(err, ShouldBeNil)
+
+		stdin, stdout := os.Stdin, os.Stdout
+		os.Stdin, os.Stdout = r, devNull
+		Reset(func() {
+			os.Stdin, os.Stdout = stdin, stdout
+			_ = devNull.Close()
+		})
+
+		Convey("When the producer prompts for the topic and resource", func() {
+			topic, selected, err := promptTopicAndResource(context.Background(), &config.Config{Kafka: testKafka})
+
+			Convey("Then it prompts again after each invalid answer, and the chosen resource is returned", func() {
+				So(err, ShouldBeNil)
+				So(topic, ShouldEqual, "search-content-deleted")
+				deleted, err := (&data.ResourceStore{}).GetResourcesWithType(context.Background(), "SearchContentDeletedResource", data.Options{Limit: 1})
+				So(err, ShouldBeNil)
+				So(selected, ShouldResemble, deleted.Items[0])
+			})
+		})
+	})
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/partitioning"
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/sink"
+	kafka "github.com/ONSdigital/dp-kafka/v4"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/Shopify/sarama"
+)
+
+// recorderGroup is the consumer group the recorder's config is built for. The recorder reads each partition directly,
+// so it never joins the group or commits offsets.
+const recorderGroup = serviceName + "-recorder"
+
+// replayer sends captured messages, keeping the time between them scaled by speed
+type replayer struct {
+	producer  sink.Producer
+	speed     float64
+	partition int
+}
+
+// runReplay replays the messages captured in the file given by the flags, to kafka or to another capture
+func runReplay(ctx context.Context, cfg *config.Config, opts options) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	f, err := os.Open(opts.replay)
+	if err != nil {
+		log.Error(ctx, "failed to open capture to replay", err)
+		return err
+	}
+	defer f.Close()
+
+	reader := sink.NewReader(f)
+	first, err := reader.Next()
+	if errors.Is(err, io.EOF) {
+		log.Info(ctx, "no messages to replay", log.Data{"file": opts.replay})
+		return nil
+	}
+	if err != nil {
+		log.Error(ctx, "failed to read capture to replay", err)
+		return err
+	}
+
+	strategy, err := partitioning.New(partitioning.KeyNone, opts.partition)
+	if err != nil {
+		log.Error(ctx, "invalid partitioning", err)
+		return err
+	}
+
+	var producer sink.Producer
+	if opts.capture != "" {
+		producer, err = sink.OpenCapture(opts.capture)
+	} else {
+		// a capture can hold messages for several topics, and each is replayed to its own topic
+		producer, err = newProducer(cfg.Kafka, strategy, func(*sarama.Config) {})
+	}
+	if err != nil {
+		log.Error(ctx, "fatal error creating kafka producer", err)
+		return err
+	}
+	defer func() {
+		if cerr := producer.Close(); cerr != nil {
+			log.Error(ctx, "failed to close producer", cerr)
+		}
+	}()
+
+	r := &replayer{producer: producer, speed: opts.speed, partition: opts.partition}
+	start := time.Now()
+	sent, err := r.replay(ctx, first, reader)
+	log.Info(ctx, "replayed messages", log.Data{"file": opts.replay, "sent": sent, "elapsed": time.Since(start).String()})
+	if err != nil {
+		log.Error(ctx, "failed to replay messages", err)
+	}
+	return err
+}
+
+// replay sends the first message and then each message from the reader, returning the number sent. With a speed,
+// each message is sent when the time since its timestamp and the first message's, divided by the speed, has passed.
+// Without one, they are sent as fast as they are acknowledged.
+func (r *replayer) replay(ctx context.Context, first sink.Message, reader *sink.Reader) (int, error) {
+	start := time.Now()
+	sent := 0
+	for m, err := first, error(nil); ; m, err = reader.Next() {
+		if errors.Is(err, io.EOF) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, err
+		}
+
+		if r.speed > 0 {
+			due := start.Add(time.Duration(float64(m.Timestamp.Sub(first.Timestamp)) / r.speed))
+			if err := sleepUntil(ctx, due); err != nil {
+				return sent, err
+			}
+		}
+
+		message := m.ProducerMessage()
+		if r.partition != partitioning.AnyPartition {
+			message.Partition = int32(r.partition)
+		}
+		partition, offset, err := r.producer.SendMessage(message)
+		if err != nil {
+			return sent, fmt.Errorf("failed to replay message %d: %w", sent+1, err)
+		}
+		sent++
+
+		log.Info(ctx, "message replayed", log.Data{
+			"topic":     m.Topic,
+			"key":       m.Key,
+			"partition": partition,
+			"offset":    offset,
+			"captured":  m.Timestamp,
+		})
+	}
+}
+
+// sleepUntil waits until the time, unless the context is done first
+func sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runRecord records the messages sent to the topics given by the flags to the capture file, from now until the
+// recording time is up or the recorder is interrupted
+func runRecord(ctx context.Context, cfg *config.Config, opts options) error {
+	if opts.capture == "" {
+		err := errors.New("-capture must be given to record to")
+		log.Error(ctx, "invalid recording", err)
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if opts.recordFor > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.recordFor)
+		defer cancel()
+	}
+
+	capture, err := sink.OpenCapture(opts.capture)
+	if err != nil {
+		log.Error(ctx, "failed to open capture", err)
+		return err
+	}
+	defer func() {
+		if cerr := capture.Close(); cerr != nil {
+			log.Error(ctx, "failed to close capture", cerr)
+		}
+	}()
+
+	var topics []string
+	for _, topic := range strings.Split(opts.record, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+
+	recorded, err := record(ctx, cfg.Kafka, topics, capture)
+	log.Info(ctx, "recorded messages", log.Data{"topics": topics, "file": opts.capture, "recorded": recorded})
+	if err != nil {
+		log.Error(ctx, "failed to record messages", err)
+	}
+	return err
+}
+
+// record consumes every partition of the topics from their newest offsets into the capture until the context is done,
+// returning the number of messages recorded
+func record(ctx context.Context, kcfg *config.Kafka, topics []string, capture *sink.Capture) (int, error) {
+	offset := kafka.OffsetNewest
+	groupConfig := &kafka.ConsumerGroupConfig{
+		BrokerAddrs:  kcfg.Addr,
+		Topic:        strings.Join(topics, ","),
+		GroupName:    recorderGroup,
+		KafkaVersion: &kcfg.Version,
+		Offset:       &offset,
+	}
+	if kcfg.SecProtocol == config.KafkaTLSProtocol {
+		groupConfig.SecurityConfig = kafka.GetSecurityConfig(
+			kcfg.SecCACerts,
+			kcfg.SecClientCert,
+			kcfg.SecClientKey,
+			kcfg.SecSkipVerify,
+		)
+	}
+	saramaConfig, err := groupConfig.Get()
+	if err != nil {
+		return 0, err
+	}
+
+	consumer, err := sarama.NewConsumer(kcfg.Addr, saramaConfig)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := consumer.Close(); cerr != nil {
+			log.Error(ctx, "failed to close recording consumer", cerr)
+		}
+	}()
+
+	var partitionConsumers []sarama.PartitionConsumer
+	defer func() {
+		for _, pc := range partitionConsumers {
+			pc.AsyncClose()
+		}
+	}()
+	for _, topic := range topics {
+		partitions, err := consumer.Partitions(topic)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get partitions of topic %q: %w", topic, err)
+		}
+		for _, partition := range partitions {
+			pc, err := consumer.ConsumePartition(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return 0, fmt.Errorf("failed to consume partition %d of topic %q: %w", partition, topic, err)
+			}
+			partitionConsumers = append(partitionConsumers, pc)
+		}
+	}
+	log.Info(ctx, "recording messages", log.Data{"topics": topics, "partitions": len(partitionConsumers)})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	recorded := 0
+	var recordErr error
+	for _, pc := range partitionConsumers {
+		wg.Add(1)
+		go func(pc sarama.PartitionConsumer) {
+			defer wg.Done()
+			errs := pc.Errors()
+			for {
+				select {
+				case message, ok := <-pc.Messages():
+					if !ok {
+						return
+					}
+					err := capture.Record(sink.Consumed(message))
+					mutex.Lock()
+					if err == nil {
+						recorded++
+					} else if recordErr == nil {
+						recordErr = err
+						cancel()
+					}
+					mutex.Unlock()
+				case err, ok := <-errs:
+					if !ok {
+						// stop selecting the closed channel, which would otherwise be ready forever
+						errs = nil
+						continue
+					}
+					log.Error(ctx, "recording consumer error", err)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(pc)
+	}
+	wg.Wait()
+	return recorded, recordErr
+}
@@ -0,0 +1,219 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// ErrClosed is returned when a message is sent to a closed producer
+var ErrClosed = errors.New("producer is closed")
+
+// Producer sends messages and waits for them to be acknowledged. It is satisfied by sarama.SyncProducer, for sending
+// to kafka, and by Capture, for running the kafka tools without brokers.
+type Producer interface {
+	SendMessage(message *sarama.ProducerMessage) (partition int32, offset int64, err error)
+	Close() error
+}
+
+// Message is a message captured by a Capture, as written to NDJSON. The value is base64 encoded in JSON, as Avro
+// payloads are binary.
+type Message struct {
+	Topic     string            `json:"topic"`
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Value     []byte            `json:"value"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Capture is an in-memory stand-in for kafka that acknowledges every message it is sent, numbering the messages sent to
+// each partition of a topic from offset 0. Messages are in the partition they are sent to, which is 0 unless one is
+// set. If it has a writer, each message is also written to it as a line of NDJSON.
+type Capture struct {
+	mutex    sync.Mutex
+	messages []Message
+	offsets  map[string]int64
+	encoder  *json.Encoder
+	closer   io.Closer
+	closed   bool
+}
+
+var _ Producer = (*Capture)(nil)
+
+// NewCapture creates a Capture that writes the messages it is sent to w, or keeps them in memory only if w is nil
+func NewCapture(w io.Writer) *Capture {
+	c := &Capture{offsets: map[string]int64{}}
+	if w != nil {
+		c.encoder = json.NewEncoder(w)
+	}
+	return c
+}
+
+// OpenCapture creates a Capture that writes the messages it is sent to the file at path. The file is created, or
+// truncated if it exists, and is closed when the Capture is.
+func OpenCapture(path string) (*Capture, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create capture file: %w", err)
+	}
+	c := NewCapture(f)
+	c.closer = f
+	return c, nil
+}
+
+// SendMessage captures a message, setting its partition and offset as kafka would
+func (c *Capture) SendMessage(message *sarama.ProducerMessage) (partition int32, offset int64, err error) {
+	captured := Message{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Timestamp: time.Now().UTC(),
+	}
+	if message.Key != nil {
+		key, err := message.Key.Encode()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to encode key: %w", err)
+		}
+		captured.Key = string(key)
+	}
+	if message.Value != nil {
+		if captured.Value, err = message.Value.Encode(); err != nil {
+			return 0, 0, fmt.Errorf("failed to encode value: %w", err)
+		}
+	}
+	if len(message.Headers) > 0 {
+		captured.Headers = make(map[string]string, len(message.Headers))
+		for _, h := range message.Headers {
+			captured.Headers[string(h.Key)] = string(h.Value)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return 0, 0, ErrClosed
+	}
+
+	id := fmt.Sprintf("%s/%d", captured.Topic, captured.Partition)
+	captured.Offset = c.offsets[id]
+	if c.encoder != nil {
+		if err := c.encoder.Encode(captured); err != nil {
+			return 0, 0, fmt.Errorf("failed to write captured message: %w", err)
+		}
+	}
+	c.offsets[id]++
+	c.messages = append(c.messages, captured)
+
+	message.Partition, message.Offset = captured.Partition, captured.Offset
+	return captured.Partition, captured.Offset, nil
+}
+
+// Record captures a message as it is, such as one consumed from kafka, keeping its partition, offset and timestamp
+func (c *Capture) Record(message Message) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	if c.encoder != nil {
+		if err := c.encoder.Encode(message); err != nil {
+			return fmt.Errorf("failed to write captured message: %w", err)
+		}
+	}
+	c.messages = append(c.messages, message)
+	return nil
+}
+
+// Messages returns the messages captured so far, in the order they were sent
+func (c *Capture) Messages() []Message {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// Reader reads captured messages from NDJSON, one at a time so that large captures can be replayed
+type Reader struct {
+	decoder *json.Decoder
+	line    int
+}
+
+// NewReader creates a Reader of the captured messages in r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{decoder: json.NewDecoder(r)}
+}
+
+// Next returns the next captured message, or io.EOF once there are no more
+func (r *Reader) Next() (Message, error) {
+	var message Message
+	if err := r.decoder.Decode(&message); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Message{}, io.EOF
+		}
+		return Message{}, fmt.Errorf("invalid captured message %d: %w", r.line+1, err)
+	}
+	r.line++
+	return message, nil
+}
+
+// Consumed returns a captured message of a message consumed from kafka
+func Consumed(message *sarama.ConsumerMessage) Message {
+	captured := Message{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Key:       string(message.Key),
+		Value:     message.Value,
+		Timestamp: message.Timestamp.UTC(),
+	}
+	if len(message.Headers) > 0 {
+		captured.Headers = make(map[string]string, len(message.Headers))
+		for _, h := range message.Headers {
+			captured.Headers[string(h.Key)] = string(h.Value)
+		}
+	}
+	return captured
+}
+
+// ProducerMessage returns a message to send the captured message to its topic with its key, headers and value. Its
+// headers are in order of their keys, and it is sent to any partition.
+func (m Message) ProducerMessage() *sarama.ProducerMessage {
+	message := &sarama.ProducerMessage{Topic: m.Topic, Value: sarama.ByteEncoder(m.Value)}
+	if m.Key != "" {
+		message.Key = sarama.StringEncoder(m.Key)
+	}
+
+	keys := make([]string, 0, len(m.Headers))
+	for key := range m.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		message.Headers = append(message.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(m.Headers[key])})
+	}
+	return message
+}
+
+// Close stops the Capture accepting messages, closing the file it writes to if it opened one
+func (c *Capture) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if c.closer != nil {
+		return c.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package sink_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/kafka-tools/sink"
+	"github.com/Shopify/sarama"
+)
+
+func newMessage(topic string, partition int32, key string) *sarama.ProducerMessage {
+	message := &sarama.ProducerMessage{
+		Topic:     topic,
+		Partition: partition,
+		Value:     sarama.ByteEncoder([]byte{0, 1, 2}),
+		Headers:   []sarama.RecordHeader{{Key: []byte("event-type"), Value: []byte("ContentPublishedEvent")}},
+	}
+	if key != "" {
+		message.Key = sarama.StringEncoder(key)
+	}
+	return message
+}
+
+func TestCapture(t *testing.T) {
+	Convey("Given a capture without a writer", t, func() {
+		c := sink.NewCapture(nil)
+
+		Convey("When messages are sent to partitions of topics", func() {
+			sent := []*sarama.ProducerMessage{
+				newMessage("content-updated", 0, "/economy"),
+				newMessage("content-updated", 0, ""),
+				newMessage("content-updated", 2, ""),
+				newMessage("search-content-deleted", 0, ""),
+			}
+			var offsets []int64
+			for _, message := range sent {
+				_, offset, err := c.SendMessage(message)
+				So(err, ShouldBeNil)
+				offsets = append(offsets, offset)
+			}
+
+			Convey("Then each partition of a topic numbers its messages from 0", func() {
+				So(offsets, ShouldResemble, []int64{0, 1, 0, 0})
+				So(sent[1].Offset, ShouldEqual, 1)
+			})
+
+			Convey("Then the messages are captured in order", func() {
+				messages := c.Messages()
+				So(messages, ShouldHaveLength, 4)
+				So(messages[0].Topic, ShouldEqual, "content-updated")
+				So(messages[0].Key, ShouldEqual, "/economy")
+				So(messages[0].Value, ShouldResemble, []byte{0, 1, 2})
+				So(messages[0].Headers, ShouldResemble, map[string]string{"event-type": "ContentPublishedEvent"})
+				So(messages[1].Key, ShouldBeEmpty)
+				So(messages[2].Partition, ShouldEqual, 2)
+				So(messages[3].Topic, ShouldEqual, "search-content-deleted")
+			})
+		})
+
+		Convey("When a message is sent once it is closed", func() {
+			So(c.Close(), ShouldBeNil)
+			_, _, err := c.SendMessage(newMessage("content-updated", 0, ""))
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldEqual, sink.ErrClosed)
+				So(c.Messages(), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given a capture with a writer", t, func() {
+		var buf bytes.Buffer
+		c := sink.NewCapture(&buf)
+
+		Convey("When messages are sent", func() {
+			for i := 0; i < 3; i++ {
+				_, _, err := c.SendMessage(newMessage("content-updated", 0, "/economy"))
+				So(err, ShouldBeNil)
+			}
+
+			Convey("Then each is written as a line of NDJSON", func() {
+				scanner := bufio.NewScanner(&buf)
+				var lines []sink.Message
+				for scanner.Scan() {
+					var m sink.Message
+					So(json.Unmarshal(scanner.Bytes(), &m), ShouldBeNil)
+					lines = append(lines, m)
+				}
+				So(lines, ShouldHaveLength, 3)
+				So(lines[2].Offset, ShouldEqual, 2)
+				So(lines[2].Value, ShouldResemble, []byte{0, 1, 2})
+				So(lines, ShouldResemble, c.Messages())
+			})
+		})
+	})
+
+	Convey("Given a capture to a file", t, func() {
+		path := filepath.Join(t.TempDir(), "capture.ndjson")
+		c, err := sink.OpenCapture(path)
+		So(err, ShouldBeNil)
+
+		Convey("When a message is sent and the capture is closed", func() {
+			_, _, err := c.SendMessage(newMessage("content-updated", 0, ""))
+			So(err, ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+
+			Convey("Then the message is in the file", func() {
+				b, err := os.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(b), ShouldStartWith, `{"topic":"content-updated","partition":0,"offset":0,`)
+			})
+		})
+	})
+}
+
+func TestRecord(t *testing.T) {
+	Convey("Given a capture with a writer and a message consumed from kafka", t, func() {
+		var buf bytes.Buffer
+		c := sink.NewCapture(&buf)
+		consumed := &sarama.ConsumerMessage{
+			Topic:     "search-content-updated",
+			Partition: 3,
+			Offset:    42,
+			Key:       []byte("/releases/a-release"),
+			Value:     []byte(`{"uri":"/releases/a-release"}`),
+			Headers:   []*sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte("00-abc-def-01")}},
+			Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+
+		Convey("When it is recorded", func() {
+			So(c.Record(sink.Consumed(consumed)), ShouldBeNil)
+
+			Convey("Then it is captured with its partition, offset and timestamp", func() {
+				messages := c.Messages()
+				So(messages, ShouldHaveLength, 1)
+				So(messages[0], ShouldResemble, sink.Message{
+					Topic:     "search-content-updated",
+					Partition: 3,
+					Offset:    42,
+					Key:       "/releases/a-release",
+					Headers:   map[string]string{"traceparent": "00-abc-def-01"},
+					Value:     []byte(`{"uri":"/releases/a-release"}`),
+					Timestamp: consumed.Timestamp,
+				})
+			})
+
+			Convey("Then it can be read back from the NDJSON", func() {
+				r := sink.NewReader(&buf)
+				m, err := r.Next()
+				So(err, ShouldBeNil)
+				So(m, ShouldResemble, c.Messages()[0])
+
+				_, err = r.Next()
+				So(err, ShouldEqual, io.EOF)
+			})
+		})
+	})
+}
+
+func TestReader(t *testing.T) {
+	Convey("Given NDJSON with an invalid second line", t, func() {
+		r := sink.NewReader(strings.NewReader(`{"topic":"content-updated","value":"AAEC"}` + "\n" + `{"topic":` + "\n"))
+
+		Convey("When the messages are read", func() {
+			first, err := r.Next()
+			So(err, ShouldBeNil)
+			_, err = r.Next()
+
+			Convey("Then the first is read and the second is reported as invalid", func() {
+				So(first.Topic, ShouldEqual, "content-updated")
+				So(first.Value, ShouldResemble, []byte{0, 1, 2})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "invalid captured message 2")
+			})
+		})
+	})
+}
+
+func TestProducerMessage(t *testing.T) {
+	Convey("Given a captured message", t, func() {
+		m := sink.Message{
+			Topic:     "content-updated",
+			Partition: 3,
+			Offset:    42,
+			Key:       "/economy",
+			Headers:   map[string]string{"traceparent": "00-abc-def-01", "content-type": "avro/binary"},
+			Value:     []byte{0, 1, 2},
+		}
+
+		Convey("When it is converted to a message to send", func() {
+			message := m.ProducerMessage()
+
+			Convey("Then it has the same key, value and headers, in order, for any partition", func() {
+				So(message.Topic, ShouldEqual, "content-updated")
+				So(message.Key, ShouldEqual, sarama.StringEncoder("/economy"))
+				So(message.Value, ShouldResemble, sarama.ByteEncoder([]byte{0, 1, 2}))
+				So(message.Partition, ShouldEqual, 0)
+				So(message.Headers, ShouldResemble, []sarama.RecordHeader{
+					{Key: []byte("content-type"), Value: []byte("avro/binary")},
+					{Key: []byte("traceparent"), Value: []byte("00-abc-def-01")},
+				})
+			})
+		})
+	})
+}
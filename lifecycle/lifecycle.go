@@ -0,0 +1,78 @@
+package lifecycle
+
+import (
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// State is the stage a release has reached in its lifecycle
+type State string
+
+// The states a release moves through. Provisional releases have a provisional date only, confirmed releases are
+// finalised ahead of their release date, and at the release date they are either published or cancelled.
+const (
+	Provisional State = "provisional"
+	Confirmed   State = "confirmed"
+	Published   State = "published"
+	Cancelled   State = "cancelled"
+)
+
+// Lifecycle works out the state of each release from its release date and the time on the clock.
+//
+// A release is provisional until the confirmation period before its release date, confirmed from then until its
+// release date, and published from its release date. A release whose fixture is cancelled but not published is
+// scheduled to be cancelled: it goes through the same states, but is cancelled rather than published at its release
+// date. Only releases that have yet to be published take part: releases that are already published keep their flags.
+type Lifecycle struct {
+	Clock              clock.Clock
+	ConfirmationPeriod time.Duration
+}
+
+// Apply returns the resource with the cancelled, finalised and published flags of its state at the current time.
+// Resources that are not releases, releases that are already published, and releases whose release date is not a
+// valid date-time are returned unchanged.
+func (l *Lifecycle) Apply(resource models.SearchContentUpdatedResource) models.SearchContentUpdatedResource {
+	if resource.ContentType != models.ReleaseContentType || resource.Published {
+		return resource
+	}
+
+	releaseDate, err := time.Parse(time.RFC3339, resource.ReleaseDate)
+	if err != nil {
+		return resource
+	}
+
+	now := l.Clock.Now()
+	switch {
+	case !now.Before(releaseDate) && resource.Cancelled:
+		return withState(resource, Cancelled)
+	case !now.Before(releaseDate):
+		return withState(resource, Published)
+	case !now.Before(releaseDate.Add(-l.ConfirmationPeriod)):
+		return withState(resource, Confirmed)
+	default:
+		return withState(resource, Provisional)
+	}
+}
+
+// StateOf returns the state of a release given by its cancelled, finalised and published flags
+func StateOf(resource models.SearchContentUpdatedResource) State {
+	switch {
+	case resource.Cancelled:
+		return Cancelled
+	case resource.Published:
+		return Published
+	case resource.Finalised:
+		return Confirmed
+	default:
+		return Provisional
+	}
+}
+
+func withState(resource models.SearchContentUpdatedResource, state State) models.SearchContentUpdatedResource {
+	resource.Cancelled = state == Cancelled
+	resource.Finalised = state != Provisional
+	resource.Published = state == Published
+	return resource
+}
@@ -0,0 +1,121 @@
+package lifecycle_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/lifecycle"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+var releaseDate = time.Date(2030, 3, 1, 9, 30, 0, 0, time.UTC)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// testRelease returns a release that is yet to be released, with the flags of its fixture
+func testRelease() models.SearchContentUpdatedResource {
+	return models.SearchContentUpdatedResource{
+		URI:             "/releases/test",
+		ContentType:     models.ReleaseContentType,
+		ReleaseDate:     releaseDate.Format(time.RFC3339),
+		ProvisionalDate: "March 2030",
+		Finalised:       true,
+	}
+}
+
+func TestApply(t *testing.T) {
+	Convey("Given a lifecycle with a confirmation period of a week", t, func() {
+		c := &fakeClock{}
+		l := &lifecycle.Lifecycle{Clock: c, ConfirmationPeriod: 7 * 24 * time.Hour}
+
+		cases := []struct {
+			description string
+			now         time.Time
+			state       lifecycle.State
+		}{
+			{"before the confirmation period", releaseDate.Add(-8 * 24 * time.Hour), lifecycle.Provisional},
+			{"at the start of the confirmation period", releaseDate.Add(-7 * 24 * time.Hour), lifecycle.Confirmed},
+			{"just before the release date", releaseDate.Add(-time.Second), lifecycle.Confirmed},
+			{"at the release date", releaseDate, lifecycle.Published},
+			{"after the release date", releaseDate.Add(time.Hour), lifecycle.Published},
+		}
+
+		for _, tc := range cases {
+			Convey("When a release is applied "+tc.description, func() {
+				c.now = tc.now
+				release := l.Apply(testRelease())
+
+				Convey("Then its flags describe the "+string(tc.state)+" state", func() {
+					So(lifecycle.StateOf(release), ShouldEqual, tc.state)
+					So(release.Cancelled, ShouldBeFalse)
+					So(release.Finalised, ShouldEqual, tc.state != lifecycle.Provisional)
+					So(release.Published, ShouldEqual, tc.state == lifecycle.Published)
+				})
+			})
+		}
+
+		Convey("When resources that cannot be scheduled are applied", func() {
+			c.now = releaseDate.Add(-8 * 24 * time.Hour)
+
+			standard := testRelease()
+			standard.ContentType = "bulletin"
+			invalid := testRelease()
+			invalid.ReleaseDate = "an invalid date"
+
+			Convey("Then they are returned unchanged", func() {
+				So(l.Apply(standard), ShouldResemble, standard)
+				So(l.Apply(invalid), ShouldResemble, invalid)
+			})
+		})
+
+		Convey("When a release that is already published is applied", func() {
+			c.now = releaseDate.Add(-8 * 24 * time.Hour)
+
+			published := testRelease()
+			published.Published = true
+			cancelled := testRelease()
+			cancelled.Cancelled = true
+			cancelled.Published = true
+
+			Convey("Then it keeps its flags", func() {
+				So(l.Apply(published), ShouldResemble, published)
+				So(l.Apply(cancelled), ShouldResemble, cancelled)
+			})
+		})
+
+		cancelledCases := []struct {
+			description string
+			now         time.Time
+			state       lifecycle.State
+		}{
+			{"before the confirmation period", releaseDate.Add(-8 * 24 * time.Hour), lifecycle.Provisional},
+			{"at the start of the confirmation period", releaseDate.Add(-7 * 24 * time.Hour), lifecycle.Confirmed},
+			{"at the release date", releaseDate, lifecycle.Cancelled},
+			{"after the release date", releaseDate.Add(time.Hour), lifecycle.Cancelled},
+		}
+
+		for _, tc := range cancelledCases {
+			Convey("When a release scheduled to be cancelled is applied "+tc.description, func() {
+				c.now = tc.now
+				scheduled := testRelease()
+				scheduled.Cancelled = true
+				release := l.Apply(scheduled)
+
+				Convey("Then its flags describe the "+string(tc.state)+" state", func() {
+					So(lifecycle.StateOf(release), ShouldEqual, tc.state)
+					So(release.Cancelled, ShouldEqual, tc.state == lifecycle.Cancelled)
+					So(release.Finalised, ShouldEqual, tc.state != lifecycle.Provisional)
+					So(release.Published, ShouldBeFalse)
+				})
+			})
+		}
+	})
+}
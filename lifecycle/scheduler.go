@@ -0,0 +1,127 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// ReleaseStore returns the releases currently served by the stub, with their lifecycle applied
+type ReleaseStore interface {
+	GetReleases(ctx context.Context) ([]models.SearchContentUpdatedResource, error)
+}
+
+// EventEmitter emits the event corresponding to a change to a resource
+type EventEmitter interface {
+	Emit(ctx context.Context, resource models.Resource)
+}
+
+// Scheduler checks the releases in the store at an interval, and emits an event for each release that has moved to
+// a new state since the last check
+type Scheduler struct {
+	Store    ReleaseStore
+	Emitter  EventEmitter
+	Interval time.Duration
+
+	mutex     sync.Mutex
+	states    map[string]State
+	wg        sync.WaitGroup
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewScheduler creates a Scheduler that checks the releases in the store at the interval. The emitter may be nil,
+// in which case transitions are only logged.
+func NewScheduler(store ReleaseStore, emitter EventEmitter, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		Store:    store,
+		Emitter:  emitter,
+		Interval: interval,
+		states:   map[string]State{},
+		done:     make(chan struct{}),
+	}
+}
+
+// Start records the current state of every release, then checks them in the background until the scheduler is closed
+func (s *Scheduler) Start(ctx context.Context) {
+	s.Check(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.Check(ctx)
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Check compares the state of every release with its state at the last check, emitting an event for each release
+// that has changed state. Releases seen for the first time are recorded without emitting an event.
+func (s *Scheduler) Check(ctx context.Context) {
+	// hold the lock while the releases are read, so that a change emitted meanwhile is not undone by stale states
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	releases, err := s.Store.GetReleases(ctx)
+	if err != nil {
+		log.Error(ctx, "failed to get releases for scheduling", err)
+		return
+	}
+
+	states := make(map[string]State, len(releases))
+	for _, release := range releases {
+		state := StateOf(release)
+		states[release.URI] = state
+
+		previous, seen := s.states[release.URI]
+		if !seen || previous == state {
+			continue
+		}
+
+		log.Info(ctx, "release moved to a new state", log.Data{"uri": release.URI, "from": previous, "to": state})
+		if s.Emitter != nil {
+			s.Emitter.Emit(ctx, release)
+		}
+	}
+	s.states = states
+}
+
+// Emit emits the event for a resource changed in the store, recording the state of a changed release so that the
+// next check does not emit an event for the same change again. The store should emit its changes through the
+// scheduler whenever the scheduler is running.
+func (s *Scheduler) Emit(ctx context.Context, resource models.Resource) {
+	s.mutex.Lock()
+	switch r := resource.(type) {
+	case models.SearchContentUpdatedResource:
+		if r.ContentType == models.ReleaseContentType {
+			s.states[r.URI] = StateOf(r)
+		}
+	case models.SearchContentDeletedResource:
+		delete(s.states, r.URI)
+	}
+	s.mutex.Unlock()
+
+	if s.Emitter != nil {
+		s.Emitter.Emit(ctx, resource)
+	}
+}
+
+// Close stops the scheduler checking releases, and waits for any check in progress to finish
+func (s *Scheduler) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
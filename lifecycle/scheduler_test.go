@@ -0,0 +1,183 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/data"
+	"github.com/ONSdigital/dis-search-upstream-stub/lifecycle"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+type fakeStore struct {
+	lifecycle *lifecycle.Lifecycle
+	releases  []models.SearchContentUpdatedResource
+	err       error
+}
+
+func (s *fakeStore) GetReleases(ctx context.Context) ([]models.SearchContentUpdatedResource, error) {
+	releases := make([]models.SearchContentUpdatedResource, 0, len(s.releases))
+	for _, release := range s.releases {
+		releases = append(releases, s.lifecycle.Apply(release))
+	}
+	return releases, s.err
+}
+
+type recordingEmitter struct {
+	emitted []models.Resource
+}
+
+func (e *recordingEmitter) Emit(ctx context.Context, resource models.Resource) {
+	e.emitted = append(e.emitted, resource)
+}
+
+func TestScheduler(t *testing.T) {
+	Convey("Given a scheduler for a provisional release", t, func() {
+		c := &fakeClock{now: releaseDate.Add(-30 * 24 * time.Hour)}
+		store := &fakeStore{
+			lifecycle: &lifecycle.Lifecycle{Clock: c, ConfirmationPeriod: 7 * 24 * time.Hour},
+			releases:  []models.SearchContentUpdatedResource{testRelease()},
+		}
+		emitter := &recordingEmitter{}
+		scheduler := lifecycle.NewScheduler(store, emitter, time.Hour)
+		ctx := context.Background()
+
+		scheduler.Check(ctx)
+
+		Convey("When the releases are first checked", func() {
+			Convey("Then no events are emitted", func() {
+				So(emitter.emitted, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the clock passes the confirmation date and the release date", func() {
+			c.now = releaseDate.Add(-24 * time.Hour)
+			scheduler.Check(ctx)
+			scheduler.Check(ctx)
+			c.now = releaseDate
+			scheduler.Check(ctx)
+
+			Convey("Then an event is emitted for each transition", func() {
+				So(emitter.emitted, ShouldHaveLength, 2)
+				So(lifecycle.StateOf(emitter.emitted[0].(models.SearchContentUpdatedResource)), ShouldEqual, lifecycle.Confirmed)
+				So(lifecycle.StateOf(emitter.emitted[1].(models.SearchContentUpdatedResource)), ShouldEqual, lifecycle.Published)
+			})
+		})
+
+		Convey("When a release is added to the store", func() {
+			added := testRelease()
+			added.URI = "/releases/added"
+			store.releases = append(store.releases, added)
+			scheduler.Check(ctx)
+
+			Convey("Then it is recorded without an event being emitted", func() {
+				So(emitter.emitted, ShouldBeEmpty)
+
+				c.now = releaseDate
+				scheduler.Check(ctx)
+				So(emitter.emitted, ShouldHaveLength, 2)
+				So(emitter.emitted[1].(models.SearchContentUpdatedResource).URI, ShouldEqual, "/releases/added")
+				So(emitter.emitted[1].(models.SearchContentUpdatedResource).Published, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the store fails to return the releases", func() {
+			store.err = errors.New("fixtures unreadable")
+			c.now = releaseDate
+			scheduler.Check(ctx)
+
+			Convey("Then no events are emitted", func() {
+				So(emitter.emitted, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given a scheduler without an emitter", t, func() {
+		c := &fakeClock{now: releaseDate.Add(-30 * 24 * time.Hour)}
+		store := &fakeStore{
+			lifecycle: &lifecycle.Lifecycle{Clock: c, ConfirmationPeriod: 7 * 24 * time.Hour},
+			releases:  []models.SearchContentUpdatedResource{testRelease()},
+		}
+		scheduler := lifecycle.NewScheduler(store, nil, time.Millisecond)
+
+		Convey("When it is started", func() {
+			scheduler.Start(context.Background())
+
+			Convey("Then it checks the releases in the background until closed", func() {
+				time.Sleep(10 * time.Millisecond)
+				So(scheduler.Close, ShouldNotPanic)
+			})
+		})
+	})
+
+	Convey("Given a scheduler for the releases in a store that emits its changes through the scheduler", t, func() {
+		c := &fakeClock{now: releaseDate}
+		emitter := &recordingEmitter{}
+		store := &data.ResourceStore{
+			Clock:     c,
+			Lifecycle: &lifecycle.Lifecycle{Clock: c, ConfirmationPeriod: 7 * 24 * time.Hour},
+		}
+		scheduler := lifecycle.NewScheduler(store, emitter, time.Hour)
+		store.Emitter = scheduler
+		ctx := context.Background()
+
+		scheduler.Check(ctx)
+
+		Convey("When a release is moved to a new state by replacing it", func() {
+			releases, err := store.GetReleases(ctx)
+			So(err, ShouldBeNil)
+			So(releases, ShouldNotBeEmpty)
+
+			var release models.SearchContentUpdatedResource
+			for _, r := range releases {
+				if lifecycle.StateOf(r) != lifecycle.Cancelled {
+					release = r
+					break
+				}
+			}
+			So(release.URI, ShouldNotBeEmpty)
+			release.Cancelled = true
+			So(store.UpdateResource(ctx, release.URI, release), ShouldBeNil)
+			scheduler.Check(ctx)
+
+			Convey("Then exactly one event is emitted for it", func() {
+				So(emitter.emitted, ShouldHaveLength, 1)
+				So(emitter.emitted[0].(models.SearchContentUpdatedResource).URI, ShouldEqual, release.URI)
+				So(lifecycle.StateOf(emitter.emitted[0].(models.SearchContentUpdatedResource)), ShouldEqual, lifecycle.Cancelled)
+			})
+		})
+	})
+
+	Convey("Given a scheduler for the releases in the fixtures before the release scheduled to be cancelled", t, func() {
+		c := &fakeClock{now: time.Date(2010, 2, 1, 9, 30, 0, 0, time.UTC)}
+		emitter := &recordingEmitter{}
+		store := &data.ResourceStore{
+			Clock:     c,
+			Lifecycle: &lifecycle.Lifecycle{Clock: c, ConfirmationPeriod: 7 * 24 * time.Hour},
+		}
+		scheduler := lifecycle.NewScheduler(store, emitter, time.Hour)
+		ctx := context.Background()
+
+		scheduler.Check(ctx)
+
+		Convey("When the clock passes its confirmation date and its release date", func() {
+			c.now = time.Date(2010, 2, 22, 9, 30, 0, 0, time.UTC)
+			scheduler.Check(ctx)
+			c.now = time.Date(2010, 3, 1, 9, 30, 0, 0, time.UTC)
+			scheduler.Check(ctx)
+
+			Convey("Then it is confirmed and then cancelled", func() {
+				So(emitter.emitted, ShouldHaveLength, 2)
+				for _, resource := range emitter.emitted {
+					So(resource.(models.SearchContentUpdatedResource).URI, ShouldEqual, "/releases/cancelledreleasedate")
+				}
+				So(lifecycle.StateOf(emitter.emitted[0].(models.SearchContentUpdatedResource)), ShouldEqual, lifecycle.Confirmed)
+				So(lifecycle.StateOf(emitter.emitted[1].(models.SearchContentUpdatedResource)), ShouldEqual, lifecycle.Cancelled)
+			})
+		})
+	})
+}
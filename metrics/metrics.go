@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the name of every metric of the stub
+const Namespace = "dis_search_upstream_stub"
+
+// Results of publishing a kafka message
+const (
+	ResultSent   = "sent"
+	ResultFailed = "failed"
+)
+
+// Metrics records what the stub serves, publishes and simulates, so that load tests can be observed alongside the
+// search pipeline. Its methods do nothing if it is nil, so that it is optional wherever it is used.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	resourcesServed *prometheus.CounterVec
+	faultsInjected  *prometheus.CounterVec
+	messages        *prometheus.CounterVec
+	fixtureReloads  *prometheus.CounterVec
+}
+
+// New creates the stub's metrics, along with the Go runtime and process metrics, in a registry of their own
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests handled, by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		resourcesServed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "resources_served_total",
+			Help:      "Number of resources returned in lists of resources, by resource type.",
+		}, []string{"type"}),
+		faultsInjected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "faults_injected_total",
+			Help:      "Number of responses degraded by a simulated fault, by fault.",
+		}, []string{"fault"}),
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "kafka_messages_published_total",
+			Help:      "Number of events handed to the kafka producers, by topic and result.",
+		}, []string{"topic", "result"}),
+		fixtureReloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fixture_reloads_total",
+			Help:      "Number of times the fixtures of a resource type were read, by resource type.",
+		}, []string{"type"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.resourcesServed,
+		m.faultsInjected,
+		m.messages,
+		m.fixtureReloads,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware records the count and duration of the requests to each route. The route is the path template it was
+// matched by, such as /admin/resources/{uri:.+}, so that the paths of resources do not each become a label.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if m == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		route := "unknown"
+		if r := mux.CurrentRoute(req); r != nil {
+			if template, err := r.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		status := strconv.Itoa(recorder.status)
+		m.requests.WithLabelValues(route, req.Method, status).Inc()
+		m.requestDuration.WithLabelValues(route, req.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ResourcesServed records that a number of resources of a type were returned in a list
+func (m *Metrics) ResourcesServed(resourceType string, count int) {
+	if m == nil {
+		return
+	}
+	m.resourcesServed.WithLabelValues(resourceType).Add(float64(count))
+}
+
+// FaultInjected records that a response was degraded by a simulated fault
+func (m *Metrics) FaultInjected(fault string) {
+	if m == nil {
+		return
+	}
+	m.faultsInjected.WithLabelValues(fault).Inc()
+}
+
+// MessagePublished records the result of handing an event to the kafka producer of a topic
+func (m *Metrics) MessagePublished(topic, result string) {
+	if m == nil {
+		return
+	}
+	m.messages.WithLabelValues(topic, result).Inc()
+}
+
+// FixturesReloaded records that the fixtures of a resource type were read
+func (m *Metrics) FixturesReloaded(resourceType string) {
+	if m == nil {
+		return
+	}
+	m.fixtureReloads.WithLabelValues(resourceType).Inc()
+}
+
+// statusRecorder is a response writer that records the status code of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code before writing it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,90 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/metrics"
+)
+
+// scrape returns the metrics in the Prometheus exposition format
+func scrape(m *metrics.Metrics) string {
+	resp := httptest.NewRecorder()
+	m.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	So(resp.Code, ShouldEqual, http.StatusOK)
+	return resp.Body.String()
+}
+
+func TestMiddleware(t *testing.T) {
+	Convey("Given a router whose routes are recorded by the metrics", t, func() {
+		m := metrics.New()
+		r := mux.NewRouter()
+		r.Use(m.Middleware)
+		r.HandleFunc("/admin/resources/{uri:.+}", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}).Methods("PUT")
+		r.HandleFunc("/resources", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("[]"))
+		}).Methods("GET")
+
+		Convey("When requests are made to the routes", func() {
+			for _, path := range []string{"/admin/resources/economy/a", "/admin/resources/economy/b"} {
+				r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, path, http.NoBody))
+			}
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/resources", http.NoBody))
+
+			Convey("Then they are counted and timed by route template, method and status code", func() {
+				body := scrape(m)
+				So(body, ShouldContainSubstring, `dis_search_upstream_stub_http_requests_total{method="PUT",route="/admin/resources/{uri:.+}",status="404"} 2`)
+				So(body, ShouldContainSubstring, `dis_search_upstream_stub_http_requests_total{method="GET",route="/resources",status="200"} 1`)
+				So(body, ShouldContainSubstring, `dis_search_upstream_stub_http_request_duration_seconds_count{method="GET",route="/resources",status="200"} 1`)
+			})
+		})
+	})
+}
+
+func TestRecord(t *testing.T) {
+	Convey("Given the metrics", t, func() {
+		m := metrics.New()
+
+		Convey("When resources, faults, messages and fixture reloads are recorded", func() {
+			m.ResourcesServed("SearchContentUpdatedResource", 20)
+			m.ResourcesServed("SearchContentUpdatedResource", 5)
+			m.FaultInjected("health_critical")
+			m.MessagePublished("content-updated", metrics.ResultSent)
+			m.MessagePublished("content-updated", metrics.ResultFailed)
+			m.FixturesReloaded("ContentUpdatedResource")
+
+			Convey("Then each is exposed with its labels, along with the Go runtime metrics", func() {
+				body := scrape(m)
+				So(body, ShouldContainSubstring, `dis_search_upstream_stub_resources_served_total{type="SearchContentUpdatedResource"} 25`)
+				So(body, ShouldContainSubstring, `dis_search_upstream_stub_faults_injected_total{fault="health_critical"} 1`)
+				So(body, ShouldContainSubstring, `dis_search_upstream_stub_kafka_messages_published_total{result="sent",topic="content-updated"} 1`)
+				So(body, ShouldContainSubstring, `dis_search_upstream_stub_kafka_messages_published_total{result="failed",topic="content-updated"} 1`)
+				So(body, ShouldContainSubstring, `dis_search_upstream_stub_fixture_reloads_total{type="ContentUpdatedResource"} 1`)
+				So(body, ShouldContainSubstring, "go_goroutines")
+			})
+		})
+	})
+
+	Convey("Given no metrics", t, func() {
+		var m *metrics.Metrics
+
+		Convey("When anything is recorded, nothing happens", func() {
+			So(func() {
+				m.ResourcesServed("SearchContentUpdatedResource", 1)
+				m.FaultInjected("health_warning")
+				m.MessagePublished("content-updated", metrics.ResultSent)
+				m.FixturesReloaded("ContentUpdatedResource")
+
+				resp := httptest.NewRecorder()
+				m.Middleware(http.NotFoundHandler()).ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+				So(resp.Code, ShouldEqual, http.StatusNotFound)
+			}, ShouldNotPanic)
+		})
+	})
+}
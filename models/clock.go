@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Clock represents the state of the stub's virtual clock and json representation for API
+type Clock struct {
+	Now    time.Time `json:"now"`
+	Frozen bool      `json:"frozen"`
+	Speed  float64   `json:"speed"`
+}
+
+// SetClockRequest represents a request to change the stub's virtual clock. Now is either an RFC 3339 date-time or a
+// time relative to the current virtual time, such as now+7d. Fields that are not given are left unchanged.
+type SetClockRequest struct {
+	Now    string   `json:"now,omitempty"`
+	Frozen *bool    `json:"frozen,omitempty"`
+	Speed  *float64 `json:"speed,omitempty"`
+}
+
+// AdvanceClockRequest represents a request to move the stub's virtual clock on by a duration, such as 7d or -1w2d
+type AdvanceClockRequest struct {
+	By string `json:"by"`
+}
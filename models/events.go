@@ -0,0 +1,19 @@
+package models
+
+import "encoding/json"
+
+// PublishEventRequest represents a request to publish either a fixture, given by its path relative to the
+// json_files directory, or an inline payload as an event
+type PublishEventRequest struct {
+	Fixture string          `json:"fixture,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// PublishedEvent represents an event that has been published and json representation for API
+type PublishedEvent struct {
+	Topic     string `json:"topic"`
+	EventType string `json:"event_type"`
+	Encoding  string `json:"encoding"`
+	Framed    bool   `json:"framed"`
+	Fixture   string `json:"fixture,omitempty"`
+}
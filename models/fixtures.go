@@ -0,0 +1,19 @@
+package models
+
+// FixturesReport represents the validation results of every fixture and json representation for API
+type FixturesReport struct {
+	Count      int             `json:"count"`
+	Mismatches int             `json:"mismatches"`
+	Items      []FixtureReport `json:"items"`
+}
+
+// FixtureReport represents the expected and actual contract violations of a single fixture
+type FixtureReport struct {
+	File               string   `json:"file"`
+	ResourceType       string   `json:"resource_type"`
+	ContentType        string   `json:"content_type,omitempty"`
+	Description        string   `json:"description,omitempty"`
+	ExpectedViolations []string `json:"expected_violations"`
+	ActualViolations   []string `json:"actual_violations"`
+	Matches            bool     `json:"matches"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// HealthSimulation represents the status forced on the health endpoint and json representation for API. Status is the
+// status being reported, which is empty once the steps are over, and Until is when it changes.
+type HealthSimulation struct {
+	Simulated bool         `json:"simulated"`
+	Status    string       `json:"status,omitempty"`
+	Until     *time.Time   `json:"until,omitempty"`
+	Steps     []HealthStep `json:"steps,omitempty"`
+	Repeat    bool         `json:"repeat"`
+}
+
+// HealthStep represents a health status reported for a duration, such as 30s or 1h, or from then on if there is no
+// duration
+type HealthStep struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// SetHealthRequest represents a request to force the status of the health endpoint, either to a single status for an
+// optional duration, or through steps that can repeat so that the status flaps
+type SetHealthRequest struct {
+	Status   string       `json:"status,omitempty"`
+	Duration string       `json:"duration,omitempty"`
+	Steps    []HealthStep `json:"steps,omitempty"`
+	Repeat   bool         `json:"repeat,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// SchemaVersion represents a version of a schema registered under a subject and json representation
+// for the schema registry API
+type SchemaVersion struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	ID      int    `json:"id"`
+	Schema  string `json:"schema"`
+}
+
+// RegisteredSchema represents a schema looked up by its ID and json representation for the schema registry API
+type RegisteredSchema struct {
+	Schema string `json:"schema"`
+}
+
+// RegistryError represents an error in the form returned by a Confluent schema registry
+type RegistryError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
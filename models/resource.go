@@ -5,6 +5,9 @@ import (
 	"fmt"
 )
 
+// ReleaseContentType is the content_type of search-content-updated resources that carry release fields
+const ReleaseContentType = "release"
+
 // Resource interface that both types will implement
 type Resource interface {
 	GetResourceType() string
@@ -0,0 +1,35 @@
+// Package oteltest records the spans started by the stub in memory, so that tests can assert on them
+package oteltest
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Setup sets the global tracer provider to one that records every span in the returned exporter as it ends, and the
+// global propagator to the trace context propagator. The returned function restores the previous provider and
+// propagator, and must be called once the test is done, such as with convey's Reset.
+func Setup() (exporter *tracetest.InMemoryExporter, restore func()) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+
+	exporter = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return exporter, func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}
+}
+
+// Attributes returns the attributes of a span, keyed by name
+func Attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
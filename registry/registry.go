@@ -0,0 +1,169 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+)
+
+// A list of errors returned by the registry
+var (
+	ErrSubjectNotFound = errors.New("subject not found")
+	ErrVersionNotFound = errors.New("version not found")
+	ErrSchemaNotFound  = errors.New("schema not found")
+)
+
+// subjectSuffix is appended to a topic name to give the subject of its message value schemas,
+// following the topic name strategy of a Confluent schema registry
+const subjectSuffix = "-value"
+
+// Registry is a read-only schema registry holding every version of the schemas in the schema
+// package, each registered under the subject of the topic it is produced to
+type Registry struct {
+	subjects []string
+	versions map[string][]models.SchemaVersion
+	schemas  map[int]string
+}
+
+// subject pairs the topic of a subject with the name and current version of its schema
+type subject struct {
+	topic   string
+	name    string
+	current *avro.Schema
+}
+
+// New creates a registry for the topics in the Kafka config. Schema IDs are assigned in the order
+// the versions are registered, with any identical schemas sharing an ID, so they are the same for
+// every registry created from the same config.
+func New(kcfg *config.Kafka) (*Registry, error) {
+	r := &Registry{
+		versions: map[string][]models.SchemaVersion{},
+		schemas:  map[int]string{},
+	}
+	ids := map[string]int{}
+
+	for _, s := range []subject{
+		{kcfg.ContentUpdatedTopic, "content-updated", schema.ContentPublishedEvent},
+		{kcfg.SearchContentUpdatedTopic, "search-content-updated", schema.SearchContentUpdateEvent},
+		{kcfg.SearchContentDeletedTopic, "search-content-deleted", schema.SearchContentDeletedEvent},
+	} {
+		previous, err := schema.PreviousVersions(s.name)
+		if err != nil {
+			return nil, err
+		}
+
+		subjectName := Subject(s.topic)
+		r.subjects = append(r.subjects, subjectName)
+
+		for _, version := range append(previous, s.current) {
+			definition, err := canonical(version)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version of schema %q: %w", s.name, err)
+			}
+
+			versions := r.versions[subjectName]
+			if len(versions) > 0 && versions[len(versions)-1].Schema == definition {
+				// the current schema is unchanged since it was last released
+				continue
+			}
+
+			id, ok := ids[definition]
+			if !ok {
+				id = len(ids) + 1
+				ids[definition] = id
+				r.schemas[id] = definition
+			}
+
+			r.versions[subjectName] = append(versions, models.SchemaVersion{
+				Subject: subjectName,
+				Version: len(versions) + 1,
+				ID:      id,
+				Schema:  definition,
+			})
+		}
+	}
+
+	return r, nil
+}
+
+// Subject returns the subject that the schemas of messages produced to the topic are registered under
+func Subject(topic string) string {
+	return topic + subjectSuffix
+}
+
+// canonical returns the schema definition with insignificant whitespace removed
+func canonical(s *avro.Schema) (string, error) {
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(s.Definition)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Subjects returns the name of every subject in the registry
+func (r *Registry) Subjects() []string {
+	return append([]string{}, r.subjects...)
+}
+
+// Versions returns the version numbers registered under the subject
+func (r *Registry) Versions(subject string) ([]int, error) {
+	versions, ok := r.versions[subject]
+	if !ok {
+		return nil, ErrSubjectNotFound
+	}
+
+	numbers := make([]int, len(versions))
+	for i, v := range versions {
+		numbers[i] = v.Version
+	}
+	return numbers, nil
+}
+
+// Version returns a version of the schema registered under the subject
+func (r *Registry) Version(subject string, version int) (*models.SchemaVersion, error) {
+	versions, ok := r.versions[subject]
+	if !ok {
+		return nil, ErrSubjectNotFound
+	}
+	if version < 1 || version > len(versions) {
+		return nil, ErrVersionNotFound
+	}
+
+	v := versions[version-1]
+	return &v, nil
+}
+
+// Latest returns the latest version of the schema registered under the subject
+func (r *Registry) Latest(subject string) (*models.SchemaVersion, error) {
+	versions, ok := r.versions[subject]
+	if !ok {
+		return nil, ErrSubjectNotFound
+	}
+
+	v := versions[len(versions)-1]
+	return &v, nil
+}
+
+// Schema returns the schema with the given ID
+func (r *Registry) Schema(id int) (string, error) {
+	definition, ok := r.schemas[id]
+	if !ok {
+		return "", ErrSchemaNotFound
+	}
+	return definition, nil
+}
+
+// LatestID returns the ID of the latest schema of messages produced to the topic
+func (r *Registry) LatestID(topic string) (int, error) {
+	latest, err := r.Latest(Subject(topic))
+	if err != nil {
+		return 0, err
+	}
+	return latest.ID, nil
+}
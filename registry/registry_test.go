@@ -0,0 +1,128 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/registry"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+)
+
+func TestNew(t *testing.T) {
+	Convey("Given the default Kafka config", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+
+		Convey("When a registry is created", func() {
+			r, err := registry.New(cfg.Kafka)
+			So(err, ShouldBeNil)
+
+			Convey("Then there is a value subject for each topic", func() {
+				So(r.Subjects(), ShouldResemble, []string{
+					"content-updated-value",
+					"search-content-updated-value",
+					"search-content-deleted-value",
+				})
+			})
+
+			Convey("Then each released version of a schema is registered, followed by the current schema if it has changed", func() {
+				versions, err := r.Versions("content-updated-value")
+				So(err, ShouldBeNil)
+				So(versions, ShouldResemble, []int{1})
+
+				versions, err = r.Versions("search-content-updated-value")
+				So(err, ShouldBeNil)
+				So(versions, ShouldResemble, []int{1, 2})
+			})
+
+			Convey("Then the latest version of a subject is the current schema", func() {
+				latest, err := r.Latest("search-content-updated-value")
+				So(err, ShouldBeNil)
+				So(latest.Subject, ShouldEqual, "search-content-updated-value")
+				So(latest.Version, ShouldEqual, 2)
+				So(latest.ID, ShouldEqual, 3)
+
+				var got, want interface{}
+				So(json.Unmarshal([]byte(latest.Schema), &got), ShouldBeNil)
+				So(json.Unmarshal([]byte(schema.SearchContentUpdateEvent.Definition), &want), ShouldBeNil)
+				So(got, ShouldResemble, want)
+			})
+
+			Convey("Then each schema can be looked up by its ID", func() {
+				for _, subject := range r.Subjects() {
+					latest, err := r.Latest(subject)
+					So(err, ShouldBeNil)
+
+					definition, err := r.Schema(latest.ID)
+					So(err, ShouldBeNil)
+					So(definition, ShouldEqual, latest.Schema)
+				}
+			})
+
+			Convey("Then the ID of the latest schema of a topic is returned", func() {
+				id, err := r.LatestID(cfg.Kafka.SearchContentDeletedTopic)
+				So(err, ShouldBeNil)
+				So(id, ShouldEqual, 4)
+			})
+		})
+
+		Convey("When two registries are created", func() {
+			r1, err := registry.New(cfg.Kafka)
+			So(err, ShouldBeNil)
+			r2, err := registry.New(cfg.Kafka)
+			So(err, ShouldBeNil)
+
+			Convey("Then they assign the same IDs", func() {
+				for _, subject := range r1.Subjects() {
+					latest1, err := r1.Latest(subject)
+					So(err, ShouldBeNil)
+					latest2, err := r2.Latest(subject)
+					So(err, ShouldBeNil)
+					So(latest1.ID, ShouldEqual, latest2.ID)
+				}
+			})
+		})
+	})
+}
+
+func TestLookupErrors(t *testing.T) {
+	Convey("Given a registry", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		r, err := registry.New(cfg.Kafka)
+		So(err, ShouldBeNil)
+
+		Convey("When an unknown subject is looked up", func() {
+			_, versionsErr := r.Versions("unknown-value")
+			_, versionErr := r.Version("unknown-value", 1)
+			_, latestErr := r.Latest("unknown-value")
+			_, idErr := r.LatestID("unknown")
+
+			Convey("Then a subject not found error is returned", func() {
+				So(versionsErr, ShouldEqual, registry.ErrSubjectNotFound)
+				So(versionErr, ShouldEqual, registry.ErrSubjectNotFound)
+				So(latestErr, ShouldEqual, registry.ErrSubjectNotFound)
+				So(idErr, ShouldEqual, registry.ErrSubjectNotFound)
+			})
+		})
+
+		Convey("When an unknown version is looked up", func() {
+			_, err := r.Version("content-updated-value", 2)
+
+			Convey("Then a version not found error is returned", func() {
+				So(err, ShouldEqual, registry.ErrVersionNotFound)
+			})
+		})
+
+		Convey("When an unknown schema ID is looked up", func() {
+			_, err := r.Schema(999)
+
+			Convey("Then a schema not found error is returned", func() {
+				So(err, ShouldEqual, registry.ErrSchemaNotFound)
+			})
+		})
+	})
+}
@@ -0,0 +1,389 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+	"github.com/pkg/errors"
+)
+
+// avroType is a parsed Avro type, holding only what is needed to compare schemas with models and
+// with each other
+type avroType struct {
+	// Type is the primitive type name, or one of "record", "array", "map" or "union"
+	Type     string
+	Name     string
+	Fields   []avroField
+	Items    *avroType
+	Values   *avroType
+	Branches []*avroType
+}
+
+type avroField struct {
+	Name    string
+	Type    *avroType
+	Default json.RawMessage
+}
+
+// hasDefault reports whether the field declares a default value, including an explicit null
+func (f avroField) hasDefault() bool {
+	return len(f.Default) > 0
+}
+
+func (t *avroType) String() string {
+	switch t.Type {
+	case "record":
+		return "record " + t.Name
+	case "array":
+		return "array<" + t.Items.String() + ">"
+	case "map":
+		return "map<" + t.Values.String() + ">"
+	case "union":
+		branches := make([]string, len(t.Branches))
+		for i, b := range t.Branches {
+			branches[i] = b.String()
+		}
+		return "union[" + strings.Join(branches, ",") + "]"
+	default:
+		return t.Type
+	}
+}
+
+func (t *avroType) field(name string) (avroField, bool) {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return avroField{}, false
+}
+
+// parse parses the definition of an Avro schema, which must be a record
+func parse(s *avro.Schema) (*avroType, error) {
+	t, err := parseType(json.RawMessage(s.Definition))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse schema")
+	}
+	if t.Type != "record" {
+		return nil, fmt.Errorf("schema is a %s, not a record", t)
+	}
+	return t, nil
+}
+
+func parseType(raw json.RawMessage) (*avroType, error) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return &avroType{Type: name}, nil
+	}
+
+	var branches []json.RawMessage
+	if err := json.Unmarshal(raw, &branches); err == nil {
+		union := &avroType{Type: "union"}
+		for _, b := range branches {
+			branch, err := parseType(b)
+			if err != nil {
+				return nil, err
+			}
+			union.Branches = append(union.Branches, branch)
+		}
+		return union, nil
+	}
+
+	var complexType struct {
+		Type   json.RawMessage `json:"type"`
+		Name   string          `json:"name"`
+		Items  json.RawMessage `json:"items"`
+		Values json.RawMessage `json:"values"`
+		Fields []struct {
+			Name    string          `json:"name"`
+			Type    json.RawMessage `json:"type"`
+			Default json.RawMessage `json:"default"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(raw, &complexType); err != nil {
+		return nil, err
+	}
+
+	t, err := parseType(complexType.Type)
+	if err != nil {
+		return nil, err
+	}
+	t.Name = complexType.Name
+
+	switch t.Type {
+	case "record":
+		for _, f := range complexType.Fields {
+			fieldType, err := parseType(f.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse type of field %q", f.Name)
+			}
+			t.Fields = append(t.Fields, avroField{Name: f.Name, Type: fieldType, Default: f.Default})
+		}
+	case "array":
+		if t.Items, err = parseType(complexType.Items); err != nil {
+			return nil, errors.Wrap(err, "failed to parse array items")
+		}
+	case "map":
+		if t.Values, err = parseType(complexType.Values); err != nil {
+			return nil, errors.Wrap(err, "failed to parse map values")
+		}
+	}
+
+	return t, nil
+}
+
+// CheckModel compares the avro tagged fields of a model struct with the fields of an Avro schema.
+// It returns a description of each field that is missing from either side, has a type that does
+// not match, or has no default (or a default of the wrong type) in the schema.
+func CheckModel(s *avro.Schema, model interface{}) ([]string, error) {
+	record, err := parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeOf(model)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model is a %s, not a struct", typ.Kind())
+	}
+
+	return checkRecord("", record, typ), nil
+}
+
+func checkRecord(path string, record *avroType, typ reflect.Type) []string {
+	var problems []string
+	tagged := map[string]bool{}
+
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		tag := strings.Split(structField.Tag.Get("avro"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		tagged[tag] = true
+
+		fieldPath := join(path, tag)
+		field, ok := record.field(tag)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: field of %s is not in the schema", fieldPath, typ.Name()))
+			continue
+		}
+
+		problems = append(problems, checkType(fieldPath, field.Type, structField.Type)...)
+
+		if !field.hasDefault() {
+			problems = append(problems, fmt.Sprintf("%s: schema field has no default", fieldPath))
+		} else if !defaultMatches(field.Type, field.Default) {
+			problems = append(problems, fmt.Sprintf("%s: schema default %s is not a valid %s", fieldPath, field.Default, field.Type))
+		}
+	}
+
+	for _, field := range record.Fields {
+		if !tagged[field.Name] {
+			problems = append(problems, fmt.Sprintf("%s: schema field is not in %s", join(path, field.Name), typ.Name()))
+		}
+	}
+
+	return problems
+}
+
+// goKinds are the kinds of Go value that each Avro primitive type is marshalled from
+var goKinds = map[string][]reflect.Kind{
+	"boolean": {reflect.Bool},
+	"int":     {reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32},
+	"long":    {reflect.Int, reflect.Int64},
+	"float":   {reflect.Float32},
+	"double":  {reflect.Float64},
+	"string":  {reflect.String},
+}
+
+func checkType(path string, t *avroType, typ reflect.Type) []string {
+	mismatch := []string{fmt.Sprintf("%s: schema type %s does not match model type %s", path, t, typ)}
+
+	switch t.Type {
+	case "union":
+		// only the non-null branch of an optional field is compared with the model
+		for _, b := range t.Branches {
+			if b.Type != "null" {
+				if typ.Kind() == reflect.Ptr {
+					typ = typ.Elem()
+				}
+				return checkType(path, b, typ)
+			}
+		}
+		return mismatch
+	case "record":
+		if typ.Kind() != reflect.Struct {
+			return mismatch
+		}
+		return checkRecord(path, t, typ)
+	case "array":
+		if typ.Kind() != reflect.Slice {
+			return mismatch
+		}
+		return checkType(path+"[]", t.Items, typ.Elem())
+	case "map":
+		if typ.Kind() != reflect.Map || typ.Key().Kind() != reflect.String {
+			return mismatch
+		}
+		return checkType(path+"{}", t.Values, typ.Elem())
+	case "bytes":
+		if typ.Kind() != reflect.Slice || typ.Elem().Kind() != reflect.Uint8 {
+			return mismatch
+		}
+		return nil
+	}
+
+	for _, kind := range goKinds[t.Type] {
+		if typ.Kind() == kind {
+			return nil
+		}
+	}
+	return mismatch
+}
+
+// defaultMatches checks that a default value is valid for the type, where the default of a union
+// must match its first branch
+func defaultMatches(t *avroType, raw json.RawMessage) bool {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return false
+	}
+
+	switch t.Type {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "int", "long", "float", "double":
+		_, ok := value.(float64)
+		return ok
+	case "string", "bytes", "enum", "fixed":
+		_, ok := value.(string)
+		return ok
+	case "union":
+		return len(t.Branches) > 0 && defaultMatches(t.Branches[0], raw)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, item := range items {
+			b, _ := json.Marshal(item)
+			if !defaultMatches(t.Items, b) {
+				return false
+			}
+		}
+		return true
+	case "map", "record":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+
+	return false
+}
+
+// CheckBackward checks that consumers using the current schema can read events written with the
+// previous schema, returning a description of each incompatibility
+func CheckBackward(previous, current *avro.Schema) ([]string, error) {
+	return checkCanRead(current, previous)
+}
+
+// CheckForward checks that consumers still using the previous schema can read events written with
+// the current schema, returning a description of each incompatibility
+func CheckForward(previous, current *avro.Schema) ([]string, error) {
+	return checkCanRead(previous, current)
+}
+
+func checkCanRead(reader, writer *avro.Schema) ([]string, error) {
+	readerType, err := parse(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid reader schema")
+	}
+	writerType, err := parse(writer)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid writer schema")
+	}
+	return canRead("", readerType, writerType), nil
+}
+
+// promotions lists the primitive types that a reader can read data written as each primitive type
+var promotions = map[string][]string{
+	"int":    {"long", "float", "double"},
+	"long":   {"float", "double"},
+	"float":  {"double"},
+	"string": {"bytes"},
+	"bytes":  {"string"},
+}
+
+// canRead applies the Avro schema resolution rules to a reader and writer type
+func canRead(path string, reader, writer *avroType) []string {
+	mismatch := []string{fmt.Sprintf("%s: %s cannot be read as %s", path, writer, reader)}
+
+	if writer.Type == "union" {
+		var problems []string
+		for _, b := range writer.Branches {
+			problems = append(problems, canRead(path, reader, b)...)
+		}
+		return problems
+	}
+
+	if reader.Type == "union" {
+		for _, b := range reader.Branches {
+			if len(canRead(path, b, writer)) == 0 {
+				return nil
+			}
+		}
+		return mismatch
+	}
+
+	if reader.Type != writer.Type {
+		for _, promoted := range promotions[writer.Type] {
+			if reader.Type == promoted {
+				return nil
+			}
+		}
+		return mismatch
+	}
+
+	switch reader.Type {
+	case "record":
+		if reader.Name != writer.Name {
+			return mismatch
+		}
+
+		var problems []string
+		for _, field := range reader.Fields {
+			fieldPath := join(path, field.Name)
+			writerField, ok := writer.field(field.Name)
+			if !ok {
+				if !field.hasDefault() {
+					problems = append(problems, fmt.Sprintf("%s: field is missing from the writer schema and has no default", fieldPath))
+				}
+				continue
+			}
+			problems = append(problems, canRead(fieldPath, field.Type, writerField.Type)...)
+		}
+		return problems
+	case "array":
+		return canRead(path+"[]", reader.Items, writer.Items)
+	case "map":
+		return canRead(path+"{}", reader.Values, writer.Values)
+	}
+
+	return nil
+}
+
+// join appends the name of a field to the path of the record it belongs to
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
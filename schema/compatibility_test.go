@@ -0,0 +1,222 @@
+package schema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/schema"
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+)
+
+var schemas = []struct {
+	name   string
+	schema *avro.Schema
+	model  interface{}
+}{
+	{"content-updated", schema.ContentPublishedEvent, models.ContentUpdatedResource{}},
+	{"search-content-updated", schema.SearchContentUpdateEvent, models.SearchContentUpdatedResource{}},
+	{"search-content-deleted", schema.SearchContentDeletedEvent, models.SearchContentDeletedResource{}},
+}
+
+func TestModelsMatchSchemas(t *testing.T) {
+	for _, s := range schemas {
+		Convey("Given the "+s.name+" schema and its model", t, func() {
+			Convey("When the model is checked against the schema", func() {
+				problems, err := schema.CheckModel(s.schema, s.model)
+
+				Convey("Then every field matches, with a default", func() {
+					So(err, ShouldBeNil)
+					So(problems, ShouldBeEmpty)
+				})
+			})
+		})
+	}
+}
+
+// sameDefinition reports whether two schemas are defined identically, apart from insignificant whitespace
+func sameDefinition(a, b *avro.Schema) bool {
+	var ca, cb bytes.Buffer
+	So(json.Compact(&ca, []byte(a.Definition)), ShouldBeNil)
+	So(json.Compact(&cb, []byte(b.Definition)), ShouldBeNil)
+	return ca.String() == cb.String()
+}
+
+func TestSchemasAreCompatibleWithPreviousVersions(t *testing.T) {
+	for _, s := range schemas {
+		Convey("Given the released versions of the "+s.name+" schema", t, func() {
+			versions, err := schema.PreviousVersions(s.name)
+			So(err, ShouldBeNil)
+			So(versions, ShouldNotBeEmpty)
+
+			// A schema that has not changed since its latest release, such as search-content-deleted, is identical to
+			// that version, and comparing it with itself would prove nothing, so only the versions it differs from are
+			// checked
+			Convey("Then the current schema is backward and forward compatible with each of them that it differs from", func() {
+				for _, previous := range versions {
+					if sameDefinition(previous, s.schema) {
+						continue
+					}
+
+					backward, err := schema.CheckBackward(previous, s.schema)
+					So(err, ShouldBeNil)
+					So(backward, ShouldBeEmpty)
+
+					forward, err := schema.CheckForward(previous, s.schema)
+					So(err, ShouldBeNil)
+					So(forward, ShouldBeEmpty)
+				}
+			})
+		})
+	}
+}
+
+type testModel struct {
+	Name     string   `avro:"name"`
+	Count    int32    `avro:"count"`
+	Tags     []string `avro:"tags"`
+	Internal string   `avro:"-"`
+	Nested   []struct {
+		Value string `avro:"value"`
+	} `avro:"nested"`
+}
+
+func TestCheckModel(t *testing.T) {
+	Convey("Given a model", t, func() {
+		Convey("When it is checked against a schema that matches it", func() {
+			s := &avro.Schema{Definition: `{"type": "record", "name": "test", "fields": [
+				{"name": "name", "type": "string", "default": ""},
+				{"name": "count", "type": "int", "default": 0},
+				{"name": "tags", "type": {"type": "array", "items": "string"}, "default": []},
+				{"name": "nested", "type": {"type": "array", "items": {"type": "record", "name": "Nested", "fields": [
+					{"name": "value", "type": "string", "default": ""}
+				]}}, "default": []}
+			]}`}
+			problems, err := schema.CheckModel(s, testModel{})
+
+			Convey("Then no problems are found", func() {
+				So(err, ShouldBeNil)
+				So(problems, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When it is checked against a schema that has drifted from it", func() {
+			s := &avro.Schema{Definition: `{"type": "record", "name": "test", "fields": [
+				{"name": "name", "type": "string"},
+				{"name": "count", "type": "string", "default": 0},
+				{"name": "tags", "type": {"type": "array", "items": "string"}, "default": ""},
+				{"name": "nested", "type": {"type": "array", "items": {"type": "record", "name": "Nested", "fields": [
+					{"name": "value", "type": "long", "default": 0}
+				]}}, "default": []},
+				{"name": "extra", "type": "string", "default": ""}
+			]}`}
+			problems, err := schema.CheckModel(s, &testModel{})
+
+			Convey("Then each difference is described", func() {
+				So(err, ShouldBeNil)
+				So(problems, ShouldResemble, []string{
+					"name: schema field has no default",
+					"count: schema type string does not match model type int32",
+					"count: schema default 0 is not a valid string",
+					`tags: schema default "" is not a valid array<string>`,
+					"nested[].value: schema type long does not match model type string",
+					"extra: schema field is not in testModel",
+				})
+			})
+		})
+
+		Convey("When it is checked against a schema missing one of its fields", func() {
+			s := &avro.Schema{Definition: `{"type": "record", "name": "test", "fields": [
+				{"name": "name", "type": "string", "default": ""}
+			]}`}
+			problems, err := schema.CheckModel(s, testModel{})
+
+			Convey("Then the missing fields are described", func() {
+				So(err, ShouldBeNil)
+				So(problems, ShouldResemble, []string{
+					"count: field of testModel is not in the schema",
+					"tags: field of testModel is not in the schema",
+					"nested: field of testModel is not in the schema",
+				})
+			})
+		})
+
+		Convey("When it is checked against an invalid schema", func() {
+			_, err := schema.CheckModel(&avro.Schema{Definition: `{"type": `}, testModel{})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	previous := &avro.Schema{Definition: `{"type": "record", "name": "test", "fields": [
+		{"name": "name", "type": "string", "default": ""},
+		{"name": "count", "type": "int"}
+	]}`}
+
+	Convey("Given a previous version of a schema", t, func() {
+		Convey("When a field with a default is added and an int is promoted to a long", func() {
+			current := &avro.Schema{Definition: `{"type": "record", "name": "test", "fields": [
+				{"name": "name", "type": "string", "default": ""},
+				{"name": "count", "type": "long"},
+				{"name": "added", "type": "string", "default": ""}
+			]}`}
+
+			Convey("Then the change is backward compatible", func() {
+				problems, err := schema.CheckBackward(previous, current)
+				So(err, ShouldBeNil)
+				So(problems, ShouldBeEmpty)
+			})
+
+			Convey("Then the change is not forward compatible", func() {
+				problems, err := schema.CheckForward(previous, current)
+				So(err, ShouldBeNil)
+				So(problems, ShouldResemble, []string{"count: long cannot be read as int"})
+			})
+		})
+
+		Convey("When a field without a default is added and a field without a default is removed", func() {
+			current := &avro.Schema{Definition: `{"type": "record", "name": "test", "fields": [
+				{"name": "name", "type": "string", "default": ""},
+				{"name": "added", "type": "string"}
+			]}`}
+
+			Convey("Then the change is not backward compatible", func() {
+				problems, err := schema.CheckBackward(previous, current)
+				So(err, ShouldBeNil)
+				So(problems, ShouldResemble, []string{"added: field is missing from the writer schema and has no default"})
+			})
+
+			Convey("Then the change is not forward compatible", func() {
+				problems, err := schema.CheckForward(previous, current)
+				So(err, ShouldBeNil)
+				So(problems, ShouldResemble, []string{"count: field is missing from the writer schema and has no default"})
+			})
+		})
+
+		Convey("When a field is made optional with a union", func() {
+			current := &avro.Schema{Definition: `{"type": "record", "name": "test", "fields": [
+				{"name": "name", "type": ["null", "string"], "default": null},
+				{"name": "count", "type": "int"}
+			]}`}
+
+			Convey("Then the change is backward compatible", func() {
+				problems, err := schema.CheckBackward(previous, current)
+				So(err, ShouldBeNil)
+				So(problems, ShouldBeEmpty)
+			})
+
+			Convey("Then the change is not forward compatible", func() {
+				problems, err := schema.CheckForward(previous, current)
+				So(err, ShouldBeNil)
+				So(problems, ShouldResemble, []string{"name: null cannot be read as string"})
+			})
+		})
+	})
+}
@@ -28,10 +28,10 @@ var searchContentUpdate = `{
       "name": "ReleaseDateDetails",
       "type": "record",
       "fields": [
-        { "name":"change_notice", "type":"string" },
-        { "name":"previous_date", "type":"string" }
-      ] 
-    }}},
+        { "name":"change_notice", "type":"string", "default": "" },
+        { "name":"previous_date", "type":"string", "default": "" }
+      ]
+    }}, "default": []},
     {"name": "provisional_date", "type": "string", "default": ""}
   ]
 }`
@@ -58,3 +58,19 @@ var contentUpdated = `{
 var ContentPublishedEvent = &avro.Schema{
 	Definition: contentUpdated,
 }
+
+var searchContentDeleted = `{
+  "type": "record",
+  "name": "search-content-deleted",
+  "fields": [
+    {"name": "uri", "type": "string", "default": ""},
+    {"name": "collection_id", "type": "string", "default": ""},
+    {"name": "search_index", "type": "string", "default": ""},
+    {"name": "trace_id", "type": "string", "default": ""}
+  ]
+}`
+
+// SearchContentDeletedEvent is the Avro schema for search-content-deleted messages.
+var SearchContentDeletedEvent = &avro.Schema{
+	Definition: searchContentDeleted,
+}
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+	"github.com/pkg/errors"
+)
+
+// versionFiles holds every released version of each schema, as versions/<schema name>/<version>.avsc
+//
+//go:embed versions
+var versionFiles embed.FS
+
+const versionsDir = "versions"
+
+// PreviousVersions returns the released versions of the named schema, oldest first, so that changes
+// to the current schema can be checked for compatibility with them
+func PreviousVersions(name string) ([]*avro.Schema, error) {
+	dir := path.Join(versionsDir, name)
+
+	dirEntries, err := fs.ReadDir(versionFiles, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read versions of schema %q", name)
+	}
+
+	versions := map[int]*avro.Schema{}
+	numbers := make([]int, 0, len(dirEntries))
+
+	for _, dirEntry := range dirEntries {
+		number, err := strconv.Atoi(strings.TrimSuffix(dirEntry.Name(), ".avsc"))
+		if err != nil || dirEntry.IsDir() || path.Ext(dirEntry.Name()) != ".avsc" {
+			return nil, fmt.Errorf("unexpected schema version file: %s", path.Join(dir, dirEntry.Name()))
+		}
+
+		definition, err := versionFiles.ReadFile(path.Join(dir, dirEntry.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read schema version file")
+		}
+
+		versions[number] = &avro.Schema{Definition: string(definition)}
+		numbers = append(numbers, number)
+	}
+
+	sort.Ints(numbers)
+
+	schemas := make([]*avro.Schema, 0, len(numbers))
+	for _, number := range numbers {
+		schemas = append(schemas, versions[number])
+	}
+
+	return schemas, nil
+}
@@ -8,15 +8,25 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/models"
 	apiError "github.com/ONSdigital/dis-search-upstream-stub/sdk/errors"
 	healthcheck "github.com/ONSdigital/dp-api-clients-go/v2/health"
 	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	dphttp "github.com/ONSdigital/dp-net/v3/http"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	service = "dis-search-upstream-stub"
+
+	// tracerName names the tracer of the spans started by the client
+	tracerName = "github.com/ONSdigital/dis-search-upstream-stub/sdk"
 )
 
 type Client struct {
@@ -24,10 +34,12 @@ type Client struct {
 	resourcesEndpoint string
 }
 
-// New creates a new instance of Client with a given upstream api url
+// New creates a new instance of Client with a given upstream api url. Its HTTP calls are traced, and propagate the
+// trace context, if OpenTelemetry is set up.
 func New(upstreamAPIURL, resourcesEndpoint string) *Client {
+	clienter := dphttp.NewClientWithTransport(otelhttp.NewTransport(dphttp.DefaultTransport))
 	return &Client{
-		hcCli:             healthcheck.NewClient(service, upstreamAPIURL),
+		hcCli:             healthcheck.NewClientWithClienter(service, upstreamAPIURL, clienter),
 		resourcesEndpoint: resourcesEndpoint,
 	}
 }
@@ -60,29 +72,57 @@ func (cli *Client) Checker(ctx context.Context, check *health.CheckState) error
 	return cli.hcCli.Checker(ctx, check)
 }
 
-// GetResources gets a list of upstream resources
+// GetResources gets a list of upstream resources. The call is traced by a span if OpenTelemetry is set up, which is
+// the parent of the span of the HTTP request made by a client created with New.
 func (cli *Client) GetResources(ctx context.Context, options Options) (*models.Resources, apiError.Error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "GetResources", trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+	setQueryAttributes(span, options.Query)
+
 	path := fmt.Sprintf("%s%s", cli.hcCli.URL, cli.resourcesEndpoint)
 	if options.Query != nil {
 		path = path + "?" + options.Query.Encode()
 	}
 
 	respInfo, apiErr := cli.callUpstreamAPI(ctx, path, http.MethodGet, options.Headers, nil)
+	if respInfo != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", respInfo.Status))
+	}
 	if apiErr != nil {
+		span.RecordError(apiErr)
+		span.SetStatus(codes.Error, apiErr.Error())
 		return nil, apiErr
 	}
 
 	var resourcesResponse models.Resources
 
 	if err := json.Unmarshal(respInfo.Body, &resourcesResponse); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, apiError.StatusError{
 			Err: fmt.Errorf("failed to unmarshal upstream resources response - error is: %v", err),
 		}
 	}
 
+	span.SetAttributes(
+		attribute.Int("pagination.count", resourcesResponse.Count),
+		attribute.Int("pagination.total_count", resourcesResponse.TotalCount),
+	)
 	return &resourcesResponse, nil
 }
 
+// setQueryAttributes sets the resource type and pagination requested by the query as attributes of the span
+func setQueryAttributes(span trace.Span, query url.Values) {
+	if resourceType := query.Get(ParamType); resourceType != "" {
+		span.SetAttributes(attribute.String("resource.type", resourceType))
+	}
+	for key, param := range map[string]string{"pagination.limit": ParamLimit, "pagination.offset": ParamOffset} {
+		if value, err := strconv.Atoi(query.Get(param)); err == nil {
+			span.SetAttributes(attribute.Int(key, value))
+		}
+	}
+}
+
 type ResponseInfo struct {
 	Body    []byte
 	Headers http.Header
@@ -104,9 +144,9 @@ func (cli *Client) callUpstreamAPI(ctx context.Context, path, method string, hea
 	var req *http.Request
 
 	if payload != nil {
-		req, err = http.NewRequest(method, path, bytes.NewReader(payload))
+		req, err = http.NewRequestWithContext(ctx, method, path, bytes.NewReader(payload))
 	} else {
-		req, err = http.NewRequest(method, path, http.NoBody)
+		req, err = http.NewRequestWithContext(ctx, method, path, http.NoBody)
 	}
 
 	// check req, above, didn't error
@@ -7,14 +7,20 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/oteltest"
 	healthcheck "github.com/ONSdigital/dp-api-clients-go/v2/health"
 	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
 	dphttp "github.com/ONSdigital/dp-net/v3/http"
 	c "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const testHost = "http://localhost:23900"
@@ -174,6 +180,90 @@ func TestGetResources(t *testing.T) {
 	})
 }
 
+func TestGetResourcesSpan(t *testing.T) {
+	c.Convey("Given OpenTelemetry is set up with an in-memory exporter", t, func() {
+		exporter, restore := oteltest.Setup()
+		c.Reset(restore)
+
+		c.Convey("When a page of resources of a type is got within a span", func() {
+			body, err := json.Marshal(getMockResponse())
+			c.So(err, c.ShouldBeNil)
+			httpClient := newMockHTTPClient(&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			}, nil)
+
+			ctx, parent := otel.Tracer("test").Start(context.Background(), "index resources")
+			options := (&Options{}).Limit("10").Offset("20")
+			options.Query.Set("type", "content-updated")
+			_, apiErr := newUpstreamAPIClient(t, httpClient).GetResources(ctx, *options)
+			c.So(apiErr, c.ShouldBeNil)
+			parent.End()
+
+			c.Convey("Then the call is traced by an internal span of the parent, with the type and pagination", func() {
+				spans := exporter.GetSpans()
+				c.So(spans, c.ShouldHaveLength, 2)
+				span := spans[0]
+				c.So(span.Name, c.ShouldEqual, "GetResources")
+				c.So(span.SpanKind, c.ShouldEqual, trace.SpanKindInternal)
+				c.So(span.Parent.SpanID(), c.ShouldEqual, spans[1].SpanContext.SpanID())
+
+				attributes := oteltest.Attributes(span)
+				c.So(attributes["resource.type"].AsString(), c.ShouldEqual, "content-updated")
+				c.So(attributes["pagination.limit"].AsInt64(), c.ShouldEqual, 10)
+				c.So(attributes["pagination.offset"].AsInt64(), c.ShouldEqual, 20)
+				c.So(attributes["pagination.count"].AsInt64(), c.ShouldEqual, 1)
+				c.So(attributes["http.response.status_code"].AsInt64(), c.ShouldEqual, http.StatusOK)
+
+				c.Convey("And the request is made with the span's context, for the transport to propagate", func() {
+					req := httpClient.DoCalls()[0].Req
+					c.So(trace.SpanContextFromContext(req.Context()).SpanID(), c.ShouldEqual, span.SpanContext.SpanID())
+					c.So(trace.SpanContextFromContext(httpClient.DoCalls()[0].Ctx).SpanID(), c.ShouldEqual, span.SpanContext.SpanID())
+				})
+			})
+		})
+
+		c.Convey("When resources are got by a client created with New", func() {
+			var traceParent string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				traceParent = req.Header.Get("traceparent")
+				_, _ = w.Write([]byte(`{"count":0,"items":[]}`))
+			}))
+			defer upstream.Close()
+
+			_, apiErr := New(upstream.URL, "/resources").GetResources(context.Background(), Options{})
+			c.So(apiErr, c.ShouldBeNil)
+
+			c.Convey("Then the HTTP request is the only client span, a child of the GetResources span, and is propagated", func() {
+				spans := exporter.GetSpans()
+				c.So(spans, c.ShouldHaveLength, 2)
+				request, getResources := spans[0], spans[1]
+				c.So(getResources.SpanKind, c.ShouldEqual, trace.SpanKindInternal)
+				c.So(request.SpanKind, c.ShouldEqual, trace.SpanKindClient)
+				c.So(request.Parent.SpanID(), c.ShouldEqual, getResources.SpanContext.SpanID())
+				c.So(traceParent, c.ShouldContainSubstring, request.SpanContext.SpanID().String())
+			})
+		})
+
+		c.Convey("When the upstream API responds with an error", func() {
+			httpClient := newMockHTTPClient(&http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       http.NoBody,
+			}, nil)
+
+			_, apiErr := newUpstreamAPIClient(t, httpClient).GetResources(context.Background(), Options{})
+			c.So(apiErr, c.ShouldNotBeNil)
+
+			c.Convey("Then the span records the error and the status code", func() {
+				spans := exporter.GetSpans()
+				c.So(spans, c.ShouldHaveLength, 1)
+				c.So(spans[0].Status.Code, c.ShouldEqual, codes.Error)
+				c.So(spans[0].Attributes, c.ShouldContain, attribute.Int("http.response.status_code", http.StatusInternalServerError))
+			})
+		})
+	})
+}
+
 func newMockHTTPClient(r *http.Response, err error) *dphttp.ClienterMock {
 	return &dphttp.ClienterMock{
 		SetPathsWithNoRetriesFunc: func(paths []string) {
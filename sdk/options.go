@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/ONSdigital/dis-search-upstream-stub/api"
 	"github.com/ONSdigital/dp-net/v3/request"
 )
 
@@ -13,6 +12,13 @@ const (
 	Authorization string = request.AuthHeaderKey
 )
 
+// List of available query parameters, as defined by the upstream API
+const (
+	ParamOffset = "offset"
+	ParamLimit  = "limit"
+	ParamType   = "type"
+)
+
 // Options is a struct containing for customised options for the API client
 type Options struct {
 	Headers http.Header
@@ -24,7 +30,7 @@ func (o *Options) Limit(val string) *Options {
 	if o.Query == nil {
 		o.Query = make(map[string][]string)
 	}
-	o.Query.Set(api.ParamLimit, val)
+	o.Query.Set(ParamLimit, val)
 	return o
 }
 
@@ -33,7 +39,7 @@ func (o *Options) Offset(val string) *Options {
 	if o.Query == nil {
 		o.Query = make(map[string][]string)
 	}
-	o.Query.Set(api.ParamOffset, val)
+	o.Query.Set(ParamOffset, val)
 	return o
 }
 
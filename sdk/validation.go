@@ -0,0 +1,24 @@
+package sdk
+
+import (
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/validation"
+)
+
+// ValidateResources checks each item in resources against the search contract and returns the
+// violations found, keyed by the index of the item in resources.Items. An empty map is returned
+// if every item is valid.
+func ValidateResources(resources *models.Resources) map[int]validation.Violations {
+	invalid := make(map[int]validation.Violations)
+	if resources == nil {
+		return invalid
+	}
+
+	for i, item := range resources.Items {
+		if violations := validation.Validate(item); len(violations) > 0 {
+			invalid[i] = violations
+		}
+	}
+
+	return invalid
+}
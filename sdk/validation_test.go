@@ -0,0 +1,47 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	c "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateResources(t *testing.T) {
+	t.Parallel()
+
+	c.Convey("Given a list of resources containing a resource that breaks the search contract", t, func() {
+		valid := models.SearchContentUpdatedResource{
+			URI:         "/economy",
+			ContentType: "bulletin",
+			Title:       "Bulletin title",
+			ReleaseDate: "2024-11-21T20:14:00Z",
+			Topics:      []string{"2213"},
+		}
+		invalid := valid
+		invalid.ContentType = "not-a-content-type"
+		resources := &models.Resources{
+			Count: 2,
+			Items: []models.Resource{valid, invalid},
+		}
+
+		c.Convey("When ValidateResources is called", func() {
+			violations := ValidateResources(resources)
+
+			c.Convey("Then only the invalid resource is reported", func() {
+				c.So(violations, c.ShouldHaveLength, 1)
+				c.So(violations[1].Keys(), c.ShouldResemble, []string{"content_type:enum"})
+			})
+		})
+	})
+
+	c.Convey("Given no resources", t, func() {
+		c.Convey("When ValidateResources is called", func() {
+			violations := ValidateResources(nil)
+
+			c.Convey("Then no violations are reported", func() {
+				c.So(violations, c.ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -1,25 +1,31 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/config"
 
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	kafka "github.com/ONSdigital/dp-kafka/v4"
 	dphttp "github.com/ONSdigital/dp-net/v3/http"
+	"github.com/ONSdigital/log.go/v2/log"
 )
 
 // ExternalServiceList holds the initialiser and initialisation state of external services.
 type ExternalServiceList struct {
-	HealthCheck bool
-	Init        Initialiser
+	HealthCheck    bool
+	KafkaProducers bool
+	Init           Initialiser
 }
 
 // NewServiceList creates a new service list with the provided initialiser
 func NewServiceList(initialiser Initialiser) *ExternalServiceList {
 	return &ExternalServiceList{
-		HealthCheck: false,
-		Init:        initialiser,
+		HealthCheck:    false,
+		KafkaProducers: false,
+		Init:           initialiser,
 	}
 }
 
@@ -42,6 +48,26 @@ func (e *ExternalServiceList) GetHealthCheck(cfg *config.Config, buildTime, gitC
 	return hc, nil
 }
 
+// GetKafkaProducers creates a kafka producer for each topic and sets the KafkaProducers flag to true
+func (e *ExternalServiceList) GetKafkaProducers(ctx context.Context, cfg *config.Config, topics ...string) (map[string]kafka.IProducer, error) {
+	producers := make(map[string]kafka.IProducer, len(topics))
+	for _, topic := range topics {
+		producer, err := e.Init.DoGetKafkaProducer(ctx, cfg, topic)
+		if err != nil {
+			// close the producers already created, as they are not returned
+			for created, p := range producers {
+				if closeErr := p.Close(ctx); closeErr != nil {
+					log.Error(ctx, "failed to close kafka producer", closeErr, log.Data{"topic": created})
+				}
+			}
+			return nil, fmt.Errorf("failed to create kafka producer for topic %s: %w", topic, err)
+		}
+		producers[topic] = producer
+	}
+	e.KafkaProducers = true
+	return producers, nil
+}
+
 // DoGetHTTPServer creates an HTTP Server with the provided bind address and router
 func (e *Init) DoGetHTTPServer(bindAddr string, router http.Handler) HTTPServer {
 	s := dphttp.NewServer(bindAddr, router)
@@ -58,3 +84,24 @@ func (e *Init) DoGetHealthCheck(cfg *config.Config, buildTime, gitCommit, versio
 	hc := healthcheck.New(versionInfo, cfg.HealthCheckCriticalTimeout, cfg.HealthCheckInterval)
 	return &hc, nil
 }
+
+// DoGetKafkaProducer creates a kafka producer for the topic
+func (e *Init) DoGetKafkaProducer(ctx context.Context, cfg *config.Config, topic string) (kafka.IProducer, error) {
+	pConfig := &kafka.ProducerConfig{
+		BrokerAddrs:       cfg.Kafka.Addr,
+		Topic:             topic,
+		KafkaVersion:      &cfg.Kafka.Version,
+		MaxMessageBytes:   &cfg.Kafka.MaxBytes,
+		MinBrokersHealthy: &cfg.Kafka.ProducerMinBrokersHealthy,
+		OtelEnabled:       &cfg.OtelEnabled,
+	}
+	if cfg.Kafka.SecProtocol == config.KafkaTLSProtocol {
+		pConfig.SecurityConfig = kafka.GetSecurityConfig(
+			cfg.Kafka.SecCACerts,
+			cfg.Kafka.SecClientCert,
+			cfg.Kafka.SecClientKey,
+			cfg.Kafka.SecSkipVerify,
+		)
+	}
+	return kafka.NewProducer(ctx, pConfig)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	kafka "github.com/ONSdigital/dp-kafka/v4"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/config"
 )
@@ -17,6 +18,7 @@ import (
 type Initialiser interface {
 	DoGetHTTPServer(bindAddr string, router http.Handler) HTTPServer
 	DoGetHealthCheck(cfg *config.Config, buildTime, gitCommit, version string) (HealthChecker, error)
+	DoGetKafkaProducer(ctx context.Context, cfg *config.Config, topic string) (kafka.IProducer, error)
 }
 
 // HTTPServer defines the required methods from the HTTP server
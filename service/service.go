@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/data"
 
@@ -11,7 +12,13 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/api"
+	"github.com/ONSdigital/dis-search-upstream-stub/clock"
 	"github.com/ONSdigital/dis-search-upstream-stub/config"
+	"github.com/ONSdigital/dis-search-upstream-stub/events"
+	"github.com/ONSdigital/dis-search-upstream-stub/healthsim"
+	"github.com/ONSdigital/dis-search-upstream-stub/lifecycle"
+	"github.com/ONSdigital/dis-search-upstream-stub/metrics"
+	"github.com/ONSdigital/dis-search-upstream-stub/registry"
 )
 
 // Service contains all the configs, server and clients to run the API
@@ -22,6 +29,12 @@ type Service struct {
 	API         *api.API
 	ServiceList *ExternalServiceList
 	HealthCheck HealthChecker
+	Publisher   *events.Publisher
+	Emitter     *events.Emitter
+	Scheduler   *lifecycle.Scheduler
+	Clock       *clock.Simulated
+	Health      *healthsim.Simulator
+	Metrics     *metrics.Metrics
 }
 
 // Run the service
@@ -33,31 +46,110 @@ func Run(ctx context.Context, cfg *config.Config, serviceList *ExternalServiceLi
 	// Get HTTP Server and ... // TODO: Add any middleware that your service requires
 	r := mux.NewRouter()
 
+	// Trace each request in a span named after its route. Middleware added to the router runs within the span, and
+	// the data store, publisher and kafka producers start child spans of it.
 	if cfg.OtelEnabled {
 		r.Use(otelmux.Middleware(cfg.OTServiceName))
-
-		// TODO: Any middleware will require 'otelhttp.NewMiddleware(cfg.OTServiceName),' included for Open Telemetry
 	}
 
+	// Record the requests to each route, and what the stub serves, publishes and simulates, for /metrics
+	m := metrics.New()
+	r.Use(m.Middleware)
+
 	s := serviceList.GetHTTPServer(cfg.BindAddr, r)
 
 	// TODO: Add other(s) to serviceList here
 
+	// Set up the schema registry stand-in
+	schemaRegistry, err := registry.New(cfg.Kafka)
+	if err != nil {
+		log.Error(ctx, "could not create schema registry", err)
+		return nil, err
+	}
+
+	// Set up the virtual clock that fixture dates and release lifecycles are relative to
+	start := cfg.ClockStart
+	if start.IsZero() {
+		start = clock.Real{}.Now()
+	}
+	virtualClock := clock.NewSimulated(clock.Real{}, start, cfg.ClockSpeed)
+
+	store := &data.ResourceStore{RejectInvalidFixtures: cfg.RejectInvalidFixtures, Clock: virtualClock, Metrics: m}
+
+	// Set up the kafka producers used to publish events over HTTP and when resources are changed, if enabled
+	var publisher *events.Publisher
+	var emitter *events.Emitter
+	var eventPublisher api.EventPublisher
+	if cfg.EventPublishingEnabled {
+		producers, err := serviceList.GetKafkaProducers(ctx, cfg,
+			cfg.Kafka.ContentUpdatedTopic, cfg.Kafka.SearchContentUpdatedTopic, cfg.Kafka.SearchContentDeletedTopic)
+		if err != nil {
+			log.Error(ctx, "could not create kafka producers", err)
+			return nil, err
+		}
+		for _, producer := range producers {
+			producer.LogErrors(ctx)
+		}
+
+		encoder := &events.Encoder{Kafka: cfg.Kafka, Registry: schemaRegistry}
+		publisher = events.NewPublisher(encoder, producers)
+		publisher.Metrics = m
+		if err := publisher.AddHeaders(); err != nil {
+			log.Error(ctx, "could not add headers to kafka producers", err)
+			closeProducers(ctx, publisher)
+			return nil, err
+		}
+		eventPublisher = publisher
+
+		emitter = events.NewEmitter(publisher, cfg.EventDelay)
+		store.Emitter = emitter
+	}
+
+	// Set up the release scheduler to move releases through their lifecycle, if enabled
+	var scheduler *lifecycle.Scheduler
+	if cfg.ReleaseSchedulerEnabled {
+		store.Lifecycle = &lifecycle.Lifecycle{
+			Clock:              virtualClock,
+			ConfirmationPeriod: cfg.ReleaseConfirmationPeriod,
+		}
+
+		var schedulerEmitter lifecycle.EventEmitter
+		if emitter != nil {
+			schedulerEmitter = emitter
+		}
+		scheduler = lifecycle.NewScheduler(store, schedulerEmitter, cfg.ReleaseSchedulerInterval)
+
+		// changes made through the store are emitted by way of the scheduler, so that it does not emit them again
+		store.Emitter = scheduler
+	}
+
+	// Set up the simulation of the health status, which runs in real time rather than on the virtual clock
+	healthSimulator := healthsim.New(clock.Real{})
+	healthSimulator.Metrics = m
+
 	// Set up the API
-	a := api.Setup(r, cfg, &data.ResourceStore{})
+	a := api.Setup(r, cfg, store, schemaRegistry, virtualClock, healthSimulator, eventPublisher)
 
 	hc, err := serviceList.GetHealthCheck(cfg, buildTime, gitCommit, version)
 
 	if err != nil {
 		log.Fatal(ctx, "could not instantiate healthcheck", err)
+		closeProducers(ctx, publisher)
 		return nil, err
 	}
 
-	if err := registerCheckers(ctx, hc); err != nil {
+	if err := registerCheckers(ctx, cfg, hc, publisher); err != nil {
+		closeProducers(ctx, publisher)
 		return nil, errors.Wrap(err, "unable to register checkers")
 	}
 
-	r.StrictSlash(true).Path("/health").HandlerFunc(hc.Handler)
+	r.StrictSlash(true).Path("/health").HandlerFunc(healthSimulator.Handler(hc.Handler))
+	r.Path("/metrics").Handler(m.Handler()).Methods("GET")
+
+	// Start the release scheduler only once nothing else can fail, so that it is never left running
+	if scheduler != nil {
+		scheduler.Start(ctx)
+	}
 	hc.Start(ctx)
 
 	// Run the http server in a new go-routine
@@ -74,6 +166,12 @@ func Run(ctx context.Context, cfg *config.Config, serviceList *ExternalServiceLi
 		HealthCheck: hc,
 		ServiceList: serviceList,
 		Server:      s,
+		Publisher:   publisher,
+		Emitter:     emitter,
+		Scheduler:   scheduler,
+		Clock:       virtualClock,
+		Health:      healthSimulator,
+		Metrics:     m,
 	}, nil
 }
 
@@ -89,7 +187,7 @@ func (svc *Service) Close(ctx context.Context) error {
 	go func() {
 		defer cancel()
 
-		// stop healthcheck, as it depends on everything else
+		// stop healthcheck, as it depends on everything else, including the kafka producers it checks
 		if svc.ServiceList.HealthCheck {
 			svc.HealthCheck.Stop()
 		}
@@ -100,6 +198,20 @@ func (svc *Service) Close(ctx context.Context) error {
 			hasShutdownError = true
 		}
 
+		// stop the release scheduler before the events it emits can no longer be published
+		if svc.Scheduler != nil {
+			svc.Scheduler.Close()
+		}
+
+		// close the kafka producers once no more events can be published over HTTP or emitted
+		if svc.ServiceList.KafkaProducers {
+			svc.Emitter.Close()
+			if err := svc.Publisher.Close(ctx); err != nil {
+				log.Error(ctx, "failed to close kafka producers", err)
+				hasShutdownError = true
+			}
+		}
+
 		// TODO: Close other dependencies, in the expected order
 	}()
 
@@ -123,9 +235,36 @@ func (svc *Service) Close(ctx context.Context) error {
 	return nil
 }
 
-func registerCheckers(_ context.Context,
-	_ HealthChecker) (err error) {
-	// TODO: add other health checks here, as per dp-upload-service
+// closeProducers closes the kafka producers of the publisher, if there is one, when the service fails to start
+func closeProducers(ctx context.Context, publisher *events.Publisher) {
+	if publisher == nil {
+		return
+	}
+	if err := publisher.Close(ctx); err != nil {
+		log.Error(ctx, "failed to close kafka producers", err)
+	}
+}
+
+// registerCheckers adds the health checks of the service's dependencies to the healthcheck. Each kafka producer's
+// checker is healthy while at least KAFKA_PRODUCER_MIN_BROKERS_HEALTHY brokers are reachable and have its topic.
+func registerCheckers(ctx context.Context, cfg *config.Config, hc HealthChecker, publisher *events.Publisher) (err error) {
+	hasErrors := false
 
+	if publisher != nil {
+		for _, topic := range []string{cfg.Kafka.ContentUpdatedTopic, cfg.Kafka.SearchContentUpdatedTopic, cfg.Kafka.SearchContentDeletedTopic} {
+			producer, ok := publisher.Producers[topic]
+			if !ok {
+				continue
+			}
+			if err := hc.AddCheck(fmt.Sprintf("Kafka producer (%s)", topic), producer.Checker); err != nil {
+				hasErrors = true
+				log.Error(ctx, "error adding check for kafka producer", err, log.Data{"topic": topic})
+			}
+		}
+	}
+
+	if hasErrors {
+		return errors.New("Error(s) registering checkers for healthcheck")
+	}
 	return nil
 }
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	kafka "github.com/ONSdigital/dp-kafka/v4"
+	"github.com/ONSdigital/dp-kafka/v4/kafkatest"
 
 	"github.com/ONSdigital/dis-search-upstream-stub/config"
 	"github.com/ONSdigital/dis-search-upstream-stub/service"
@@ -27,7 +29,8 @@ var (
 )
 
 var (
-	errHealthcheck = errors.New("healthCheck error")
+	errHealthcheck   = errors.New("healthCheck error")
+	errKafkaProducer = errors.New("kafka producer error")
 )
 
 var funcDoGetHealthcheckErr = func(_ *config.Config, buildTime string, gitCommit string, version string) (service.HealthChecker, error) {
@@ -126,37 +129,147 @@ func TestRun(t *testing.T) {
 			})
 		})
 
-		/* ADD CODE OR REMOVE: put this code in, if you have Checkers to register
+		Convey("Given that event publishing is enabled", func() {
+			// setup (run before each `Convey` at this scope / indentation):
+			publishingCfg := *cfg
+			publishingCfg.EventPublishingEnabled = true
+
+			producerMock := &kafkatest.IProducerMock{
+				LogErrorsFunc: func(ctx context.Context) {},
+				AddHeaderFunc: func(key, value string) {},
+			}
+			initMock := &mock.InitialiserMock{
+				DoGetHTTPServerFunc:  funcDoGetHTTPServer,
+				DoGetHealthCheckFunc: funcDoGetHealthcheckOk,
+				DoGetKafkaProducerFunc: func(ctx context.Context, cfg *config.Config, topic string) (kafka.IProducer, error) {
+					return producerMock, nil
+				},
+			}
+			svcErrors := make(chan error, 1)
+			svcList := service.NewServiceList(initMock)
+			serverWg.Add(1)
+			svc, err := service.Run(ctx, &publishingCfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+
+			Convey("Then a producer is created for each topic and the event publishing route added", func() {
+				So(err, ShouldBeNil)
+				So(svcList.KafkaProducers, ShouldBeTrue)
+				So(initMock.DoGetKafkaProducerCalls(), ShouldHaveLength, 3)
+				So(initMock.DoGetKafkaProducerCalls()[0].Topic, ShouldEqual, cfg.Kafka.ContentUpdatedTopic)
+				So(initMock.DoGetKafkaProducerCalls()[1].Topic, ShouldEqual, cfg.Kafka.SearchContentUpdatedTopic)
+				So(initMock.DoGetKafkaProducerCalls()[2].Topic, ShouldEqual, cfg.Kafka.SearchContentDeletedTopic)
+				So(producerMock.LogErrorsCalls(), ShouldHaveLength, 3)
+				So(producerMock.AddHeaderCalls(), ShouldHaveLength, 9)
+				So(svc.Publisher, ShouldNotBeNil)
+				So(svc.API.Publisher, ShouldNotBeNil)
+				serverWg.Wait() // Wait for HTTP server go-routine to finish
+			})
+
+			Convey("Then the checker of each kafka producer is registered with the healthcheck", func() {
+				So(hcMock.AddCheckCalls(), ShouldHaveLength, 3)
+				So(hcMock.AddCheckCalls()[0].Name, ShouldEqual, "Kafka producer (content-updated)")
+				So(hcMock.AddCheckCalls()[1].Name, ShouldEqual, "Kafka producer (search-content-updated)")
+				So(hcMock.AddCheckCalls()[2].Name, ShouldEqual, "Kafka producer (search-content-deleted)")
+				serverWg.Wait() // Wait for HTTP server go-routine to finish
+			})
+		})
+
+		Convey("Given that the release scheduler is enabled", func() {
+			// setup (run before each `Convey` at this scope / indentation):
+			schedulerCfg := *cfg
+			schedulerCfg.ReleaseSchedulerEnabled = true
+
+			initMock := &mock.InitialiserMock{
+				DoGetHTTPServerFunc:  funcDoGetHTTPServer,
+				DoGetHealthCheckFunc: funcDoGetHealthcheckOk,
+			}
+			svcErrors := make(chan error, 1)
+			svcList := service.NewServiceList(initMock)
+			serverWg.Add(1)
+			svc, err := service.Run(ctx, &schedulerCfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+
+			Convey("Then the scheduler is started without kafka producers", func() {
+				So(err, ShouldBeNil)
+				So(svc.Scheduler, ShouldNotBeNil)
+				So(svc.Scheduler.Emitter, ShouldBeNil)
+				So(svcList.KafkaProducers, ShouldBeFalse)
+				serverWg.Wait() // Wait for HTTP server go-routine to finish
+				svc.Scheduler.Close()
+			})
+		})
+
+		Convey("Given that event publishing is enabled but a kafka producer cannot be created", func() {
+			// setup (run before each `Convey` at this scope / indentation):
+			publishingCfg := *cfg
+			publishingCfg.EventPublishingEnabled = true
+
+			producerMock := &kafkatest.IProducerMock{
+				CloseFunc: func(ctx context.Context) error { return nil },
+			}
+			initMock := &mock.InitialiserMock{
+				DoGetHTTPServerFunc:  funcDoGetHTTPServerNil,
+				DoGetHealthCheckFunc: funcDoGetHealthcheckOk,
+				DoGetKafkaProducerFunc: func(ctx context.Context, cfg *config.Config, topic string) (kafka.IProducer, error) {
+					if topic == cfg.Kafka.SearchContentDeletedTopic {
+						return nil, errKafkaProducer
+					}
+					return producerMock, nil
+				},
+			}
+			svcErrors := make(chan error, 1)
+			svcList := service.NewServiceList(initMock)
+			_, err := service.Run(ctx, &publishingCfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+
+			Convey("Then service Run fails with the error, the producers already created are closed and the flag is not set", func() {
+				So(errors.Is(err, errKafkaProducer), ShouldBeTrue)
+				So(producerMock.CloseCalls(), ShouldHaveLength, 2)
+				So(svcList.KafkaProducers, ShouldBeFalse)
+			})
+		})
+
 		Convey("Given that Checkers cannot be registered", func() {
 			// setup (run before each `Convey` at this scope / indentation):
+			publishingCfg := *cfg
+			publishingCfg.EventPublishingEnabled = true
+
 			errAddheckFail := errors.New("Error(s) registering checkers for healthcheck")
 			hcMockAddFail := &mock.HealthCheckerMock{
 				AddCheckFunc: func(name string, checker healthcheck.Checker) error { return errAddheckFail },
 				StartFunc:    func(ctx context.Context) {},
 			}
 
+			producerMock := &kafkatest.IProducerMock{
+				LogErrorsFunc: func(ctx context.Context) {},
+				AddHeaderFunc: func(key, value string) {},
+				CloseFunc:     func(ctx context.Context) error { return nil },
+			}
 			initMock := &mock.InitialiserMock{
 				DoGetHTTPServerFunc: funcDoGetHTTPServerNil,
 				DoGetHealthCheckFunc: func(cfg *config.Config, buildTime string, gitCommit string, version string) (service.HealthChecker, error) {
 					return hcMockAddFail, nil
 				},
-				// ADD CODE: add the checkers that you want to register here
+				DoGetKafkaProducerFunc: func(ctx context.Context, cfg *config.Config, topic string) (kafka.IProducer, error) {
+					return producerMock, nil
+				},
 			}
 			svcErrors := make(chan error, 1)
 			svcList := service.NewServiceList(initMock)
-			_, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+			_, err := service.Run(ctx, &publishingCfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
 
 			Convey("Then service Run fails, but all checks try to register", func() {
 				So(err, ShouldNotBeNil)
 				So(err.Error(), ShouldResemble, fmt.Sprintf("unable to register checkers: %s", errAddheckFail.Error()))
 				So(svcList.HealthCheck, ShouldBeTrue)
-				// ADD CODE: add code to confirm checkers exist
-				So(len(hcMockAddFail.AddCheckCalls()), ShouldEqual, 0) // ADD CODE: change the '0' to the number of checkers you have registered
+				So(svcList.KafkaProducers, ShouldBeTrue)
+				So(len(hcMockAddFail.AddCheckCalls()), ShouldEqual, 3)
+			})
+
+			Convey("Then the kafka producers are closed", func() {
+				So(producerMock.CloseCalls(), ShouldHaveLength, 3)
 			})
 			Reset(func() {
 				// This reset is run after each `Convey` at the same scope (indentation)
 			})
-		})*/
+		})
 
 		Convey("Given that all dependencies are successfully initialised but the http server fails", func() {
 			// setup (run before each `Convey` at this scope / indentation):
@@ -227,6 +340,53 @@ func TestClose(t *testing.T) {
 			So(len(serverMock.ShutdownCalls()), ShouldEqual, 1)
 		})
 
+		Convey("Closing a service with event publishing enabled closes the kafka producers after the healthcheck and http server", func() {
+			publishingCfg := *cfg
+			publishingCfg.EventPublishingEnabled = true
+
+			serverShutdown := false
+			orderedServerMock := &mock.HTTPServerMock{
+				ListenAndServeFunc: func() error { return nil },
+				ShutdownFunc: func(ctx context.Context) error {
+					serverShutdown = true
+					return nil
+				},
+			}
+			producerMock := &kafkatest.IProducerMock{
+				LogErrorsFunc: func(ctx context.Context) {},
+				AddHeaderFunc: func(key, value string) {},
+				CloseFunc: func(ctx context.Context) error {
+					if !hcStopped {
+						return errors.New("kafka producer closed before healthcheck")
+					}
+					if !serverShutdown {
+						return errors.New("kafka producer closed before http server")
+					}
+					return nil
+				},
+			}
+
+			initMock := &mock.InitialiserMock{
+				DoGetHTTPServerFunc: func(bindAddr string, router http.Handler) service.HTTPServer { return orderedServerMock },
+				DoGetHealthCheckFunc: func(cfg *config.Config, buildTime string, gitCommit string, version string) (service.HealthChecker, error) {
+					return hcMock, nil
+				},
+				DoGetKafkaProducerFunc: func(ctx context.Context, cfg *config.Config, topic string) (kafka.IProducer, error) {
+					return producerMock, nil
+				},
+			}
+
+			svcErrors := make(chan error, 1)
+			svcList := service.NewServiceList(initMock)
+			svc, err := service.Run(ctx, &publishingCfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+			So(err, ShouldBeNil)
+
+			err = svc.Close(context.Background())
+			So(err, ShouldBeNil)
+			So(len(orderedServerMock.ShutdownCalls()), ShouldEqual, 1)
+			So(len(producerMock.CloseCalls()), ShouldEqual, 3)
+		})
+
 		Convey("If services fail to stop, the Close operation tries to close all dependencies and returns an error", func() {
 			failingserverMock := &mock.HTTPServerMock{
 				ListenAndServeFunc: func() error { return nil },
@@ -0,0 +1,160 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+)
+
+// A list of the contract rules a resource can violate
+const (
+	RuleRequired    = "required"
+	RuleEnum        = "enum"
+	RuleFormat      = "format"
+	RuleReleaseOnly = "release_only"
+)
+
+// contentTypes is the content_type enum defined by StandardPayload in docs/contract/resource_metadata.yml
+var contentTypes = map[string]bool{
+	"api_dataset_landing_page":    true,
+	"article":                     true,
+	"article_download":            true,
+	"bulletin":                    true,
+	"compendium_chapter":          true,
+	"compendium_data":             true,
+	"compendium_landing_page":     true,
+	"dataset":                     true,
+	"dataset_landing_page":        true,
+	"home_page":                   true,
+	"home_page_census":            true,
+	"product_page":                true,
+	"reference_tables":            true,
+	"release":                     true,
+	"static_adhoc":                true,
+	"static_article":              true,
+	"static_foi":                  true,
+	"static_landing_page":         true,
+	"static_methodology":          true,
+	"static_methodology_download": true,
+	"static_page":                 true,
+	"static_qmi":                  true,
+	"statistical_article":         true,
+	"taxonomy_landing_page":       true,
+	"timeseries":                  true,
+	"visualisation":               true,
+}
+
+// Violation describes a single way in which a resource breaks the search contract
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Key returns the field and rule of the violation in the form "field:rule"
+func (v Violation) Key() string {
+	return v.Field + ":" + v.Rule
+}
+
+// Violations is a list of contract violations found for a resource
+type Violations []Violation
+
+// Error allows Violations to satisfy the error interface
+func (v Violations) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, violation := range v {
+		messages = append(messages, violation.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Keys returns the distinct keys of the violations in the list, in the order they were found
+func (v Violations) Keys() []string {
+	keys := make([]string, 0, len(v))
+	seen := make(map[string]bool, len(v))
+	for _, violation := range v {
+		if key := violation.Key(); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Validate checks a resource against the search contract and returns any violations found.
+// Only search-content-updated resources are covered by the contract, so any other resource
+// type is always considered valid.
+func Validate(resource models.Resource) Violations {
+	switch r := resource.(type) {
+	case models.SearchContentUpdatedResource:
+		return validateSearchContentUpdated(&r)
+	case *models.SearchContentUpdatedResource:
+		return validateSearchContentUpdated(r)
+	default:
+		return nil
+	}
+}
+
+func validateSearchContentUpdated(r *models.SearchContentUpdatedResource) Violations {
+	var violations Violations
+
+	add := func(field, rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{
+			Field:   field,
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// StandardPayload
+	if r.URI == "" {
+		add("uri", RuleRequired, "uri is required")
+	} else if _, err := url.ParseRequestURI(r.URI); err != nil {
+		add("uri", RuleFormat, "uri %q is not a valid uri", r.URI)
+	}
+
+	if r.Title == "" {
+		add("title", RuleRequired, "title is required")
+	}
+
+	if r.ContentType == "" {
+		add("content_type", RuleRequired, "content_type is required")
+	} else if !contentTypes[r.ContentType] {
+		add("content_type", RuleEnum, "content_type %q is not a recognised content type", r.ContentType)
+	}
+
+	if r.ReleaseDate != "" && !isDateTime(r.ReleaseDate) {
+		add("release_date", RuleFormat, "release_date %q is not a valid date-time", r.ReleaseDate)
+	}
+
+	// ReleasePayload fields are only used with the release content type, which can only be
+	// checked when a content type has been given
+	if r.ContentType != "" && r.ContentType != models.ReleaseContentType {
+		if r.Cancelled {
+			add("cancelled", RuleReleaseOnly, "cancelled is only used with release content")
+		}
+		if r.Finalised {
+			add("finalised", RuleReleaseOnly, "finalised is only used with release content")
+		}
+		if r.Published {
+			add("published", RuleReleaseOnly, "published is only used with release content")
+		}
+		if len(r.DateChanges) > 0 {
+			add("date_changes", RuleReleaseOnly, "date_changes is only used with release content")
+		}
+		if r.ProvisionalDate != "" {
+			add("provisional_date", RuleReleaseOnly, "provisional_date is only used with release content")
+		}
+	}
+
+	return violations
+}
+
+// isDateTime checks the value is an RFC 3339 date-time, as required by the OpenAPI date-time format
+func isDateTime(value string) bool {
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
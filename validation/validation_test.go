@@ -0,0 +1,133 @@
+package validation_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/ONSdigital/dis-search-upstream-stub/models"
+	"github.com/ONSdigital/dis-search-upstream-stub/validation"
+)
+
+func validStandardResource() models.SearchContentUpdatedResource {
+	return models.SearchContentUpdatedResource{
+		URI:         "/economy/inflationandpriceindices",
+		ContentType: "bulletin",
+		Title:       "Consumer price inflation",
+		ReleaseDate: "2026-02-12T07:00:00.000Z",
+		Topics:      []string{"4972", "1245"},
+	}
+}
+
+func validReleaseResource() models.SearchContentUpdatedResource {
+	resource := validStandardResource()
+	resource.ContentType = models.ReleaseContentType
+	resource.Cancelled = true
+	resource.Finalised = true
+	resource.ProvisionalDate = "October-November 2024"
+	resource.DateChanges = []models.ReleaseDateDetails{
+		{ChangeNotice: "a change notice", PreviousDate: "2024-10-01T07:00:00Z"},
+	}
+	return resource
+}
+
+func TestValidateValidResources(t *testing.T) {
+	Convey("Given resources that satisfy the search contract", t, func() {
+		standard := validStandardResource()
+		release := validReleaseResource()
+
+		Convey("When they are validated", func() {
+			Convey("Then no violations are returned", func() {
+				So(validation.Validate(standard), ShouldBeEmpty)
+				So(validation.Validate(&standard), ShouldBeEmpty)
+				So(validation.Validate(release), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given resources that are not covered by the search contract", t, func() {
+		contentUpdated := models.ContentUpdatedResource{}
+		searchContentDeleted := models.SearchContentDeletedResource{}
+
+		Convey("When they are validated", func() {
+			Convey("Then no violations are returned", func() {
+				So(validation.Validate(contentUpdated), ShouldBeEmpty)
+				So(validation.Validate(searchContentDeleted), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestValidateInvalidResources(t *testing.T) {
+	Convey("Given a resource with no uri, title or content type", t, func() {
+		resource := validStandardResource()
+		resource.URI = ""
+		resource.Title = ""
+		resource.ContentType = ""
+
+		Convey("When it is validated", func() {
+			violations := validation.Validate(resource)
+
+			Convey("Then each missing field is reported as required", func() {
+				So(violations.Keys(), ShouldResemble, []string{"uri:required", "title:required", "content_type:required"})
+			})
+		})
+	})
+
+	Convey("Given a resource with an unknown content type", t, func() {
+		resource := validStandardResource()
+		resource.ContentType = "bananas"
+
+		Convey("When it is validated", func() {
+			violations := validation.Validate(resource)
+
+			Convey("Then the content type is reported as not in the enum", func() {
+				So(violations.Keys(), ShouldResemble, []string{"content_type:enum"})
+				So(violations.Error(), ShouldEqual, `content_type "bananas" is not a recognised content type`)
+			})
+		})
+	})
+
+	Convey("Given resources with release dates that are not valid date-times", t, func() {
+		invalid := validStandardResource()
+		invalid.ReleaseDate = "an invalid date"
+		zero := validStandardResource()
+		zero.ReleaseDate = "0000-00-00T00:00:00Z"
+
+		Convey("When they are validated", func() {
+			Convey("Then the release date is reported as the wrong format", func() {
+				So(validation.Validate(invalid).Keys(), ShouldResemble, []string{"release_date:format"})
+				So(validation.Validate(zero).Keys(), ShouldResemble, []string{"release_date:format"})
+			})
+		})
+	})
+
+	Convey("Given a resource with topics that are not numeric", t, func() {
+		resource := validStandardResource()
+		resource.Topics = []string{"1245", "economy"}
+
+		Convey("When it is validated", func() {
+			Convey("Then no violations are reported, as the contract does not constrain the topics", func() {
+				So(validation.Validate(resource), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given a resource that is not a release but has release fields", t, func() {
+		resource := validReleaseResource()
+		resource.ContentType = "bulletin"
+
+		Convey("When it is validated", func() {
+			violations := validation.Validate(resource)
+
+			Convey("Then each release field that is set is reported", func() {
+				So(violations.Keys(), ShouldResemble, []string{
+					"cancelled:release_only",
+					"finalised:release_only",
+					"date_changes:release_only",
+					"provisional_date:release_only",
+				})
+			})
+		})
+	})
+}